@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/service"
+	"github.com/JinXVIII/BE-Medical-Record/internal/storage"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
+)
+
+// runCommand runs a maintenance subcommand instead of the HTTP server.
+//
+//	go run . create-admin -name "Admin" -email admin@example.com
+//	go run . migrate up|down [-drop-data] [steps]|status
+//	go run . seed minimal|demo|load-test [-seed 1 -doctors 2000 ...]
+//
+// The password is read from ADMIN_PASSWORD or, when that is empty, from the
+// first line of stdin so it does not end up in the shell history.
+func runCommand(args []string, db *sql.DB, users service.UserService) error {
+	switch args[0] {
+	case "create-admin":
+		return createAdmin(args[1:], users)
+	case "seed":
+		return seed(args[1:], db)
+	default:
+		return fmt.Errorf("perintah tidak dikenal %q (tersedia: create-admin, migrate, seed)", args[0])
+	}
+}
+
+func createAdmin(args []string, users service.UserService) error {
+	fs := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	name := fs.String("name", "Administrator", "display name")
+	email := fs.String("email", "", "login email (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return errors.New("password tidak terbaca dari stdin")
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	req := struct {
+		Name     string `validate:"required"`
+		Email    string `validate:"required,email"`
+		Password string `validate:"required,min=8"`
+	}{*name, *email, password}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		return fmt.Errorf("input tidak valid: %v", validationErrors)
+	}
+
+	admin, err := users.CreateAdmin(context.Background(), req.Name, req.Email, req.Password)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Admin %s dibuat (id %d)", admin.Email, admin.ID)
+	return nil
+}
+
+// seed inserts one of the named datasets. The flags only size load-test.
+func seed(args []string, db *sql.DB) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("pemakaian: seed %s [flags]", strings.Join(storage.SeedDatasets, "|"))
+	}
+	dataset := args[0]
+
+	opts := storage.DefaultSeedOptions()
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	fs.Uint64Var(&opts.Seed, "seed", opts.Seed, "random seed, the same seed generates the same data")
+	fs.IntVar(&opts.Doctors, "doctors", opts.Doctors, "number of doctors")
+	fs.IntVar(&opts.Patients, "patients", opts.Patients, "number of patients")
+	fs.IntVar(&opts.Appointments, "appointments", opts.Appointments, "number of appointments")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if err := storage.Seed(context.Background(), db, dataset, opts); err != nil {
+		return err
+	}
+
+	log.Printf("Dataset %s selesai di-seed", dataset)
+	return nil
+}
+
+// runMigrate applies, rolls back or lists the schema migrations. It is
+// dispatched from main before the services are built, since those expect the
+// schema to exist.
+func runMigrate(db *sql.DB, args []string) error {
+	migrator, err := storage.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if len(args) == 0 {
+		return errors.New("pemakaian: migrate up|down [-drop-data] [steps]|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("%d migration diterapkan", len(applied))
+		return nil
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		dropData := fs.Bool("drop-data", false, "also roll back migrations that drop tables and their data")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		steps := 1
+		if fs.NArg() > 0 {
+			if steps, err = strconv.Atoi(fs.Arg(0)); err != nil || steps <= 0 {
+				return fmt.Errorf("steps harus berupa angka positif, bukan %q", fs.Arg(0))
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps, *dropData)
+		if errors.Is(err, storage.ErrDropsData) {
+			return fmt.Errorf("%w; jalankan lagi dengan -drop-data jika memang disengaja", err)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("%d migration dibatalkan", len(rolledBack))
+		return nil
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("perintah migrate tidak dikenal %q (tersedia: up, down, status)", args[0])
+	}
+}
@@ -0,0 +1,112 @@
+// Package audit keeps the trail of who read or changed patient data.
+package audit
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/auth"
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Auditor records audit events. Services only describe what happened; the
+// actor, IP, request id and time are taken from the context.
+type Auditor interface {
+	Record(ctx context.Context, e domain.AuditEvent)
+	// RecordAll records events in one go. An empty list records nothing.
+	RecordAll(ctx context.Context, events []domain.AuditEvent)
+}
+
+type repoAuditor struct {
+	repo repository.AuditRepository
+}
+
+func New(repo repository.AuditRepository) Auditor {
+	return &repoAuditor{repo: repo}
+}
+
+// Record never fails the caller: a missing audit row is logged loudly, but
+// the user still gets the data they were allowed to see.
+func (a *repoAuditor) Record(ctx context.Context, e domain.AuditEvent) {
+	complete(ctx, &e)
+	// Keep recording when the client hung up halfway through the request
+	if err := a.repo.Create(context.WithoutCancel(ctx), &e); err != nil {
+		log.Printf("ERROR: failed to record audit event %s %s/%s: %v", e.Action, e.ResourceType, e.ResourceID, err)
+	}
+}
+
+func (a *repoAuditor) RecordAll(ctx context.Context, events []domain.AuditEvent) {
+	if len(events) == 0 {
+		return
+	}
+	for i := range events {
+		complete(ctx, &events[i])
+	}
+	if err := a.repo.CreateBatch(context.WithoutCancel(ctx), events); err != nil {
+		log.Printf("ERROR: failed to record %d audit events %s %s: %v", len(events), events[0].Action, events[0].ResourceType, err)
+	}
+}
+
+// Nop discards every event.
+type Nop struct{}
+
+func (Nop) Record(context.Context, domain.AuditEvent) {}
+
+func (Nop) RecordAll(context.Context, []domain.AuditEvent) {}
+
+// complete fills the fields of e that come from the request.
+func complete(ctx context.Context, e *domain.AuditEvent) {
+	if e.ActorUserID == nil {
+		if p, ok := auth.FromContext(ctx); ok {
+			id := int64(p.UserID)
+			e.ActorUserID = &id
+			e.ActorRole = p.Role
+		}
+	}
+	if e.Outcome == "" {
+		e.Outcome = domain.AuditOutcomeSuccess
+	}
+	if info, ok := requestInfoFromContext(ctx); ok {
+		e.IP = info.ip
+		e.RequestID = info.requestID
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+}
+
+// ID formats a numeric resource id.
+func ID[T ~int | ~int64](id T) string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+type requestInfo struct {
+	ip        string
+	requestID string
+}
+
+type requestInfoKey struct{}
+
+// Middleware remembers the client IP and request id for Record. It must run
+// after middleware.RequestID (and middleware.RealIP when used).
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		info := requestInfo{ip: ip, requestID: middleware.GetReqID(r.Context())}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)))
+	})
+}
+
+func requestInfoFromContext(ctx context.Context) (requestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(requestInfo)
+	return info, ok
+}
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// verificationKey is a public key (or the HMAC secret) tokens may be signed with.
+type verificationKey struct {
+	id     string
+	method jwt.SigningMethod
+	key    any
+}
+
+// KeySet signs access tokens with one key and verifies them against every
+// key that is still active, so a signing key can be rotated without logging
+// everybody out.
+type KeySet struct {
+	signingID     string
+	signingMethod jwt.SigningMethod
+	signingKey    any
+
+	verify map[string]verificationKey
+	hmac   *verificationKey // HS256 fallback, tokens without kid
+}
+
+// KeyConfig describes where the keys come from.
+//
+// With PrivateKeyFile set tokens are signed with RS256 or EdDSA depending on
+// the key type. VerifyKeyFiles maps kid to the public key of a previous
+// signing key that is still accepted. When HMACSecret is set HS256 tokens are
+// accepted too, and used for signing if there is no private key.
+type KeyConfig struct {
+	PrivateKeyFile string
+	KeyID          string
+	VerifyKeyFiles map[string]string
+	HMACSecret     string
+}
+
+// KeyConfigFromEnv reads JWT_PRIVATE_KEY_FILE, JWT_KEY_ID, JWT_VERIFY_KEYS
+// (kid=path pairs separated by commas) and JWT_SECRET.
+func KeyConfigFromEnv() (KeyConfig, error) {
+	cfg := KeyConfig{
+		PrivateKeyFile: os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		KeyID:          os.Getenv("JWT_KEY_ID"),
+		VerifyKeyFiles: map[string]string{},
+		HMACSecret:     os.Getenv("JWT_SECRET"),
+	}
+
+	for _, pair := range strings.Split(os.Getenv("JWT_VERIFY_KEYS"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(pair, "=")
+		if !ok || kid == "" || path == "" {
+			return KeyConfig{}, fmt.Errorf("JWT_VERIFY_KEYS: %q bukan pasangan kid=path", pair)
+		}
+		cfg.VerifyKeyFiles[kid] = path
+	}
+
+	return cfg, nil
+}
+
+// LoadKeySet reads the PEM files of cfg.
+func LoadKeySet(cfg KeyConfig) (*KeySet, error) {
+	ks := &KeySet{verify: map[string]verificationKey{}}
+
+	if cfg.HMACSecret != "" {
+		ks.hmac = &verificationKey{method: jwt.SigningMethodHS256, key: []byte(cfg.HMACSecret)}
+	}
+
+	if cfg.PrivateKeyFile != "" {
+		data, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read private key: %w", err)
+		}
+		private, method, err := parsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.PrivateKeyFile, err)
+		}
+		public := private.(crypto.Signer).Public()
+
+		kid := cfg.KeyID
+		if kid == "" {
+			if kid, err = thumbprint(public); err != nil {
+				return nil, err
+			}
+		}
+
+		ks.signingID = kid
+		ks.signingMethod = method
+		ks.signingKey = private
+		ks.verify[kid] = verificationKey{id: kid, method: method, key: public}
+	} else if ks.hmac != nil {
+		ks.signingMethod = jwt.SigningMethodHS256
+		ks.signingKey = ks.hmac.key
+	} else {
+		return nil, errors.New("JWT_PRIVATE_KEY_FILE atau JWT_SECRET harus diisi")
+	}
+
+	for kid, path := range cfg.VerifyKeyFiles {
+		if _, exists := ks.verify[kid]; exists {
+			return nil, fmt.Errorf("kid %q dipakai lebih dari sekali", kid)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read verify key %s: %w", kid, err)
+		}
+		public, method, err := parsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ks.verify[kid] = verificationKey{id: kid, method: method, key: public}
+	}
+
+	return ks, nil
+}
+
+// Sign signs claims with the current signing key and sets the kid header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signingMethod, claims)
+	if ks.signingID != "" {
+		token.Header["kid"] = ks.signingID
+	}
+	return token.SignedString(ks.signingKey)
+}
+
+// Keyfunc picks the verification key by kid. The algorithm in the header has
+// to match the key, so a public key can never be used as an HMAC secret.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var key *verificationKey
+	if kid == "" {
+		key = ks.hmac
+	} else if k, ok := ks.verify[kid]; ok {
+		key = &k
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("key %q does not sign with %s", kid, token.Method.Alg())
+	}
+	return key.key, nil
+}
+
+// ValidMethods lists the algorithms the key set accepts.
+func (ks *KeySet) ValidMethods() []string {
+	seen := map[string]bool{}
+	var methods []string
+	add := func(m jwt.SigningMethod) {
+		if !seen[m.Alg()] {
+			seen[m.Alg()] = true
+			methods = append(methods, m.Alg())
+		}
+	}
+	if ks.hmac != nil {
+		add(ks.hmac.method)
+	}
+	for _, k := range ks.verify {
+		add(k.method)
+	}
+	return methods
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public verification keys. The HMAC secret is never
+// published, so services verifying HS256 tokens still need the secret.
+func (ks *KeySet) JWKS() JWKS {
+	doc := JWKS{Keys: []JWK{}}
+	for _, k := range ks.verify {
+		jwk := JWK{KeyID: k.id, Use: "sig", Algorithm: k.method.Alg()}
+		switch pub := k.key.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		doc.Keys = append(doc.Keys, jwk)
+	}
+	sort.Slice(doc.Keys, func(i, j int) bool { return doc.Keys[i].KeyID < doc.Keys[j].KeyID })
+	return doc
+}
+
+func parsePrivateKey(data []byte) (any, jwt.SigningMethod, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return key, jwt.SigningMethodRS256, nil
+	}
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		return key, jwt.SigningMethodEdDSA, nil
+	}
+	return nil, nil, errors.New("private key harus berupa RSA atau Ed25519 dalam format PEM")
+}
+
+func parsePublicKey(data []byte) (any, jwt.SigningMethod, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, jwt.SigningMethodRS256, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return key, jwt.SigningMethodEdDSA, nil
+	}
+	return nil, nil, errors.New("public key harus berupa RSA atau Ed25519 dalam format PEM")
+}
+
+// thumbprint derives a stable kid from the public key.
+func thumbprint(public crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
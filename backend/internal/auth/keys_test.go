@@ -0,0 +1,292 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "hmac-secret-that-must-stay-private"
+
+func TestSignAndVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		newKey func(t *testing.T) crypto.Signer
+		alg    string
+	}{
+		{"RS256", newRSAKey, "RS256"},
+		{"EdDSA", newEd25519Key, "EdDSA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			private, _ := writeKeyPair(t, tt.newKey(t))
+			ks, err := LoadKeySet(KeyConfig{PrivateKeyFile: private, KeyID: "current"})
+			if err != nil {
+				t.Fatalf("LoadKeySet: %v", err)
+			}
+
+			signed, err := ks.Sign(jwt.RegisteredClaims{Subject: "42"})
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			token := parse(t, ks, signed)
+			if token.Header["kid"] != "current" || token.Method.Alg() != tt.alg {
+				t.Errorf("header = %v, want kid current and alg %s", token.Header, tt.alg)
+			}
+			if sub, _ := token.Claims.GetSubject(); sub != "42" {
+				t.Errorf("subject = %q, want 42", sub)
+			}
+		})
+	}
+}
+
+func TestKeyIDFromThumbprint(t *testing.T) {
+	private, _ := writeKeyPair(t, newEd25519Key(t))
+
+	first, err := LoadKeySet(KeyConfig{PrivateKeyFile: private})
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+	second, err := LoadKeySet(KeyConfig{PrivateKeyFile: private})
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+	if first.signingID == "" || first.signingID != second.signingID {
+		t.Errorf("kid = %q and %q, want the same non-empty thumbprint", first.signingID, second.signingID)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	oldPrivate, oldPublic := writeKeyPair(t, newEd25519Key(t))
+	newPrivate, _ := writeKeyPair(t, newRSAKey(t))
+
+	before, err := LoadKeySet(KeyConfig{PrivateKeyFile: oldPrivate, KeyID: "2026-01"})
+	if err != nil {
+		t.Fatalf("LoadKeySet before rotation: %v", err)
+	}
+	issued, err := before.Sign(jwt.RegisteredClaims{Subject: "42"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	// The new key signs, the old one is only kept for verifying
+	after, err := LoadKeySet(KeyConfig{
+		PrivateKeyFile: newPrivate,
+		KeyID:          "2026-02",
+		VerifyKeyFiles: map[string]string{"2026-01": oldPublic},
+	})
+	if err != nil {
+		t.Fatalf("LoadKeySet after rotation: %v", err)
+	}
+	parse(t, after, issued)
+
+	fresh, err := after.Sign(jwt.RegisteredClaims{Subject: "42"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if token := parse(t, after, fresh); token.Header["kid"] != "2026-02" {
+		t.Errorf("new token kid = %v, want 2026-02", token.Header["kid"])
+	}
+
+	// Once the old key is retired its tokens stop working
+	retired, err := LoadKeySet(KeyConfig{PrivateKeyFile: newPrivate, KeyID: "2026-02"})
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+	if _, err := jwt.Parse(issued, retired.Keyfunc, jwt.WithValidMethods(retired.ValidMethods())); err == nil {
+		t.Error("token of a retired key verified")
+	}
+
+	if _, err := LoadKeySet(KeyConfig{
+		PrivateKeyFile: newPrivate,
+		KeyID:          "2026-01",
+		VerifyKeyFiles: map[string]string{"2026-01": oldPublic},
+	}); err == nil {
+		t.Error("two keys with the same kid loaded")
+	}
+}
+
+func TestKeyfuncRejects(t *testing.T) {
+	private, public := writeKeyPair(t, newRSAKey(t))
+	ks, err := LoadKeySet(KeyConfig{PrivateKeyFile: private, KeyID: "rsa"})
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+	publicPEM, err := os.ReadFile(public)
+	if err != nil {
+		t.Fatalf("read public key: %v", err)
+	}
+
+	forge := func(method jwt.SigningMethod, kid string, key any) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, jwt.RegisteredClaims{Subject: "42"})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("sign %s: %v", method.Alg(), err)
+		}
+		return signed
+	}
+	edKey := newEd25519Key(t)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"unknown kid", forge(jwt.SigningMethodRS256, "unknown", mustRSA(t, private))},
+		// The public key is no secret, it must never be accepted as an HMAC key
+		{"HS256 with the RSA public key", forge(jwt.SigningMethodHS256, "rsa", publicPEM)},
+		{"EdDSA under the RSA kid", forge(jwt.SigningMethodEdDSA, "rsa", edKey)},
+		{"no kid without an HMAC secret", forge(jwt.SigningMethodHS256, "", []byte(testSecret))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No WithValidMethods here, Keyfunc alone has to refuse
+			if _, err := jwt.Parse(tt.token, ks.Keyfunc); err == nil {
+				t.Error("token accepted")
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	edKey := newEd25519Key(t)
+	private, _ := writeKeyPair(t, rsaKey)
+	_, edPublic := writeKeyPair(t, edKey)
+
+	ks, err := LoadKeySet(KeyConfig{
+		PrivateKeyFile: private,
+		KeyID:          "rsa-current",
+		VerifyKeyFiles: map[string]string{"ed-previous": edPublic},
+		HMACSecret:     testSecret,
+	})
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+
+	doc := ks.JWKS()
+	if len(doc.Keys) != 2 {
+		t.Fatalf("%d keys published, want the two public keys: %+v", len(doc.Keys), doc.Keys)
+	}
+	ed, rsaJWK := doc.Keys[0], doc.Keys[1]
+
+	if ed.KeyID != "ed-previous" || ed.KeyType != "OKP" || ed.Curve != "Ed25519" || ed.Algorithm != "EdDSA" {
+		t.Errorf("Ed25519 key = %+v", ed)
+	}
+	if x, _ := base64.RawURLEncoding.DecodeString(ed.X); !edKey.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		t.Error("Ed25519 x does not match the public key")
+	}
+
+	if rsaJWK.KeyID != "rsa-current" || rsaJWK.KeyType != "RSA" || rsaJWK.Algorithm != "RS256" || rsaJWK.Use != "sig" {
+		t.Errorf("RSA key = %+v", rsaJWK)
+	}
+	n, _ := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	e, _ := base64.RawURLEncoding.DecodeString(rsaJWK.E)
+	pub := rsaKey.Public().(*rsa.PublicKey)
+	if new(big.Int).SetBytes(n).Cmp(pub.N) != 0 || int(new(big.Int).SetBytes(e).Int64()) != pub.E {
+		t.Error("RSA n and e do not match the public key")
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, leak := range []string{testSecret, base64.RawURLEncoding.EncodeToString([]byte(testSecret)), `"oct"`, `"d"`} {
+		if strings.Contains(string(data), leak) {
+			t.Errorf("JWKS contains %s: %s", leak, data)
+		}
+	}
+
+	hmacOnly, err := LoadKeySet(KeyConfig{HMACSecret: testSecret})
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+	if keys := hmacOnly.JWKS().Keys; keys == nil || len(keys) != 0 {
+		t.Errorf("HMAC-only JWKS = %#v, want an empty list", keys)
+	}
+}
+
+func parse(t *testing.T, ks *KeySet, signed string) *jwt.Token {
+	t.Helper()
+
+	token, err := jwt.Parse(signed, ks.Keyfunc, jwt.WithValidMethods(ks.ValidMethods()))
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	return token
+}
+
+func newRSAKey(t *testing.T) crypto.Signer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	return key
+}
+
+func newEd25519Key(t *testing.T) crypto.Signer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate Ed25519 key: %v", err)
+	}
+	return key
+}
+
+func mustRSA(t *testing.T, privateFile string) *rsa.PrivateKey {
+	t.Helper()
+
+	data, err := os.ReadFile(privateFile)
+	if err != nil {
+		t.Fatalf("read private key: %v", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err != nil {
+		t.Fatalf("parse private key: %v", err)
+	}
+	return key
+}
+
+// writeKeyPair writes key as PKCS #8 and its public half as PKIX PEM files,
+// the format openssl genpkey and openssl pkey -pubout produce.
+func writeKeyPair(t *testing.T, key crypto.Signer) (privateFile, publicFile string) {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal private key: %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+
+	dir := t.TempDir()
+	privateFile = filepath.Join(dir, "private.pem")
+	publicFile = filepath.Join(dir, "public.pem")
+	if err := os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write private key: %v", err)
+	}
+	if err := os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644); err != nil {
+		t.Fatalf("write public key: %v", err)
+	}
+	return privateFile, publicFile
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RevocationChecker reports whether the access token with the given jti
+// belongs to a session that was logged out or revoked.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// DoctorLookup resolves the doctor profile of a user.
+type DoctorLookup interface {
+	GetByUserID(ctx context.Context, userID int) (domain.Doctor, error)
+}
+
+// Authenticate verifies the bearer token, rejects tokens whose session has
+// been revoked and stores the Principal in the request context.
+func Authenticate(keys *KeySet, revocations RevocationChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				helper.SendJSON(w, http.StatusUnauthorized, domain.Response{Message: "Token tidak ditemukan"})
+				return
+			}
+
+			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+			if tokenString == authHeader {
+				helper.SendJSON(w, http.StatusUnauthorized, domain.Response{Message: "Format token salah"})
+				return
+			}
+
+			var claims domain.Claims
+			token, err := jwt.ParseWithClaims(tokenString, &claims, keys.Keyfunc,
+				jwt.WithValidMethods(keys.ValidMethods()), jwt.WithExpirationRequired())
+			if err != nil || !token.Valid || claims.UserID == 0 {
+				helper.SendJSON(w, http.StatusUnauthorized, domain.Response{Message: "Token tidak valid"})
+				return
+			}
+
+			revoked, err := revocations.IsRevoked(r.Context(), claims.ID)
+			if err != nil {
+				helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: "Gagal memeriksa sesi"})
+				return
+			}
+			if revoked {
+				helper.SendJSON(w, http.StatusUnauthorized, domain.Response{Message: "Sesi sudah berakhir, silakan login kembali"})
+				return
+			}
+
+			ctx := WithPrincipal(r.Context(), Principal{
+				UserID:  claims.UserID,
+				Email:   claims.Email,
+				Role:    claims.Role,
+				TokenID: claims.ID,
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireRole only lets through principals with one of roles. It must run
+// after Authenticate.
+func RequireRole(roles ...domain.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := FromContext(r.Context())
+			if !ok {
+				helper.SendJSON(w, http.StatusUnauthorized, domain.Response{Message: "Token tidak ditemukan"})
+				return
+			}
+
+			for _, allowed := range roles {
+				if p.Role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			helper.SendJSON(w, http.StatusForbidden, domain.Response{Message: "Akses ditolak untuk role ini"})
+		})
+	}
+}
+
+// RequireDoctorProfile only lets through doctors that have a doctor profile
+// and stores the profile for DoctorFromContext. It implies RequireRole(doctor).
+func RequireDoctorProfile(doctors DoctorLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return RequireRole(domain.RoleDoctor)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, _ := FromContext(r.Context())
+
+			doctor, err := doctors.GetByUserID(r.Context(), p.UserID)
+			if err != nil {
+				helper.SendJSON(w, http.StatusForbidden, domain.Response{Message: "profil dokter tidak ditemukan"})
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithDoctor(r.Context(), doctor)))
+		}))
+	}
+}
@@ -0,0 +1,46 @@
+// Package auth authenticates requests and carries the authenticated user
+// through the request context.
+package auth
+
+import (
+	"context"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+// Principal is the authenticated user behind a request.
+type Principal struct {
+	UserID  int
+	Email   string
+	Role    domain.UserRole
+	TokenID string // jti of the access token
+}
+
+// Actor is the principal in the form the service layer expects.
+func (p Principal) Actor() domain.Actor {
+	return domain.Actor{UserID: int64(p.UserID), Role: p.Role}
+}
+
+type principalKey struct{}
+
+type doctorKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored by Authenticate.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+func WithDoctor(ctx context.Context, d domain.Doctor) context.Context {
+	return context.WithValue(ctx, doctorKey{}, d)
+}
+
+// DoctorFromContext returns the doctor profile stored by RequireDoctorProfile.
+func DoctorFromContext(ctx context.Context) (domain.Doctor, bool) {
+	d, ok := ctx.Value(doctorKey{}).(domain.Doctor)
+	return d, ok
+}
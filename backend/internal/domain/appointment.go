@@ -12,8 +12,31 @@ const (
 	AppointmentStatusConfirmed AppointmentStatus = "Confirmed"
 	AppointmentStatusRejected  AppointmentStatus = "Rejected"
 	AppointmentStatusCompleted AppointmentStatus = "Completed"
+	AppointmentStatusCancelled AppointmentStatus = "Cancelled"
+	AppointmentStatusNoShow    AppointmentStatus = "NoShow"
+	AppointmentStatusCheckedIn AppointmentStatus = "CheckedIn"
 )
 
+// appointmentTransitions is the appointment lifecycle: the statuses each
+// status may move to. Statuses without an entry are final. Completed is only
+// reached by writing the medical record, never by a plain status change.
+var appointmentTransitions = map[AppointmentStatus][]AppointmentStatus{
+	AppointmentStatusPending: {
+		AppointmentStatusConfirmed,
+		AppointmentStatusRejected,
+		AppointmentStatusCancelled,
+	},
+	AppointmentStatusConfirmed: {
+		AppointmentStatusCheckedIn,
+		AppointmentStatusCancelled,
+		AppointmentStatusNoShow,
+		AppointmentStatusCompleted,
+	},
+	AppointmentStatusCheckedIn: {
+		AppointmentStatusCompleted,
+	},
+}
+
 type Appointment struct {
 	ID              int               `json:"id"`
 	PatientID       int               `json:"patient_id"`
@@ -27,9 +50,24 @@ type Appointment struct {
 	UpdatedAt       time.Time         `json:"updated_at"`
 
 	// Relations
-	Patient  *User           `json:"patient,omitempty"`
-	Doctor   *Doctor         `json:"doctor,omitempty"`
-	Schedule *DoctorSchedule `json:"schedule,omitempty"`
+	Patient  *User               `json:"patient,omitempty"`
+	Doctor   *Doctor             `json:"doctor,omitempty"`
+	Schedule *DoctorSchedule     `json:"schedule,omitempty"`
+	Changes  []AppointmentChange `json:"changes,omitempty"`
+}
+
+// AppointmentChange is one entry of an appointment's reschedule history.
+type AppointmentChange struct {
+	ID               int       `json:"id"`
+	AppointmentID    int       `json:"appointment_id"`
+	OldDate          time.Time `json:"old_appointment_date"`
+	OldStartTimeSlot string    `json:"old_start_time_slot"`
+	NewDate          time.Time `json:"new_appointment_date"`
+	NewStartTimeSlot string    `json:"new_start_time_slot"`
+	ActorUserID      int       `json:"actor_user_id"`
+	ActorRole        UserRole  `json:"actor_role"`
+	Reason           string    `json:"reason"`
+	CreatedAt        time.Time `json:"created_at"`
 }
 
 type AppointmentRequest struct {
@@ -40,8 +78,14 @@ type AppointmentRequest struct {
 	Complaint       string `json:"complaint"`
 }
 
+type AppointmentRescheduleRequest struct {
+	AppointmentDate string `json:"appointment_date" validate:"required"`
+	StartTimeSlot   string `json:"start_time_slot" validate:"required"`
+	Reason          string `json:"reason"`
+}
+
 type AppointmentUpdateRequest struct {
-	Status AppointmentStatus `json:"status" validate:"required,oneof=Pending Confirmed Rejected Completed"`
+	Status AppointmentStatus `json:"status" validate:"required,oneof=Pending Confirmed Rejected Completed Cancelled NoShow CheckedIn"`
 }
 
 func NormalizeAppointmentStatus(status string) (AppointmentStatus, bool) {
@@ -55,6 +99,12 @@ func NormalizeAppointmentStatus(status string) (AppointmentStatus, bool) {
 		return AppointmentStatusRejected, true
 	case strings.ToLower(string(AppointmentStatusCompleted)), "complete", "completed":
 		return AppointmentStatusCompleted, true
+	case strings.ToLower(string(AppointmentStatusCancelled)), "cancel", "canceled":
+		return AppointmentStatusCancelled, true
+	case strings.ToLower(string(AppointmentStatusNoShow)), "no-show", "no_show", "no show":
+		return AppointmentStatusNoShow, true
+	case strings.ToLower(string(AppointmentStatusCheckedIn)), "checked-in", "checked_in", "checked in", "check-in", "checkin":
+		return AppointmentStatusCheckedIn, true
 	default:
 		return AppointmentStatusPending, false
 	}
@@ -64,3 +114,14 @@ func IsValidAppointmentStatus(status string) bool {
 	_, ok := NormalizeAppointmentStatus(status)
 	return ok
 }
+
+// CanTransitionAppointment reports whether an appointment may move from one
+// status to another.
+func CanTransitionAppointment(from, to AppointmentStatus) bool {
+	for _, next := range appointmentTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
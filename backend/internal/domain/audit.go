@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// AuditEvent records that someone read or changed patient related data.
+type AuditEvent struct {
+	ID           int64     `json:"id"`
+	ActorUserID  *int64    `json:"actor_user_id,omitempty"` // nil for unauthenticated or system actions
+	ActorRole    UserRole  `json:"actor_role,omitempty"`
+	Action       string    `json:"action"`
+	Outcome      string    `json:"outcome"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id,omitempty"`
+	PatientID    *int64    `json:"patient_id,omitempty"`
+	IP           string    `json:"ip,omitempty"`
+	RequestID    string    `json:"request_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+const (
+	AuditActionView   = "view"
+	AuditActionList   = "list"
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionCancel = "cancel"
+	// AuditActionReschedule and AuditActionStatus change an appointment
+	AuditActionReschedule = "reschedule"
+	AuditActionStatus     = "status_change"
+)
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeDenied  = "denied"
+)
+
+const (
+	AuditResourceAppointment   = "appointment"
+	AuditResourcePatient       = "patient"
+	AuditResourceMedicalRecord = "medical_record"
+	AuditResourceDoctor        = "doctor"
+)
+
+// AuditFilter narrows GET /api/admin/audit. Zero values match everything.
+type AuditFilter struct {
+	ActorUserID  int64
+	PatientID    int64
+	Action       string
+	ResourceType string
+	ResourceID   string
+	From         time.Time
+	To           time.Time
+	Limit        int // ignored by the export
+}
@@ -45,3 +45,10 @@ type DoctorRequest struct {
 	Address          string `json:"address"`
 	LicenseNumber    string `json:"license_number" validate:"required"`
 }
+
+// DoctorInviteResponse is returned when an admin creates a doctor or resends
+// the invite. The doctor sets their own password by redeeming Invite.Token.
+type DoctorInviteResponse struct {
+	Doctor Doctor `json:"doctor"`
+	Invite Invite `json:"invite"`
+}
@@ -50,3 +50,23 @@ type DoctorScheduleRequest struct {
 	EndTime      string `json:"end_time" validate:"required"`
 	PatientQuota int    `json:"patient_quota"`
 }
+
+// ScheduleBooking is the number of active appointments booked on one schedule
+// for one date.
+type ScheduleBooking struct {
+	ScheduleID int
+	Date       time.Time
+	Count      int
+}
+
+// AvailabilitySlot is one bookable slot of a doctor's schedule on a concrete date.
+// RemainingQuota is nil when the schedule has no patient limit.
+type AvailabilitySlot struct {
+	Date           string `json:"date"`
+	StartTime      string `json:"start_time"`
+	EndTime        string `json:"end_time"`
+	ScheduleID     int    `json:"schedule_id"`
+	PatientQuota   int    `json:"patient_quota"`
+	RemainingQuota *int   `json:"remaining_quota"`
+	Available      bool   `json:"available"`
+}
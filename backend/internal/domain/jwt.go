@@ -2,7 +2,11 @@ package domain
 
 import "github.com/golang-jwt/jwt/v5"
 
+// Claims is the payload of an access token. RegisteredClaims.ID carries the
+// jti used to look up the session.
 type Claims struct {
-	Name string `json:"name"`
+	UserID int      `json:"user_id"`
+	Email  string   `json:"email"`
+	Role   UserRole `json:"role"`
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// LoginEvent is one attempt on POST /api/login, successful or not.
+type LoginEvent struct {
+	ID        int       `json:"id"`
+	UserID    *int      `json:"user_id,omitempty"` // nil when the email is unknown
+	Email     string    `json:"email"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason,omitempty"` // why a failed attempt failed
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Reasons recorded for failed attempts. They are only shown to admins, the
+// client always gets the same message.
+const (
+	LoginReasonUnknownEmail  = "unknown_email"
+	LoginReasonWrongPassword = "wrong_password"
+	LoginReasonLocked        = "locked"
+	LoginReasonWrongMFACode  = "wrong_mfa_code"
+)
+
+// ClientInfo describes where a request came from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// LoginEventFilter narrows GET /api/admin/login-events. Zero values match
+// everything.
+type LoginEventFilter struct {
+	Email   string
+	IP      string
+	UserID  int
+	Success *bool
+	From    time.Time
+	To      time.Time
+	Limit   int
+}
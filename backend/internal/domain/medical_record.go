@@ -20,7 +20,15 @@ type MedicalRecord struct {
 
 type MedicalRecordRequest struct {
 	AppointmentID int    `json:"appointment_id" validate:"required"`
-	Diagnosis     string `json:"diagnosis"`
+	Diagnosis     string `json:"diagnosis" validate:"required"`
 	Prescription  string `json:"prescription"`
 	DoctorNotes   string `json:"doctor_notes"`
 }
+
+// MedicalRecordFilter narrows a patient's medical history. Zero values mean
+// the filter is not applied.
+type MedicalRecordFilter struct {
+	From     *time.Time
+	To       *time.Time
+	DoctorID int
+}
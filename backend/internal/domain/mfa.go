@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// UserMFA is the TOTP enrollment of a user. It stays pending (EnabledAt nil)
+// until the user proves the authenticator works by entering a code.
+type UserMFA struct {
+	UserID       int        `json:"user_id"`
+	Secret       string     `json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep int64      `json:"-"` // TOTP step of the last accepted code, against replay
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// MFAEnrollment is shown once when enrolling, usually as a QR code of URI.
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFARecoveryCodes are handed out once when MFA is enabled. Each can be used
+// instead of a TOTP code a single time.
+type MFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFAChallengeRequest carries the challenge token returned by POST /api/login.
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+// MFALoginRequest finishes a login that returned mfa_required. Exactly one of
+// Code and RecoveryCode is expected.
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
@@ -18,14 +18,14 @@ const (
 )
 
 type Patient struct {
-	ID          int       `json:"id"`
-	UserID      int       `json:"user_id"`
-	DateOfBirth time.Time `json:"date_of_birth"`
-	Phone       string    `json:"phone"`
-	Address     string    `json:"address"`
-	BloodType   BloodType `json:"blood_type"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	DateOfBirth *time.Time `json:"date_of_birth"`
+	Phone       string     `json:"phone"`
+	Address     string     `json:"address"`
+	BloodType   BloodType  `json:"blood_type"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 
 	// Relations
 	User *User `json:"user,omitempty"`
@@ -39,9 +39,10 @@ type PatientRequest struct {
 	BloodType   string `json:"blood_type" validate:"omitempty,oneof=A+ A- B+ B- AB+ AB- O+ O-"`
 }
 
+// PatientUpdateRequest replaces the patient's demographics; empty fields are cleared.
 type PatientUpdateRequest struct {
-	DateOfBirth string `json:"date_of_birth"`
-	Phone       string `json:"phone"`
+	DateOfBirth string `json:"date_of_birth" validate:"omitempty,datetime=2006-01-02"`
+	Phone       string `json:"phone" validate:"omitempty,max=20,phone"`
 	Address     string `json:"address"`
 	BloodType   string `json:"blood_type" validate:"omitempty,oneof=A+ A- B+ B- AB+ AB- O+ O-"`
 }
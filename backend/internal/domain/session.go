@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// RefreshToken is one link of a login session. Every refresh rotates the
+// token: the old row is marked rotated and a new one is added to the same
+// family. AccessJTI is the jti of the access token issued together with it,
+// which is how AuthMiddleware finds out a session was revoked.
+type RefreshToken struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	FamilyID  string     `json:"family_id"`
+	TokenHash string     `json:"-"`
+	AccessJTI string     `json:"access_jti"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest ends the current session, or every session of the user when All is set.
+type LogoutRequest struct {
+	All bool `json:"all"`
+}
@@ -3,14 +3,15 @@ package domain
 import "time"
 
 type User struct {
-	ID             int       `json:"id"`
-	Name           string    `json:"name"`
-	Email          string    `json:"email"`
-	Password       string    `json:"password"`
-	Role           UserRole  `json:"role"`
-	ProfilePicture string    `json:"profile_picture"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID              int        `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Password        string     `json:"password"`
+	Role            UserRole   `json:"role"`
+	ProfilePicture  string     `json:"profile_picture"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type UserRole string
@@ -21,6 +22,12 @@ const (
 	RolePatient UserRole = "patient"
 )
 
+// Actor is the authenticated user on whose behalf a service call is made.
+type Actor struct {
+	UserID int64
+	Role   UserRole
+}
+
 func IsValidRole(role string) bool {
 	switch UserRole(role) {
 	case RoleAdmin, RoleDoctor, RolePatient:
@@ -30,21 +37,45 @@ func IsValidRole(role string) bool {
 	}
 }
 
+// RegisterRequest is the public sign up form. It only creates patients, staff
+// accounts are made by an admin.
 type RegisterRequest struct {
 	Name           string   `json:"name" validate:"required"`
 	Email          string   `json:"email" validate:"required,email"`
 	Password       string   `json:"password" validate:"required,min=6"`
-	Role           UserRole `json:"role" validate:"omitempty,oneof=admin doctor patient"`
+	Role           UserRole `json:"role" validate:"omitempty,oneof=patient"`
 	ProfilePicture string   `json:"profile_picture"`
 }
 
+// CreateUserRequest is used by admins to create an account on behalf of
+// someone. Doctors go through the doctor endpoints, which also create the
+// doctor profile.
+type CreateUserRequest struct {
+	Name  string   `json:"name" validate:"required"`
+	Email string   `json:"email" validate:"required,email"`
+	Role  UserRole `json:"role" validate:"required,oneof=admin patient"`
+}
+
+// UserInviteResponse is returned when an admin creates an account. The user
+// sets their own password by redeeming Invite.Token.
+type UserInviteResponse struct {
+	User   User   `json:"user"`
+	Invite Invite `json:"invite"`
+}
+
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
 }
 
+// LoginResponse carries the tokens of a completed login. When the account
+// needs a second factor the tokens are absent and MFAToken has to be
+// exchanged at POST /api/login/mfa instead.
 type LoginResponse struct {
-	User      User      `json:"user"`
-	Token     string    `json:"token"`
-	ExpiresAt time.Time `json:"expires_at"`
+	User User `json:"user"`
+	*TokenPair
+	MFARequired           bool     `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string   `json:"mfa_token,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"`
 }
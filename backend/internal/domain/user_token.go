@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// TokenPurpose tells what a one-time user token may be redeemed for.
+type TokenPurpose string
+
+const (
+	TokenPurposeInvite        TokenPurpose = "invite"
+	TokenPurposePasswordReset TokenPurpose = "password_reset"
+	TokenPurposeVerifyEmail   TokenPurpose = "verify_email"
+	TokenPurposeMFAChallenge  TokenPurpose = "mfa_challenge"
+)
+
+// UserToken is a single-use token bound to a user. Only the SHA-256 hash of
+// the token is stored; the raw value is handed out once and never persisted.
+type UserToken struct {
+	ID        int          `json:"id"`
+	UserID    int          `json:"user_id"`
+	Purpose   TokenPurpose `json:"purpose"`
+	TokenHash string       `json:"-"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    *time.Time   `json:"used_at,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// Invite is the raw invite token returned to the admin who created the account.
+type Invite struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type AcceptInviteRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/service"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
+)
+
+type AccountHandler struct {
+	service service.AccountService
+}
+
+func NewAccountHandler(s service.AccountService) *AccountHandler {
+	return &AccountHandler{service: s}
+}
+
+// ForgotPassword always answers 200 so callers cannot probe which emails
+// have an account.
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req domain.ForgotPasswordRequest
+	if err := helper.ParseBody(r, &req); err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	validationErrors := helper.ValidateStruct(req)
+	if len(validationErrors) > 0 {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Validation failed",
+			Data:    validationErrors,
+		})
+		return
+	}
+
+	if err := h.service.ForgotPassword(r.Context(), req.Email); err != nil {
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: err.Error()})
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "Jika email terdaftar, tautan reset password sudah dikirim"})
+}
+
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req domain.ResetPasswordRequest
+	if err := helper.ParseBody(r, &req); err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	validationErrors := helper.ValidateStruct(req)
+	if len(validationErrors) > 0 {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Validation failed",
+			Data:    validationErrors,
+		})
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), req); err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: err.Error()})
+			return
+		}
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: err.Error()})
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "Password berhasil diganti, silakan login kembali"})
+}
+
+// VerifyEmail is opened from the link in the verification mail (?token=...).
+func (h *AccountHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "token wajib diisi"})
+		return
+	}
+
+	if err := h.service.VerifyEmail(r.Context(), token); err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: err.Error()})
+			return
+		}
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: err.Error()})
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "Email berhasil diverifikasi"})
+}
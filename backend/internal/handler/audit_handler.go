@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/service"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
+)
+
+type AuditHandler struct {
+	service service.AuditService
+}
+
+func NewAuditHandler(s service.AuditService) *AuditHandler {
+	return &AuditHandler{service: s}
+}
+
+// GetAuditEvents lists audit events, newest first.
+// Query params: actor_user_id, patient_id, action, resource_type,
+// resource_id, from, to (YYYY-MM-DD or RFC 3339) and limit.
+func (h *AuditHandler) GetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := h.service.Events(r.Context(), filter)
+	if err != nil {
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: err.Error()})
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "audit events loaded", Data: events})
+}
+
+// ExportAuditEvents streams the matching events as NDJSON, one event per
+// line, oldest first. It takes the same filters as GetAuditEvents; limit is
+// ignored.
+func (h *AuditHandler) ExportAuditEvents(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+
+	// A large export outlives the server's write timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Println("WARNING: cannot lift the write deadline of the audit export:", err)
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.ndjson"`)
+	w.WriteHeader(http.StatusOK)
+
+	// Encode appends the newline that terminates each record
+	enc := json.NewEncoder(w)
+	err := h.service.Export(r.Context(), filter, func(e domain.AuditEvent) error {
+		return enc.Encode(e)
+	})
+	if err != nil {
+		// The status line is already sent, all we can do is cut the stream short
+		log.Println("ERROR exporting audit events:", err)
+	}
+}
+
+func parseAuditFilter(w http.ResponseWriter, r *http.Request) (domain.AuditFilter, bool) {
+	query := r.URL.Query()
+	filter := domain.AuditFilter{
+		Action:       query.Get("action"),
+		ResourceType: query.Get("resource_type"),
+		ResourceID:   query.Get("resource_id"),
+	}
+
+	var err error
+	if v := query.Get("actor_user_id"); v != "" {
+		if filter.ActorUserID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid actor_user_id"})
+			return filter, false
+		}
+	}
+	if v := query.Get("patient_id"); v != "" {
+		if filter.PatientID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid patient_id"})
+			return filter, false
+		}
+	}
+	if v := query.Get("from"); v != "" {
+		if filter.From, err = parseTimeParam(v); err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid from format (YYYY-MM-DD)"})
+			return filter, false
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if filter.To, err = parseEndParam(v); err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid to format (YYYY-MM-DD)"})
+			return filter, false
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "limit harus berupa angka positif"})
+			return filter, false
+		}
+	}
+
+	return filter, true
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/JinXVIII/BE-Medical-Record/internal/auth"
 	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
 	"github.com/JinXVIII/BE-Medical-Record/internal/service"
 	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
@@ -14,37 +15,23 @@ import (
 )
 
 type DoctorAppointmentHandler struct {
-	service       service.PatientService
-	doctorService service.DoctorService
+	service service.PatientService
 }
 
-var (
-	errUserContextMissing  = errors.New("user not found in context")
-	errDoctorRoleRequired  = errors.New("hanya dokter yang bisa mengakses endpoint ini")
-	errDoctorProfileAbsent = errors.New("profil dokter tidak ditemukan")
-)
+var errDoctorProfileAbsent = errors.New("profil dokter tidak ditemukan")
 
-func NewDoctorAppointmentHandler(ps service.PatientService, ds service.DoctorService) *DoctorAppointmentHandler {
-	return &DoctorAppointmentHandler{service: ps, doctorService: ds}
+func NewDoctorAppointmentHandler(ps service.PatientService) *DoctorAppointmentHandler {
+	return &DoctorAppointmentHandler{service: ps}
 }
 
 func (h *DoctorAppointmentHandler) GetAppointments(w http.ResponseWriter, r *http.Request) {
-	doctorID, err := h.getDoctorIDFromToken(r)
+	doctor, err := currentDoctor(r)
 	if err != nil {
-		status := http.StatusInternalServerError
-		switch {
-		case errors.Is(err, errUserContextMissing):
-			status = http.StatusUnauthorized
-		case errors.Is(err, errDoctorRoleRequired), errors.Is(err, errDoctorProfileAbsent):
-			status = http.StatusForbidden
-		default:
-			status = http.StatusUnauthorized
-		}
-		helper.SendJSON(w, status, domain.Response{Message: err.Error()})
+		respondDoctorAuthError(w, err)
 		return
 	}
 
-	data, err := h.service.GetDoctorAppointments(r.Context(), int64(doctorID))
+	data, err := h.service.GetDoctorAppointments(r.Context(), int64(doctor.ID))
 	if err != nil {
 		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: err.Error()})
 		return
@@ -58,18 +45,9 @@ type updateAppointmentStatusRequest struct {
 }
 
 func (h *DoctorAppointmentHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	doctorID, err := h.getDoctorIDFromToken(r)
+	doctor, err := currentDoctor(r)
 	if err != nil {
-		status := http.StatusInternalServerError
-		switch {
-		case errors.Is(err, errUserContextMissing):
-			status = http.StatusUnauthorized
-		case errors.Is(err, errDoctorRoleRequired), errors.Is(err, errDoctorProfileAbsent):
-			status = http.StatusForbidden
-		default:
-			status = http.StatusUnauthorized
-		}
-		helper.SendJSON(w, status, domain.Response{Message: err.Error()})
+		respondDoctorAuthError(w, err)
 		return
 	}
 
@@ -91,17 +69,14 @@ func (h *DoctorAppointmentHandler) UpdateStatus(w http.ResponseWriter, r *http.R
 		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "status tidak dikenal"})
 		return
 	}
-	if statusValue == domain.AppointmentStatusPending {
-		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "status tidak boleh kembali ke Pending"})
-		return
-	}
-
-	if err := h.service.UpdateAppointmentStatus(r.Context(), int64(doctorID), appointmentID, statusValue); err != nil {
+	if err := h.service.UpdateAppointmentStatus(r.Context(), int64(doctor.ID), appointmentID, statusValue); err != nil {
 		switch {
 		case errors.Is(err, service.ErrNotAllowed):
 			helper.SendJSON(w, http.StatusForbidden, domain.Response{Message: err.Error()})
 		case errors.Is(err, service.ErrInvalidStatus):
 			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: err.Error()})
+		case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrCompleteByRecord):
+			helper.SendJSON(w, http.StatusConflict, domain.Response{Message: err.Error()})
 		case errors.Is(err, sql.ErrNoRows):
 			helper.SendJSON(w, http.StatusNotFound, domain.Response{Message: "appointment not found"})
 		default:
@@ -113,26 +88,88 @@ func (h *DoctorAppointmentHandler) UpdateStatus(w http.ResponseWriter, r *http.R
 	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "status updated"})
 }
 
-func (h *DoctorAppointmentHandler) getDoctorIDFromToken(r *http.Request) (int, error) {
-	userInfo, ok := r.Context().Value("user").(map[string]interface{})
-	if !ok {
-		return 0, errUserContextMissing
+// Reschedule moves an appointment of the doctor to another date and slot.
+func (h *DoctorAppointmentHandler) Reschedule(w http.ResponseWriter, r *http.Request) {
+	doctor, err := currentDoctor(r)
+	if err != nil {
+		respondDoctorAuthError(w, err)
+		return
 	}
 
-	role, _ := userInfo["role"].(string)
-	if role != "doctor" {
-		return 0, errDoctorRoleRequired
+	appointmentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid appointment id"})
+		return
 	}
 
-	userIDFloat, ok := userInfo["user_id"].(float64)
-	if !ok {
-		return 0, errUserContextMissing
+	var req domain.AppointmentRescheduleRequest
+	if err := helper.ParseBody(r, &req); err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "Invalid request body: " + err.Error()})
+		return
+	}
+	date, slot, err := parseRescheduleRequest(req)
+	if err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: err.Error()})
+		return
+	}
+
+	ap, err := h.service.RescheduleByDoctor(r.Context(), int64(doctor.UserID), int64(doctor.ID), appointmentID, date, slot, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotAllowed):
+			helper.SendJSON(w, http.StatusForbidden, domain.Response{Message: err.Error()})
+		case errors.Is(err, service.ErrQuotaFull), errors.Is(err, service.ErrNotReschedulable):
+			helper.SendJSON(w, http.StatusConflict, domain.Response{Message: err.Error()})
+		case errors.Is(err, service.ErrDoctorOffDay), errors.Is(err, service.ErrSlotOutsideSchedule), errors.Is(err, service.ErrSameSlot):
+			helper.SendJSON(w, http.StatusUnprocessableEntity, domain.Response{Message: err.Error()})
+		case errors.Is(err, sql.ErrNoRows):
+			helper.SendJSON(w, http.StatusNotFound, domain.Response{Message: "appointment not found"})
+		default:
+			helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: err.Error()})
+		}
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "appointment rescheduled", Data: ap})
+}
+
+// GetPatient shows the demographics of a patient who has booked the doctor.
+func (h *DoctorAppointmentHandler) GetPatient(w http.ResponseWriter, r *http.Request) {
+	doctor, err := currentDoctor(r)
+	if err != nil {
+		respondDoctorAuthError(w, err)
+		return
 	}
 
-	doctor, err := h.doctorService.GetByUserID(r.Context(), int(userIDFloat))
+	patientID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		return 0, errDoctorProfileAbsent
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid patient id"})
+		return
+	}
+
+	actor := domain.Actor{UserID: int64(doctor.UserID), Role: domain.RoleDoctor}
+	patient, err := h.service.GetPatientProfile(r.Context(), actor, patientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helper.SendJSON(w, http.StatusNotFound, domain.Response{Message: "patient not found"})
+			return
+		}
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: err.Error()})
+		return
 	}
 
-	return doctor.ID, nil
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "patient loaded", Data: patient})
+}
+
+// currentDoctor returns the doctor profile loaded by auth.RequireDoctorProfile.
+func currentDoctor(r *http.Request) (domain.Doctor, error) {
+	doctor, ok := auth.DoctorFromContext(r.Context())
+	if !ok {
+		return domain.Doctor{}, errDoctorProfileAbsent
+	}
+	return doctor, nil
+}
+
+func respondDoctorAuthError(w http.ResponseWriter, err error) {
+	helper.SendJSON(w, http.StatusForbidden, domain.Response{Message: err.Error()})
 }
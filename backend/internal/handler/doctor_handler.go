@@ -9,6 +9,7 @@ import (
 	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
 	"github.com/JinXVIII/BE-Medical-Record/internal/service"
 	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
+	"github.com/go-chi/chi/v5"
 )
 
 type DoctorHandler interface {
@@ -18,6 +19,7 @@ type DoctorHandler interface {
 	UpdateDoctor(w http.ResponseWriter, r *http.Request)
 	DeleteDoctor(w http.ResponseWriter, r *http.Request)
 	SearchDoctors(w http.ResponseWriter, r *http.Request)
+	ResendInvite(w http.ResponseWriter, r *http.Request)
 }
 
 type DoctorHandlerImpl struct {
@@ -31,15 +33,6 @@ func NewDoctorHandler(service service.DoctorService) DoctorHandler {
 }
 
 func (h *DoctorHandlerImpl) CreateDoctor(w http.ResponseWriter, r *http.Request) {
-	// Check if user is admin
-	if err := h.checkAdminRole(r); err != nil {
-		helper.SendJSON(w, http.StatusForbidden, domain.Response{
-			Message: "Access denied: " + err.Error(),
-			Data:    nil,
-		})
-		return
-	}
-
 	var req domain.DoctorRequest
 
 	// Parsing body request
@@ -61,7 +54,7 @@ func (h *DoctorHandlerImpl) CreateDoctor(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	doctor, err := h.Service.CreateDoctor(r.Context(), req)
+	created, err := h.Service.CreateDoctor(r.Context(), req)
 	if err != nil {
 		errorMessage := err.Error()
 
@@ -73,7 +66,8 @@ func (h *DoctorHandlerImpl) CreateDoctor(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		if strings.Contains(errorMessage, "email sudah ada") {
+		// Taken emails and license numbers
+		if strings.Contains(errorMessage, "email sudah ada") || strings.Contains(errorMessage, "already exists") {
 			helper.SendJSON(w, http.StatusConflict, domain.Response{
 				Message: errorMessage,
 				Data:    nil,
@@ -89,23 +83,13 @@ func (h *DoctorHandlerImpl) CreateDoctor(w http.ResponseWriter, r *http.Request)
 	}
 
 	helper.SendJSON(w, http.StatusCreated, domain.Response{
-		Message: "Doctor and user created successfully",
-		Data:    doctor,
+		Message: "Doctor created, send the invite token so the doctor can set a password",
+		Data:    created,
 	})
 }
 
 func (h *DoctorHandlerImpl) GetDoctorByID(w http.ResponseWriter, r *http.Request) {
-	// Check if user is admin
-	if err := h.checkAdminRole(r); err != nil {
-		helper.SendJSON(w, http.StatusForbidden, domain.Response{
-			Message: "Access denied: " + err.Error(),
-			Data:    nil,
-		})
-		return
-	}
-
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/admin/doctors/")
-	id, err := strconv.Atoi(idStr)
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
 		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
 			Message: "Invalid doctor ID",
@@ -140,15 +124,6 @@ func (h *DoctorHandlerImpl) GetDoctorByID(w http.ResponseWriter, r *http.Request
 }
 
 func (h *DoctorHandlerImpl) GetAllDoctors(w http.ResponseWriter, r *http.Request) {
-	// Check if user is admin
-	if err := h.checkAdminRole(r); err != nil {
-		helper.SendJSON(w, http.StatusForbidden, domain.Response{
-			Message: "Access denied: " + err.Error(),
-			Data:    nil,
-		})
-		return
-	}
-
 	doctors, err := h.Service.GetAllDoctors(r.Context())
 	if err != nil {
 		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{
@@ -165,17 +140,7 @@ func (h *DoctorHandlerImpl) GetAllDoctors(w http.ResponseWriter, r *http.Request
 }
 
 func (h *DoctorHandlerImpl) UpdateDoctor(w http.ResponseWriter, r *http.Request) {
-	// Check if user is admin
-	if err := h.checkAdminRole(r); err != nil {
-		helper.SendJSON(w, http.StatusForbidden, domain.Response{
-			Message: "Access denied: " + err.Error(),
-			Data:    nil,
-		})
-		return
-	}
-
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/admin/doctors/")
-	id, err := strconv.Atoi(idStr)
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
 		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
 			Message: "Invalid doctor ID",
@@ -237,17 +202,7 @@ func (h *DoctorHandlerImpl) UpdateDoctor(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *DoctorHandlerImpl) DeleteDoctor(w http.ResponseWriter, r *http.Request) {
-	// Check if user is admin
-	if err := h.checkAdminRole(r); err != nil {
-		helper.SendJSON(w, http.StatusForbidden, domain.Response{
-			Message: "Access denied: " + err.Error(),
-			Data:    nil,
-		})
-		return
-	}
-
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/admin/doctors/")
-	id, err := strconv.Atoi(idStr)
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
 		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
 			Message: "Invalid doctor ID",
@@ -282,21 +237,49 @@ func (h *DoctorHandlerImpl) DeleteDoctor(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// Helper function to check if user has admin role
-func (h *DoctorHandlerImpl) checkAdminRole(r *http.Request) error {
-	// Get user info from context (set by JWT middleware)
-	userInfo, ok := r.Context().Value("user").(map[string]interface{})
-	if !ok {
-		return errors.New("user not found in context")
+// ResendInvite issues a new invite token for a doctor who has not set a
+// password yet. Earlier invites stop working.
+func (h *DoctorHandlerImpl) ResendInvite(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Invalid doctor ID",
+			Data:    nil,
+		})
+		return
 	}
 
-	// Check if user role is admin
-	role, ok := userInfo["role"].(string)
-	if !ok || role != "admin" {
-		return errors.New("only admins can access this endpoint")
+	resent, err := h.Service.ResendInvite(r.Context(), id)
+	if err != nil {
+		errorMessage := err.Error()
+
+		if strings.Contains(errorMessage, "not found") {
+			helper.SendJSON(w, http.StatusNotFound, domain.Response{
+				Message: errorMessage,
+				Data:    nil,
+			})
+			return
+		}
+
+		if errors.Is(err, service.ErrInviteAccepted) {
+			helper.SendJSON(w, http.StatusConflict, domain.Response{
+				Message: errorMessage,
+				Data:    nil,
+			})
+			return
+		}
+
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{
+			Message: errorMessage,
+			Data:    nil,
+		})
+		return
 	}
 
-	return nil
+	helper.SendJSON(w, http.StatusOK, domain.Response{
+		Message: "Invite issued successfully",
+		Data:    resent,
+	})
 }
 
 func (h *DoctorHandlerImpl) SearchDoctors(w http.ResponseWriter, r *http.Request) {
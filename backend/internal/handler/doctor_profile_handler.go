@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/JinXVIII/BE-Medical-Record/internal/auth"
 	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
 	"github.com/JinXVIII/BE-Medical-Record/internal/service"
 	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
@@ -25,52 +26,11 @@ func NewDoctorProfileHandler(doctorService service.DoctorService) DoctorProfileH
 }
 
 func (h *DoctorProfileHandlerImpl) GetMyProfile(w http.ResponseWriter, r *http.Request) {
-	// Get user info from JWT token
-	userInfo, ok := r.Context().Value("user").(map[string]interface{})
+	// Profile is loaded by auth.RequireDoctorProfile
+	doctor, ok := auth.DoctorFromContext(r.Context())
 	if !ok {
-		helper.SendJSON(w, http.StatusUnauthorized, domain.Response{
-			Message: "user not found in context",
-			Data:    nil,
-		})
-		return
-	}
-
-	// Check if user role is doctor
-	role, ok := userInfo["role"].(string)
-	if !ok || role != "doctor" {
-		helper.SendJSON(w, http.StatusForbidden, domain.Response{
-			Message: "access denied: only doctors can access this endpoint",
-			Data:    nil,
-		})
-		return
-	}
-
-	// Get user ID
-	userIDFloat, ok := userInfo["user_id"].(float64)
-	if !ok {
-		helper.SendJSON(w, http.StatusUnauthorized, domain.Response{
-			Message: "invalid user ID in token",
-			Data:    nil,
-		})
-		return
-	}
-
-	userID := int(userIDFloat)
-
-	doctor, err := h.DoctorService.GetByUserID(r.Context(), userID)
-	if err != nil {
-		errorMessage := err.Error()
-
-		if strings.Contains(errorMessage, "not found") {
-			helper.SendJSON(w, http.StatusNotFound, domain.Response{
-				Message: "doctor profile not found",
-				Data:    nil,
-			})
-			return
-		}
-
-		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{
-			Message: errorMessage,
+		helper.SendJSON(w, http.StatusNotFound, domain.Response{
+			Message: "doctor profile not found",
 			Data:    nil,
 		})
 		return
@@ -83,8 +43,8 @@ func (h *DoctorProfileHandlerImpl) GetMyProfile(w http.ResponseWriter, r *http.R
 }
 
 func (h *DoctorProfileHandlerImpl) UpdateMyProfile(w http.ResponseWriter, r *http.Request) {
-	// Get user info from JWT token
-	userInfo, ok := r.Context().Value("user").(map[string]interface{})
+	// Principal is set by auth.Authenticate
+	principal, ok := auth.FromContext(r.Context())
 	if !ok {
 		helper.SendJSON(w, http.StatusUnauthorized, domain.Response{
 			Message: "user not found in context",
@@ -93,27 +53,7 @@ func (h *DoctorProfileHandlerImpl) UpdateMyProfile(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Check if user role is doctor
-	role, ok := userInfo["role"].(string)
-	if !ok || role != "doctor" {
-		helper.SendJSON(w, http.StatusForbidden, domain.Response{
-			Message: "access denied: only doctors can access this endpoint",
-			Data:    nil,
-		})
-		return
-	}
-
-	// Get user ID
-	userIDFloat, ok := userInfo["user_id"].(float64)
-	if !ok {
-		helper.SendJSON(w, http.StatusUnauthorized, domain.Response{
-			Message: "invalid user ID in token",
-			Data:    nil,
-		})
-		return
-	}
-
-	userID := int(userIDFloat)
+	userID := principal.UserID
 
 	var req domain.DoctorRequest
 
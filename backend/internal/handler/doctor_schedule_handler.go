@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/JinXVIII/BE-Medical-Record/internal/auth"
 	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
 	"github.com/JinXVIII/BE-Medical-Record/internal/service"
 	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
@@ -22,46 +23,31 @@ type DoctorScheduleHandler interface {
 
 type DoctorScheduleHandlerImpl struct {
 	ScheduleService service.DoctorScheduleService
-	DoctorService   service.DoctorService
 }
 
-var (
-	errSchedUserContextMissing = errors.New("user not found in context")
-	errSchedDoctorRoleRequired = errors.New("access denied: only doctors can access this endpoint")
-	errSchedInvalidUserID      = errors.New("invalid user ID in token")
-	errSchedDoctorProfile      = errors.New("doctor not found for this user")
-)
+var errSchedDoctorProfile = errors.New("doctor not found for this user")
 
 func respondScheduleAuthError(w http.ResponseWriter, err error) {
-	status := http.StatusUnauthorized
-	switch {
-	case errors.Is(err, errSchedUserContextMissing), errors.Is(err, errSchedInvalidUserID):
-		status = http.StatusUnauthorized
-	case errors.Is(err, errSchedDoctorRoleRequired), errors.Is(err, errSchedDoctorProfile):
-		status = http.StatusForbidden
-	default:
-		status = http.StatusUnauthorized
-	}
-	helper.SendJSON(w, status, domain.Response{
+	helper.SendJSON(w, http.StatusForbidden, domain.Response{
 		Message: err.Error(),
 		Data:    nil,
 	})
 }
 
-func NewDoctorScheduleHandler(scheduleService service.DoctorScheduleService, doctorService service.DoctorService) DoctorScheduleHandler {
+func NewDoctorScheduleHandler(scheduleService service.DoctorScheduleService) DoctorScheduleHandler {
 	return &DoctorScheduleHandlerImpl{
 		ScheduleService: scheduleService,
-		DoctorService:   doctorService,
 	}
 }
 
 func (h *DoctorScheduleHandlerImpl) GetMySchedules(w http.ResponseWriter, r *http.Request) {
-	// Get doctor ID from JWT token
-	doctorID, err := h.getDoctorIDFromToken(r)
-	if err != nil {
-		respondScheduleAuthError(w, err)
+	// Doctor is loaded by auth.RequireDoctorProfile
+	doctor, ok := auth.DoctorFromContext(r.Context())
+	if !ok {
+		respondScheduleAuthError(w, errSchedDoctorProfile)
 		return
 	}
+	doctorID := doctor.ID
 
 	schedules, err := h.ScheduleService.GetSchedulesByDoctorID(r.Context(), doctorID)
 	if err != nil {
@@ -89,12 +75,13 @@ func (h *DoctorScheduleHandlerImpl) GetMySchedules(w http.ResponseWriter, r *htt
 }
 
 func (h *DoctorScheduleHandlerImpl) CreateSchedule(w http.ResponseWriter, r *http.Request) {
-	// Get doctor ID from JWT token
-	doctorID, err := h.getDoctorIDFromToken(r)
-	if err != nil {
-		respondScheduleAuthError(w, err)
+	// Doctor is loaded by auth.RequireDoctorProfile
+	doctor, ok := auth.DoctorFromContext(r.Context())
+	if !ok {
+		respondScheduleAuthError(w, errSchedDoctorProfile)
 		return
 	}
+	doctorID := doctor.ID
 
 	var req domain.DoctorScheduleRequest
 
@@ -107,7 +94,7 @@ func (h *DoctorScheduleHandlerImpl) CreateSchedule(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Set doctor ID from token
+	// Set doctor ID from the loaded profile
 	req.DoctorID = doctorID
 
 	// Validation
@@ -154,12 +141,13 @@ func (h *DoctorScheduleHandlerImpl) CreateSchedule(w http.ResponseWriter, r *htt
 }
 
 func (h *DoctorScheduleHandlerImpl) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
-	// Get doctor ID from JWT token
-	doctorID, err := h.getDoctorIDFromToken(r)
-	if err != nil {
-		respondScheduleAuthError(w, err)
+	// Doctor is loaded by auth.RequireDoctorProfile
+	doctor, ok := auth.DoctorFromContext(r.Context())
+	if !ok {
+		respondScheduleAuthError(w, errSchedDoctorProfile)
 		return
 	}
+	doctorID := doctor.ID
 
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/doctor/schedules/")
 	id, err := strconv.Atoi(idStr)
@@ -198,7 +186,7 @@ func (h *DoctorScheduleHandlerImpl) UpdateSchedule(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Set doctor ID from token
+	// Set doctor ID from the loaded profile
 	req.DoctorID = doctorID
 
 	// Validation
@@ -245,12 +233,13 @@ func (h *DoctorScheduleHandlerImpl) UpdateSchedule(w http.ResponseWriter, r *htt
 }
 
 func (h *DoctorScheduleHandlerImpl) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
-	// Get doctor ID from JWT token
-	doctorID, err := h.getDoctorIDFromToken(r)
-	if err != nil {
-		respondScheduleAuthError(w, err)
+	// Doctor is loaded by auth.RequireDoctorProfile
+	doctor, ok := auth.DoctorFromContext(r.Context())
+	if !ok {
+		respondScheduleAuthError(w, errSchedDoctorProfile)
 		return
 	}
+	doctorID := doctor.ID
 
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/doctor/schedules/")
 	id, err := strconv.Atoi(idStr)
@@ -305,38 +294,6 @@ func (h *DoctorScheduleHandlerImpl) DeleteSchedule(w http.ResponseWriter, r *htt
 	})
 }
 
-// Helper function to extract doctor ID from JWT token
-func (h *DoctorScheduleHandlerImpl) getDoctorIDFromToken(r *http.Request) (int, error) {
-	// Get user info from context (set by JWT middleware)
-	userInfo, ok := r.Context().Value("user").(map[string]interface{})
-	if !ok {
-		return 0, errSchedUserContextMissing
-	}
-
-	// Check if user role is doctor
-	role, ok := userInfo["role"].(string)
-	if !ok || role != "doctor" {
-		return 0, errSchedDoctorRoleRequired
-	}
-
-	// Get user ID
-	userIDFloat, ok := userInfo["user_id"].(float64)
-	if !ok {
-		return 0, errSchedInvalidUserID
-	}
-
-	userID := int(userIDFloat)
-
-	// Get doctor ID from user ID
-	doctor, err := h.DoctorService.GetByUserID(r.Context(), userID)
-	if err != nil {
-		return 0, errSchedDoctorProfile
-	}
-
-	return doctor.ID, nil
-
-}
-
 func (h *DoctorScheduleHandlerImpl) GetDoctorSchedules(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 
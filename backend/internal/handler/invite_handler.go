@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/service"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
+)
+
+type InviteHandler struct {
+	service service.InviteService
+}
+
+func NewInviteHandler(s service.InviteService) *InviteHandler {
+	return &InviteHandler{service: s}
+}
+
+// AcceptInvite lets an invited user set their password with the invite token.
+func (h *InviteHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+	var req domain.AcceptInviteRequest
+	if err := helper.ParseBody(r, &req); err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	validationErrors := helper.ValidateStruct(req)
+	if len(validationErrors) > 0 {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Validation failed",
+			Data:    validationErrors,
+		})
+		return
+	}
+
+	if err := h.service.Accept(r.Context(), req); err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: err.Error()})
+			return
+		}
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: err.Error()})
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "password set, you can now log in"})
+}
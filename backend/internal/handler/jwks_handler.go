@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/auth"
+)
+
+type JWKSHandler struct {
+	keys *auth.KeySet
+}
+
+func NewJWKSHandler(keys *auth.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS publishes the public keys other services verify access tokens with.
+// The body is a bare JWK set, not wrapped in domain.Response.
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_ = json.NewEncoder(w).Encode(h.keys.JWKS())
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/service"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
+)
+
+type LoginEventHandler struct {
+	guard service.LoginGuard
+}
+
+func NewLoginEventHandler(g service.LoginGuard) *LoginEventHandler {
+	return &LoginEventHandler{guard: g}
+}
+
+// GetLoginEvents lists login attempts, newest first.
+// Query params: email, ip, user_id, success (true/false), from, to
+// (YYYY-MM-DD or RFC 3339) and limit.
+func (h *LoginEventHandler) GetLoginEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.LoginEventFilter{
+		Email: query.Get("email"),
+		IP:    query.Get("ip"),
+	}
+
+	var err error
+	if v := query.Get("user_id"); v != "" {
+		if filter.UserID, err = strconv.Atoi(v); err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid user_id"})
+			return
+		}
+	}
+	if v := query.Get("success"); v != "" {
+		success, err := strconv.ParseBool(v)
+		if err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "success harus true atau false"})
+			return
+		}
+		filter.Success = &success
+	}
+	if v := query.Get("from"); v != "" {
+		if filter.From, err = parseTimeParam(v); err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid from format (YYYY-MM-DD)"})
+			return
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if filter.To, err = parseEndParam(v); err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid to format (YYYY-MM-DD)"})
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "limit harus berupa angka positif"})
+			return
+		}
+	}
+
+	events, err := h.guard.Events(r.Context(), filter)
+	if err != nil {
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: err.Error()})
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "login events loaded", Data: events})
+}
+
+// parseTimeParam accepts a date or an RFC 3339 timestamp.
+func parseTimeParam(v string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// parseEndParam parses the exclusive upper bound of a range. A bare date
+// means up to the end of that day, so to=2026-10-17 includes the 17th.
+func parseEndParam(v string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/service"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
+	"github.com/go-chi/chi/v5"
+)
+
+type MedicalRecordHandler struct {
+	service service.MedicalRecordService
+}
+
+func NewMedicalRecordHandler(ms service.MedicalRecordService) *MedicalRecordHandler {
+	return &MedicalRecordHandler{service: ms}
+}
+
+func (h *MedicalRecordHandler) GetRecord(w http.ResponseWriter, r *http.Request) {
+	doctor, err := currentDoctor(r)
+	if err != nil {
+		respondDoctorAuthError(w, err)
+		return
+	}
+
+	appointmentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid appointment id"})
+		return
+	}
+
+	record, err := h.service.GetRecordForDoctor(r.Context(), int64(doctor.ID), appointmentID)
+	if err != nil {
+		respondMedicalRecordError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "medical record loaded", Data: record})
+}
+
+func (h *MedicalRecordHandler) CreateRecord(w http.ResponseWriter, r *http.Request) {
+	doctorID, appointmentID, req, ok := h.parseWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := h.service.CreateRecord(r.Context(), int64(doctorID), appointmentID, req)
+	if err != nil {
+		respondMedicalRecordError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusCreated, domain.Response{Message: "medical record created", Data: record})
+}
+
+func (h *MedicalRecordHandler) UpdateRecord(w http.ResponseWriter, r *http.Request) {
+	doctorID, appointmentID, req, ok := h.parseWriteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := h.service.UpdateRecord(r.Context(), int64(doctorID), appointmentID, req)
+	if err != nil {
+		respondMedicalRecordError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "medical record updated", Data: record})
+}
+
+// GetMyRecords returns the medical history of the logged in patient.
+// Optional query params: from, to (YYYY-MM-DD) and doctor_id.
+func (h *MedicalRecordHandler) GetMyRecords(w http.ResponseWriter, r *http.Request) {
+	userID, err := getPatientUserID(r)
+	if err != nil {
+		helper.SendJSON(w, http.StatusUnauthorized, domain.Response{Message: err.Error()})
+		return
+	}
+
+	var filter domain.MedicalRecordFilter
+	query := r.URL.Query()
+	if v := query.Get("from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid from format (YYYY-MM-DD)"})
+			return
+		}
+		filter.From = &from
+	}
+	if v := query.Get("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid to format (YYYY-MM-DD)"})
+			return
+		}
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "from must not be after to"})
+		return
+	}
+	if v := query.Get("doctor_id"); v != "" {
+		doctorID, err := strconv.Atoi(v)
+		if err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid doctor_id"})
+			return
+		}
+		filter.DoctorID = doctorID
+	}
+
+	records, err := h.service.GetPatientRecords(r.Context(), userID, filter)
+	if err != nil {
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: err.Error()})
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "medical records loaded", Data: records})
+}
+
+func (h *MedicalRecordHandler) parseWriteRequest(w http.ResponseWriter, r *http.Request) (int, int64, domain.MedicalRecordRequest, bool) {
+	var req domain.MedicalRecordRequest
+
+	doctor, err := currentDoctor(r)
+	if err != nil {
+		respondDoctorAuthError(w, err)
+		return 0, 0, req, false
+	}
+
+	appointmentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "invalid appointment id"})
+		return 0, 0, req, false
+	}
+
+	if err := helper.ParseBody(r, &req); err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{Message: "Invalid request body: " + err.Error()})
+		return 0, 0, req, false
+	}
+
+	// Appointment ID always comes from the URL
+	req.AppointmentID = int(appointmentID)
+
+	validationErrors := helper.ValidateStruct(req)
+	if len(validationErrors) > 0 {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Validation failed",
+			Data:    validationErrors,
+		})
+		return 0, 0, req, false
+	}
+
+	return doctor.ID, appointmentID, req, true
+}
+
+func respondMedicalRecordError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotAllowed):
+		helper.SendJSON(w, http.StatusForbidden, domain.Response{Message: err.Error()})
+	case errors.Is(err, service.ErrRecordExists), errors.Is(err, service.ErrRecordNotWriteable):
+		helper.SendJSON(w, http.StatusConflict, domain.Response{Message: err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		helper.SendJSON(w, http.StatusNotFound, domain.Response{Message: "appointment or medical record not found"})
+	default:
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{Message: err.Error()})
+	}
+}
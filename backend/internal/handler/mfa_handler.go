@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/auth"
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/service"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
+)
+
+// LoginMFA exchanges the challenge token from Login plus a TOTP or recovery
+// code for the access and refresh tokens.
+func (h *UserHandlerImpl) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req domain.MFALoginRequest
+	if !parseAndValidate(w, r, &req) {
+		return
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "code atau recovery_code wajib diisi",
+			Data:    nil,
+		})
+		return
+	}
+
+	resp, err := h.Service.LoginMFA(r.Context(), req, clientInfo(r))
+	if err != nil {
+		respondMFAError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{
+		Message: "Login successful",
+		Data:    resp,
+	})
+}
+
+// LoginMFAEnroll hands out a TOTP secret to a user whose role requires MFA
+// but who has not enrolled yet, using the challenge token from Login.
+func (h *UserHandlerImpl) LoginMFAEnroll(w http.ResponseWriter, r *http.Request) {
+	var req domain.MFAChallengeRequest
+	if !parseAndValidate(w, r, &req) {
+		return
+	}
+
+	enrollment, err := h.Service.EnrollMFAWithChallenge(r.Context(), req.MFAToken)
+	if err != nil {
+		respondMFAError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{
+		Message: "Scan the otpauth URI, then log in with a code",
+		Data:    enrollment,
+	})
+}
+
+func (h *UserHandlerImpl) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		helper.SendJSON(w, http.StatusUnauthorized, domain.Response{Message: "user not found in context"})
+		return
+	}
+
+	enrollment, err := h.Service.EnrollMFA(r.Context(), principal.UserID)
+	if err != nil {
+		respondMFAError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{
+		Message: "Scan the otpauth URI, then confirm with a code",
+		Data:    enrollment,
+	})
+}
+
+// ConfirmMFA enables MFA and returns the recovery codes, which are not shown again.
+func (h *UserHandlerImpl) ConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		helper.SendJSON(w, http.StatusUnauthorized, domain.Response{Message: "user not found in context"})
+		return
+	}
+
+	var req domain.MFACodeRequest
+	if !parseAndValidate(w, r, &req) {
+		return
+	}
+
+	codes, err := h.Service.ConfirmMFA(r.Context(), principal.UserID, req.Code)
+	if err != nil {
+		respondMFAError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{
+		Message: "MFA enabled",
+		Data:    codes,
+	})
+}
+
+func (h *UserHandlerImpl) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		helper.SendJSON(w, http.StatusUnauthorized, domain.Response{Message: "user not found in context"})
+		return
+	}
+
+	var req domain.MFACodeRequest
+	if !parseAndValidate(w, r, &req) {
+		return
+	}
+
+	if err := h.Service.DisableMFA(r.Context(), principal.UserID, req.Code); err != nil {
+		respondMFAError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{Message: "MFA disabled"})
+}
+
+// parseAndValidate decodes the body into req and answers 400 on failure.
+func parseAndValidate(w http.ResponseWriter, r *http.Request, req any) bool {
+	if err := helper.ParseBody(r, req); err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Invalid request body: " + err.Error(),
+			Data:    nil,
+		})
+		return false
+	}
+
+	validationErrors := helper.ValidateStruct(req)
+	if len(validationErrors) > 0 {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Validation failed",
+			Data:    validationErrors,
+		})
+		return false
+	}
+	return true
+}
+
+func respondMFAError(w http.ResponseWriter, err error) {
+	if respondLoginLocked(w, err) {
+		return
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrInvalidToken), errors.Is(err, service.ErrInvalidMFACode):
+		status = http.StatusUnauthorized
+	case errors.Is(err, service.ErrMFANotEnrolled):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrMFAEnforced):
+		status = http.StatusForbidden
+	}
+	helper.SendJSON(w, status, domain.Response{Message: err.Error()})
+}
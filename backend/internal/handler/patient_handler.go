@@ -9,7 +9,10 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/JinXVIII/BE-Medical-Record/internal/auth"
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
 	"github.com/JinXVIII/BE-Medical-Record/internal/service"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -42,17 +45,9 @@ func (h *PatientHandler) CreateAppointment(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	startTimeNormalized := req.StartTimeSlot
-	if startTimeNormalized == "" {
-		http.Error(w, "start_time_slot wajib diisi (HH:MM)", http.StatusBadRequest)
-		return
-	}
-	if t, err := time.Parse("15:04", req.StartTimeSlot); err == nil {
-		startTimeNormalized = t.Format("15:04:05")
-	} else if tFull, errFull := time.Parse("15:04:05", req.StartTimeSlot); errFull == nil {
-		startTimeNormalized = tFull.Format("15:04:05")
-	} else {
-		http.Error(w, "start_time_slot harus memiliki format HH:MM", http.StatusBadRequest)
+	startTimeNormalized, err := normalizeTimeSlot(req.StartTimeSlot)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -76,6 +71,10 @@ func (h *PatientHandler) CreateAppointment(w http.ResponseWriter, r *http.Reques
 		switch err {
 		case service.ErrNotAllowed:
 			http.Error(w, err.Error(), http.StatusForbidden)
+		case service.ErrQuotaFull:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case service.ErrDoctorOffDay, service.ErrSlotOutsideSchedule:
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		default:
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
@@ -102,13 +101,28 @@ func (h *PatientHandler) GetAppointments(w http.ResponseWriter, r *http.Request)
 	_ = json.NewEncoder(w).Encode(data)
 }
 
+// GetAppointmentDetail is readable by the owning patient, the assigned doctor
+// and admins; anyone else gets 404.
 func (h *PatientHandler) GetAppointmentDetail(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-	id, _ := strconv.ParseInt(idStr, 10, 64)
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid appointment id", http.StatusBadRequest)
+		return
+	}
 
-	data, err := h.service.GetAppointmentDetail(r.Context(), id)
+	actor, err := actorFromContext(r)
 	if err != nil {
-		http.Error(w, "appointment not found", http.StatusNotFound)
+		handlePatientAuthError(w, err)
+		return
+	}
+
+	data, err := h.service.GetAppointmentDetail(r.Context(), actor, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "appointment not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -131,6 +145,8 @@ func (h *PatientHandler) CancelAppointment(w http.ResponseWriter, r *http.Reques
 			http.Error(w, err.Error(), http.StatusForbidden)
 		case service.ErrInvalidStatus:
 			http.Error(w, err.Error(), http.StatusBadRequest)
+		case service.ErrInvalidTransition:
+			http.Error(w, err.Error(), http.StatusConflict)
 		case sql.ErrNoRows:
 			http.Error(w, "appointment not found", http.StatusNotFound)
 		default:
@@ -142,32 +158,194 @@ func (h *PatientHandler) CancelAppointment(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusNoContent)
 }
 
-var (
-	errUnauthorized = errors.New("unauthorized")
-	errForbidden    = errors.New("forbidden")
-)
+// Reschedule moves one of the patient's appointments to another date and slot.
+func (h *PatientHandler) Reschedule(w http.ResponseWriter, r *http.Request) {
+	appointmentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid appointment id", http.StatusBadRequest)
+		return
+	}
+
+	var req domain.AppointmentRescheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	date, slot, err := parseRescheduleRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, err := getPatientUserID(r)
+	if err != nil {
+		handlePatientAuthError(w, err)
+		return
+	}
+
+	appointment, err := h.service.RescheduleByPatient(r.Context(), userID, appointmentID, date, slot, req.Reason)
+	if err != nil {
+		switch err {
+		case service.ErrNotAllowed:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case service.ErrQuotaFull, service.ErrNotReschedulable:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case service.ErrDoctorOffDay, service.ErrSlotOutsideSchedule, service.ErrSameSlot:
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		case sql.ErrNoRows:
+			http.Error(w, "appointment not found", http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(appointment)
+}
+
+func (h *PatientHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := getPatientUserID(r)
+	if err != nil {
+		handlePatientAuthError(w, err)
+		return
+	}
+
+	profile, err := h.service.GetProfile(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(profile)
+}
+
+// UpdateProfile replaces the date of birth, phone, address and blood type of
+// the logged in patient.
+func (h *PatientHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := getPatientUserID(r)
+	if err != nil {
+		handlePatientAuthError(w, err)
+		return
+	}
+
+	var req domain.PatientUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(validationErrors)
+		return
+	}
+
+	profile, err := h.service.UpdateProfile(r.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateOfBirth) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(profile)
+}
+
+// GetDoctorAvailability lists the bookable slots of a doctor.
+// Query params: from, to (YYYY-MM-DD, default today and 6 days later) and slot_minutes.
+func (h *PatientHandler) GetDoctorAvailability(w http.ResponseWriter, r *http.Request) {
+	doctorID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid doctor id", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	from := time.Now()
+	if v := query.Get("from"); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			http.Error(w, "invalid from format (YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+	}
+	to := from.AddDate(0, 0, 6)
+	if v := query.Get("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			http.Error(w, "invalid to format (YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+	}
+	slotMinutes := service.DefaultSlotMinutes
+	if v := query.Get("slot_minutes"); v != "" {
+		if slotMinutes, err = strconv.Atoi(v); err != nil || slotMinutes <= 0 {
+			http.Error(w, "slot_minutes harus berupa angka positif", http.StatusBadRequest)
+			return
+		}
+	}
+
+	slots, err := h.service.GetDoctorAvailability(r.Context(), doctorID, from, to, slotMinutes)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidAvailabilityRange:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(slots)
+}
+
+// normalizeTimeSlot accepts HH:MM or HH:MM:SS and returns HH:MM:SS.
+func normalizeTimeSlot(value string) (string, error) {
+	if value == "" {
+		return "", errors.New("start_time_slot wajib diisi (HH:MM)")
+	}
+	if t, err := time.Parse("15:04", value); err == nil {
+		return t.Format("15:04:05"), nil
+	}
+	if t, err := time.Parse("15:04:05", value); err == nil {
+		return t.Format("15:04:05"), nil
+	}
+	return "", errors.New("start_time_slot harus memiliki format HH:MM")
+}
 
+func parseRescheduleRequest(req domain.AppointmentRescheduleRequest) (time.Time, string, error) {
+	date, err := time.Parse("2006-01-02", req.AppointmentDate)
+	if err != nil {
+		return time.Time{}, "", errors.New("invalid appointment_date format (YYYY-MM-DD)")
+	}
+	slot, err := normalizeTimeSlot(req.StartTimeSlot)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	return date, slot, nil
+}
+
+var errUnauthorized = errors.New("unauthorized")
+
+// getPatientUserID returns the logged in user. The patient role is enforced
+// by auth.RequireRole on the route.
 func getPatientUserID(r *http.Request) (int64, error) {
-	userInfo, ok := r.Context().Value("user").(map[string]interface{})
+	p, ok := auth.FromContext(r.Context())
 	if !ok {
 		return 0, errUnauthorized
 	}
-	role, _ := userInfo["role"].(string)
-	if role != "patient" {
-		return 0, errForbidden
-	}
-	userIDFloat, ok := userInfo["user_id"].(float64)
+	return int64(p.UserID), nil
+}
+
+// actorFromContext returns the authenticated user whatever their role.
+func actorFromContext(r *http.Request) (domain.Actor, error) {
+	p, ok := auth.FromContext(r.Context())
 	if !ok {
-		return 0, errUnauthorized
+		return domain.Actor{}, errUnauthorized
 	}
-	return int64(userIDFloat), nil
+	return p.Actor(), nil
 }
 
 func handlePatientAuthError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, errForbidden):
-		http.Error(w, err.Error(), http.StatusForbidden)
-	default:
-		http.Error(w, err.Error(), http.StatusUnauthorized)
-	}
+	http.Error(w, err.Error(), http.StatusUnauthorized)
 }
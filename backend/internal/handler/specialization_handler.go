@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/service"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
+	"github.com/go-chi/chi/v5"
+)
+
+type SpecializationHandler interface {
+	GetAllSpecializations(w http.ResponseWriter, r *http.Request)
+	GetSpecializationByID(w http.ResponseWriter, r *http.Request)
+	CreateSpecialization(w http.ResponseWriter, r *http.Request)
+	UpdateSpecialization(w http.ResponseWriter, r *http.Request)
+	DeleteSpecialization(w http.ResponseWriter, r *http.Request)
+}
+
+type SpecializationHandlerImpl struct {
+	Service service.SpecializationService
+}
+
+func NewSpecializationHandler(service service.SpecializationService) SpecializationHandler {
+	return &SpecializationHandlerImpl{
+		Service: service,
+	}
+}
+
+// GetAllSpecializations is public so patients can fill the specialization
+// filter of the doctor search.
+func (h *SpecializationHandlerImpl) GetAllSpecializations(w http.ResponseWriter, r *http.Request) {
+	specializations, err := h.Service.GetAllSpecializations(r.Context())
+	if err != nil {
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{
+		Message: "Specializations retrieved successfully",
+		Data:    specializations,
+	})
+}
+
+func (h *SpecializationHandlerImpl) GetSpecializationByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Invalid specialization ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	specialization, err := h.Service.GetSpecializationByID(r.Context(), id)
+	if err != nil {
+		respondSpecializationError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{
+		Message: "Specialization retrieved successfully",
+		Data:    specialization,
+	})
+}
+
+func (h *SpecializationHandlerImpl) CreateSpecialization(w http.ResponseWriter, r *http.Request) {
+	var req domain.SpecializationRequest
+	if !parseSpecializationRequest(w, r, &req) {
+		return
+	}
+
+	specialization, err := h.Service.CreateSpecialization(r.Context(), req)
+	if err != nil {
+		respondSpecializationError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusCreated, domain.Response{
+		Message: "Specialization created successfully",
+		Data:    specialization,
+	})
+}
+
+func (h *SpecializationHandlerImpl) UpdateSpecialization(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Invalid specialization ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	var req domain.SpecializationRequest
+	if !parseSpecializationRequest(w, r, &req) {
+		return
+	}
+
+	specialization, err := h.Service.UpdateSpecialization(r.Context(), id, req)
+	if err != nil {
+		respondSpecializationError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{
+		Message: "Specialization updated successfully",
+		Data:    specialization,
+	})
+}
+
+func (h *SpecializationHandlerImpl) DeleteSpecialization(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Invalid specialization ID",
+			Data:    nil,
+		})
+		return
+	}
+
+	if err := h.Service.DeleteSpecialization(r.Context(), id); err != nil {
+		respondSpecializationError(w, err)
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{
+		Message: "Specialization deleted successfully",
+		Data:    nil,
+	})
+}
+
+func parseSpecializationRequest(w http.ResponseWriter, r *http.Request, req *domain.SpecializationRequest) bool {
+	if err := helper.ParseBody(r, req); err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Invalid request body: " + err.Error(),
+			Data:    nil,
+		})
+		return false
+	}
+
+	validationErrors := helper.ValidateStruct(*req)
+	if len(validationErrors) > 0 {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Validation failed",
+			Data:    validationErrors,
+		})
+		return false
+	}
+
+	return true
+}
+
+func respondSpecializationError(w http.ResponseWriter, err error) {
+	errorMessage := err.Error()
+
+	status := http.StatusInternalServerError
+	switch {
+	case strings.Contains(errorMessage, "not found"):
+		status = http.StatusNotFound
+	case strings.Contains(errorMessage, "already exists"), strings.Contains(errorMessage, "still used"):
+		status = http.StatusConflict
+	case strings.Contains(errorMessage, "invalid name"):
+		status = http.StatusBadRequest
+	}
+
+	helper.SendJSON(w, status, domain.Response{
+		Message: errorMessage,
+		Data:    nil,
+	})
+}
@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/JinXVIII/BE-Medical-Record/internal/auth"
 	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
 	"github.com/JinXVIII/BE-Medical-Record/internal/service"
 	"github.com/JinXVIII/BE-Medical-Record/pkg/helper"
@@ -11,7 +16,15 @@ import (
 
 type UserHandler interface {
 	Register(w http.ResponseWriter, r *http.Request)
+	CreateUser(w http.ResponseWriter, r *http.Request)
 	Login(w http.ResponseWriter, r *http.Request)
+	RefreshToken(w http.ResponseWriter, r *http.Request)
+	Logout(w http.ResponseWriter, r *http.Request)
+	LoginMFA(w http.ResponseWriter, r *http.Request)
+	LoginMFAEnroll(w http.ResponseWriter, r *http.Request)
+	EnrollMFA(w http.ResponseWriter, r *http.Request)
+	ConfirmMFA(w http.ResponseWriter, r *http.Request)
+	DisableMFA(w http.ResponseWriter, r *http.Request)
 }
 
 type UserHandlerImpl struct {
@@ -82,8 +95,10 @@ func (handler *UserHandlerImpl) Register(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-func (h *UserHandlerImpl) Login(w http.ResponseWriter, r *http.Request) {
-	var req domain.LoginRequest
+// CreateUser lets an admin create an admin or patient account. The response
+// carries the invite token the new user sets their password with.
+func (handler *UserHandlerImpl) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateUserRequest
 
 	// Parsing body request
 	if err := helper.ParseBody(r, &req); err != nil {
@@ -104,23 +119,26 @@ func (h *UserHandlerImpl) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.Service.Login(r.Context(), req)
+	created, err := handler.Service.CreateUser(r.Context(), req)
 	if err != nil {
-		// Error handling yang konsisten dengan register
 		errorMessage := err.Error()
 
-		// Jika error terkait credentials
-		if strings.Contains(errorMessage, "invalid credentials") ||
-			strings.Contains(errorMessage, "user not found") ||
-			strings.Contains(errorMessage, "password") {
-			helper.SendJSON(w, http.StatusUnauthorized, domain.Response{
+		if strings.Contains(errorMessage, "email sudah ada") {
+			helper.SendJSON(w, http.StatusConflict, domain.Response{
+				Message: errorMessage,
+				Data:    nil,
+			})
+			return
+		}
+
+		if strings.Contains(errorMessage, "invalid role") {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{
 				Message: errorMessage,
 				Data:    nil,
 			})
 			return
 		}
 
-		// Error lainnya
 		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{
 			Message: errorMessage,
 			Data:    nil,
@@ -128,8 +146,178 @@ func (h *UserHandlerImpl) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	helper.SendJSON(w, http.StatusCreated, domain.Response{
+		Message: "User berhasil dibuat",
+		Data:    created,
+	})
+}
+
+func (h *UserHandlerImpl) Login(w http.ResponseWriter, r *http.Request) {
+	var req domain.LoginRequest
+
+	// Parsing body request
+	if err := helper.ParseBody(r, &req); err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Invalid request body: " + err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	// Validation input
+	validationErrors := helper.ValidateStruct(req)
+	if len(validationErrors) > 0 {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Validation failed",
+			Data:    validationErrors,
+		})
+		return
+	}
+
+	resp, err := h.Service.Login(r.Context(), req, clientInfo(r))
+	if err != nil {
+		// Same message for unknown emails and wrong passwords
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			helper.SendJSON(w, http.StatusUnauthorized, domain.Response{
+				Message: err.Error(),
+				Data:    nil,
+			})
+			return
+		}
+
+		if respondLoginLocked(w, err) {
+			return
+		}
+
+		// Error lainnya
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	message := "Login successful"
+	if resp.MFARequired {
+		message = "MFA required"
+	}
 	helper.SendJSON(w, http.StatusOK, domain.Response{
-		Message: "Login successful",
+		Message: message,
 		Data:    resp,
 	})
 }
+
+// RefreshToken trades a refresh token for a new token pair. The old refresh
+// token stops working.
+func (h *UserHandlerImpl) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req domain.RefreshRequest
+
+	if err := helper.ParseBody(r, &req); err != nil {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Invalid request body: " + err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	validationErrors := helper.ValidateStruct(req)
+	if len(validationErrors) > 0 {
+		helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+			Message: "Validation failed",
+			Data:    validationErrors,
+		})
+		return
+	}
+
+	tokens, err := h.Service.RefreshToken(r.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			helper.SendJSON(w, http.StatusUnauthorized, domain.Response{
+				Message: err.Error(),
+				Data:    nil,
+			})
+			return
+		}
+
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{
+		Message: "Token refreshed",
+		Data:    tokens,
+	})
+}
+
+// Logout revokes the current session, or all sessions of the user with {"all": true}.
+func (h *UserHandlerImpl) Logout(w http.ResponseWriter, r *http.Request) {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		helper.SendJSON(w, http.StatusUnauthorized, domain.Response{
+			Message: "user not found in context",
+			Data:    nil,
+		})
+		return
+	}
+
+	// Body is optional, an empty one logs out the current session only
+	var req domain.LogoutRequest
+	if r.ContentLength != 0 {
+		if err := helper.ParseBody(r, &req); err != nil {
+			helper.SendJSON(w, http.StatusBadRequest, domain.Response{
+				Message: "Invalid request body: " + err.Error(),
+				Data:    nil,
+			})
+			return
+		}
+	}
+
+	if err := h.Service.Logout(r.Context(), principal.UserID, principal.TokenID, req.All); err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			helper.SendJSON(w, http.StatusUnauthorized, domain.Response{
+				Message: err.Error(),
+				Data:    nil,
+			})
+			return
+		}
+
+		helper.SendJSON(w, http.StatusInternalServerError, domain.Response{
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	helper.SendJSON(w, http.StatusOK, domain.Response{
+		Message: "Logged out",
+		Data:    nil,
+	})
+}
+
+// respondLoginLocked answers 429 with Retry-After when err is a lockout.
+func respondLoginLocked(w http.ResponseWriter, err error) bool {
+	var locked *service.LoginLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+	helper.SendJSON(w, http.StatusTooManyRequests, domain.Response{
+		Message: err.Error(),
+		Data:    nil,
+	})
+	return true
+}
+
+// clientInfo returns the caller's address and user agent. RemoteAddr is the
+// real client only when middleware.RealIP runs behind a trusted proxy.
+func clientInfo(r *http.Request) domain.ClientInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return domain.ClientInfo{IP: ip, UserAgent: r.UserAgent()}
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+type AppointmentChangeRepository interface {
+	CreateTx(ctx context.Context, tx *sql.Tx, c *domain.AppointmentChange) error
+	GetByAppointment(ctx context.Context, appointmentID int64) ([]domain.AppointmentChange, error)
+}
+
+type appointmentChangeRepoMySQL struct {
+	db *sql.DB
+}
+
+func NewAppointmentChangeRepository(db *sql.DB) AppointmentChangeRepository {
+	return &appointmentChangeRepoMySQL{db: db}
+}
+
+var _ AppointmentChangeRepository = (*appointmentChangeRepoMySQL)(nil)
+
+func (r *appointmentChangeRepoMySQL) CreateTx(ctx context.Context, tx *sql.Tx, c *domain.AppointmentChange) error {
+	const q = `
+		INSERT INTO appointment_changes
+			(appointment_id, old_appointment_date, old_start_time_slot,
+			 new_appointment_date, new_start_time_slot,
+			 actor_user_id, actor_role, reason, created_at)
+		VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?, NOW())
+	`
+
+	res, err := tx.ExecContext(
+		ctx,
+		q,
+		c.AppointmentID,
+		c.OldDate,
+		c.OldStartTimeSlot,
+		c.NewDate,
+		c.NewStartTimeSlot,
+		c.ActorUserID,
+		c.ActorRole,
+		c.Reason,
+	)
+	if err != nil {
+		return err
+	}
+
+	insertID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	c.ID = int(insertID)
+	c.CreatedAt = time.Now()
+	return nil
+}
+
+// GetByAppointment returns the reschedule history of an appointment, oldest first.
+func (r *appointmentChangeRepoMySQL) GetByAppointment(ctx context.Context, appointmentID int64) ([]domain.AppointmentChange, error) {
+	const q = `
+		SELECT id, appointment_id, old_appointment_date, old_start_time_slot,
+		       new_appointment_date, new_start_time_slot,
+		       actor_user_id, actor_role, reason, created_at
+		FROM appointment_changes
+		WHERE appointment_id = ?
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, q, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.AppointmentChange
+	for rows.Next() {
+		var (
+			c           domain.AppointmentChange
+			oldSlot     sql.NullString
+			newSlot     sql.NullString
+			actorUserID sql.NullInt64
+			reason      sql.NullString
+		)
+		if err := rows.Scan(
+			&c.ID,
+			&c.AppointmentID,
+			&c.OldDate,
+			&oldSlot,
+			&c.NewDate,
+			&newSlot,
+			&actorUserID,
+			&c.ActorRole,
+			&reason,
+			&c.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		c.OldStartTimeSlot = oldSlot.String
+		c.NewStartTimeSlot = newSlot.String
+		c.ActorUserID = int(actorUserID.Int64)
+		c.Reason = reason.String
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
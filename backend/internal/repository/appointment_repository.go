@@ -12,9 +12,14 @@ import (
 type AppointmentRepository interface {
 	CreateTx(ctx context.Context, tx *sql.Tx, a *domain.Appointment) error
 	GetByID(ctx context.Context, id int64) (*domain.Appointment, error)
+	GetByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (*domain.Appointment, error)
 	UpdateStatusTx(ctx context.Context, tx *sql.Tx, id int64, status domain.AppointmentStatus) error
+	RescheduleTx(ctx context.Context, tx *sql.Tx, a *domain.Appointment) error
+	CountActiveByScheduleTx(ctx context.Context, tx *sql.Tx, scheduleID int64, date time.Time) (int, error)
+	CountActiveByDoctor(ctx context.Context, doctorID int64, from, to time.Time) ([]domain.ScheduleBooking, error)
 	GetByPatient(ctx context.Context, patientID int64) ([]domain.Appointment, error)
 	GetByDoctor(ctx context.Context, doctorID int64) ([]domain.Appointment, error)
+	ExistsForDoctorAndPatient(ctx context.Context, doctorID, patientID int64) (bool, error)
 }
 
 type appointmentRepoMySQL struct {
@@ -149,6 +154,53 @@ func (r *appointmentRepoMySQL) GetByID(ctx context.Context, id int64) (*domain.A
 	return &a, nil
 }
 
+// GetByIDForUpdateTx locks the appointment row until tx finishes so concurrent
+// status changes on the same appointment are serialized.
+func (r *appointmentRepoMySQL) GetByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (*domain.Appointment, error) {
+	const q = `
+		SELECT id, patient_id, doctor_id, schedule_id,
+		       appointment_date, start_time_slot, complaint,
+		       status, created_at, updated_at
+		FROM appointments
+		WHERE id = ?
+		FOR UPDATE
+	`
+
+	var (
+		a          domain.Appointment
+		scheduleID sql.NullInt64
+		startTime  sql.NullString
+		complaint  sql.NullString
+	)
+
+	if err := tx.QueryRowContext(ctx, q, id).Scan(
+		&a.ID,
+		&a.PatientID,
+		&a.DoctorID,
+		&scheduleID,
+		&a.AppointmentDate,
+		&startTime,
+		&complaint,
+		&a.Status,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	if scheduleID.Valid {
+		v := int(scheduleID.Int64)
+		a.ScheduleID = &v
+	}
+	a.StartTimeSlot = startTime.String
+	a.Complaint = complaint.String
+
+	return &a, nil
+}
+
 func (r *appointmentRepoMySQL) UpdateStatusTx(
 	ctx context.Context,
 	tx *sql.Tx,
@@ -176,6 +228,108 @@ func (r *appointmentRepoMySQL) UpdateStatusTx(
 	return nil
 }
 
+// RescheduleTx moves the appointment to a.ScheduleID, a.AppointmentDate and
+// a.StartTimeSlot. The status is left untouched.
+func (r *appointmentRepoMySQL) RescheduleTx(ctx context.Context, tx *sql.Tx, a *domain.Appointment) error {
+	const q = `
+		UPDATE appointments
+		SET schedule_id = ?, appointment_date = ?, start_time_slot = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+
+	var scheduleID interface{}
+	if a.ScheduleID != nil {
+		scheduleID = *a.ScheduleID
+	}
+	res, err := tx.ExecContext(ctx, q, scheduleID, a.AppointmentDate, a.StartTimeSlot, a.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// CountActiveByScheduleTx counts the appointments that still hold a place in
+// the schedule's patient quota on the given date.
+func (r *appointmentRepoMySQL) CountActiveByScheduleTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	scheduleID int64,
+	date time.Time,
+) (int, error) {
+	const q = `
+		SELECT COUNT(*)
+		FROM appointments
+		WHERE schedule_id = ?
+		  AND appointment_date = ?
+		  AND status NOT IN (?, ?)
+	`
+
+	var count int
+	if err := tx.QueryRowContext(
+		ctx,
+		q,
+		scheduleID,
+		date.Format("2006-01-02"),
+		domain.AppointmentStatusRejected,
+		domain.AppointmentStatusCancelled,
+	).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountActiveByDoctor groups the doctor's quota-holding appointments between
+// from and to (inclusive) by schedule and date.
+func (r *appointmentRepoMySQL) CountActiveByDoctor(
+	ctx context.Context,
+	doctorID int64,
+	from, to time.Time,
+) ([]domain.ScheduleBooking, error) {
+	const q = `
+		SELECT schedule_id, appointment_date, COUNT(*)
+		FROM appointments
+		WHERE doctor_id = ?
+		  AND schedule_id IS NOT NULL
+		  AND appointment_date BETWEEN ? AND ?
+		  AND status NOT IN (?, ?)
+		GROUP BY schedule_id, appointment_date
+	`
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		q,
+		doctorID,
+		from.Format("2006-01-02"),
+		to.Format("2006-01-02"),
+		domain.AppointmentStatusRejected,
+		domain.AppointmentStatusCancelled,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.ScheduleBooking
+	for rows.Next() {
+		var b domain.ScheduleBooking
+		if err := rows.Scan(&b.ScheduleID, &b.Date, &b.Count); err != nil {
+			return nil, err
+		}
+		result = append(result, b)
+	}
+	return result, rows.Err()
+}
+
 func (r *appointmentRepoMySQL) GetByPatient(ctx context.Context, patientID int64) ([]domain.Appointment, error) {
 	const q = `
 		SELECT a.id, a.patient_id, a.doctor_id, a.schedule_id,
@@ -314,3 +468,19 @@ func (r *appointmentRepoMySQL) GetByDoctor(ctx context.Context, doctorID int64)
 
 	return result, nil
 }
+
+// ExistsForDoctorAndPatient reports whether the patient ever booked the doctor.
+func (r *appointmentRepoMySQL) ExistsForDoctorAndPatient(ctx context.Context, doctorID, patientID int64) (bool, error) {
+	const q = `
+		SELECT EXISTS(
+			SELECT 1 FROM appointments
+			WHERE doctor_id = ? AND patient_id = ?
+		)
+	`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, q, doctorID, patientID).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, e *domain.AuditEvent) error
+	// CreateBatch stores events with as few statements as possible. It does
+	// not fill in their IDs.
+	CreateBatch(ctx context.Context, events []domain.AuditEvent) error
+	// List returns at most f.Limit events, newest first.
+	List(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEvent, error)
+	// Each calls fn for every matching event, oldest first, without loading
+	// them all into memory. It stops at the first error fn returns.
+	Each(ctx context.Context, f domain.AuditFilter, fn func(domain.AuditEvent) error) error
+}
+
+type auditRepoMySQL struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepoMySQL{db: db}
+}
+
+var _ AuditRepository = (*auditRepoMySQL)(nil)
+
+const auditEventColumns = `
+	id, actor_user_id, actor_role, action, outcome, resource_type,
+	resource_id, patient_id, ip, request_id, created_at
+`
+
+func (r *auditRepoMySQL) Create(ctx context.Context, e *domain.AuditEvent) error {
+	const q = `
+		INSERT INTO audit_events
+			(actor_user_id, actor_role, action, outcome, resource_type,
+			 resource_id, patient_id, ip, request_id, created_at)
+		VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	res, err := r.db.ExecContext(ctx, q,
+		e.ActorUserID, e.ActorRole, e.Action, e.Outcome, e.ResourceType,
+		e.ResourceID, e.PatientID, e.IP, e.RequestID, e.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	insertID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	e.ID = insertID
+	return nil
+}
+
+// auditBatchSize keeps a batch insert well below the placeholder limit of
+// the drivers.
+const auditBatchSize = 500
+
+func (r *auditRepoMySQL) CreateBatch(ctx context.Context, events []domain.AuditEvent) error {
+	for len(events) > 0 {
+		n := min(len(events), auditBatchSize)
+		if err := r.insertBatch(ctx, events[:n]); err != nil {
+			return err
+		}
+		events = events[n:]
+	}
+	return nil
+}
+
+func (r *auditRepoMySQL) insertBatch(ctx context.Context, events []domain.AuditEvent) error {
+	var q strings.Builder
+	q.WriteString(`
+		INSERT INTO audit_events
+			(actor_user_id, actor_role, action, outcome, resource_type,
+			 resource_id, patient_id, ip, request_id, created_at)
+		VALUES
+	`)
+	args := make([]any, 0, len(events)*10)
+	for i, e := range events {
+		if i > 0 {
+			q.WriteString(",")
+		}
+		q.WriteString("\n\t\t\t(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			e.ActorUserID, e.ActorRole, e.Action, e.Outcome, e.ResourceType,
+			e.ResourceID, e.PatientID, e.IP, e.RequestID, e.CreatedAt,
+		)
+	}
+
+	_, err := r.db.ExecContext(ctx, q.String(), args...)
+	return err
+}
+
+func (r *auditRepoMySQL) List(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEvent, error) {
+	where, args := auditWhere(f)
+	q := `SELECT ` + auditEventColumns + ` FROM audit_events` + where + ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, f.Limit)
+
+	events := []domain.AuditEvent{}
+	err := r.query(ctx, q, args, func(e domain.AuditEvent) error {
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *auditRepoMySQL) Each(ctx context.Context, f domain.AuditFilter, fn func(domain.AuditEvent) error) error {
+	where, args := auditWhere(f)
+	q := `SELECT ` + auditEventColumns + ` FROM audit_events` + where + ` ORDER BY created_at, id`
+	return r.query(ctx, q, args, fn)
+}
+
+func (r *auditRepoMySQL) query(ctx context.Context, q string, args []any, fn func(domain.AuditEvent) error) error {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			e         domain.AuditEvent
+			actorID   sql.NullInt64
+			patientID sql.NullInt64
+		)
+		if err := rows.Scan(
+			&e.ID,
+			&actorID,
+			&e.ActorRole,
+			&e.Action,
+			&e.Outcome,
+			&e.ResourceType,
+			&e.ResourceID,
+			&patientID,
+			&e.IP,
+			&e.RequestID,
+			&e.CreatedAt,
+		); err != nil {
+			return err
+		}
+		if actorID.Valid {
+			e.ActorUserID = &actorID.Int64
+		}
+		if patientID.Valid {
+			e.PatientID = &patientID.Int64
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func auditWhere(f domain.AuditFilter) (string, []any) {
+	var (
+		where []string
+		args  []any
+	)
+	if f.ActorUserID != 0 {
+		where = append(where, "actor_user_id = ?")
+		args = append(args, f.ActorUserID)
+	}
+	if f.PatientID != 0 {
+		where = append(where, "patient_id = ?")
+		args = append(args, f.PatientID)
+	}
+	if f.Action != "" {
+		where = append(where, "action = ?")
+		args = append(args, f.Action)
+	}
+	if f.ResourceType != "" {
+		where = append(where, "resource_type = ?")
+		args = append(args, f.ResourceType)
+	}
+	if f.ResourceID != "" {
+		where = append(where, "resource_id = ?")
+		args = append(args, f.ResourceID)
+	}
+	if !f.From.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, f.From)
+	}
+	if !f.To.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, f.To)
+	}
+
+	if len(where) == 0 {
+		return "", nil
+	}
+	return ` WHERE ` + strings.Join(where, " AND "), args
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+type LoginEventRepository interface {
+	Create(ctx context.Context, e *domain.LoginEvent) error
+	// FailuresForEmail counts failed attempts for email after since and after
+	// the last successful login, and returns when the latest one happened.
+	// Attempts rejected because of a lockout do not count.
+	FailuresForEmail(ctx context.Context, email string, since time.Time) (int, time.Time, error)
+	// FailuresForIP counts failed attempts from ip after since.
+	FailuresForIP(ctx context.Context, ip string, since time.Time) (int, time.Time, error)
+	List(ctx context.Context, f domain.LoginEventFilter) ([]domain.LoginEvent, error)
+}
+
+type loginEventRepoMySQL struct {
+	db *sql.DB
+}
+
+func NewLoginEventRepository(db *sql.DB) LoginEventRepository {
+	return &loginEventRepoMySQL{db: db}
+}
+
+var _ LoginEventRepository = (*loginEventRepoMySQL)(nil)
+
+func (r *loginEventRepoMySQL) Create(ctx context.Context, e *domain.LoginEvent) error {
+	const q = `
+		INSERT INTO login_events (user_id, email, ip, user_agent, success, reason, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	res, err := r.db.ExecContext(ctx, q, e.UserID, e.Email, e.IP, e.UserAgent, e.Success, e.Reason, e.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	insertID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	e.ID = int(insertID)
+	return nil
+}
+
+func (r *loginEventRepoMySQL) FailuresForEmail(ctx context.Context, email string, since time.Time) (int, time.Time, error) {
+	const q = `
+		SELECT COUNT(*), MAX(created_at)
+		FROM login_events
+		WHERE email = ? AND success = FALSE AND reason <> ? AND created_at > ?
+		  AND created_at > COALESCE(
+			(SELECT MAX(created_at) FROM login_events WHERE email = ? AND success = TRUE),
+			'1970-01-01 00:00:01'
+		  )
+	`
+	return r.failures(ctx, q, email, domain.LoginReasonLocked, since, email)
+}
+
+func (r *loginEventRepoMySQL) FailuresForIP(ctx context.Context, ip string, since time.Time) (int, time.Time, error) {
+	const q = `
+		SELECT COUNT(*), MAX(created_at)
+		FROM login_events
+		WHERE ip = ? AND success = FALSE AND reason <> ? AND created_at > ?
+	`
+	return r.failures(ctx, q, ip, domain.LoginReasonLocked, since)
+}
+
+func (r *loginEventRepoMySQL) failures(ctx context.Context, q string, args ...any) (int, time.Time, error) {
+	var (
+		count int
+		last  sql.NullTime
+	)
+	if err := r.db.QueryRowContext(ctx, q, args...).Scan(&count, &last); err != nil {
+		return 0, time.Time{}, err
+	}
+	return count, last.Time, nil
+}
+
+// List returns the newest events first.
+func (r *loginEventRepoMySQL) List(ctx context.Context, f domain.LoginEventFilter) ([]domain.LoginEvent, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if f.Email != "" {
+		where = append(where, "email = ?")
+		args = append(args, f.Email)
+	}
+	if f.IP != "" {
+		where = append(where, "ip = ?")
+		args = append(args, f.IP)
+	}
+	if f.UserID != 0 {
+		where = append(where, "user_id = ?")
+		args = append(args, f.UserID)
+	}
+	if f.Success != nil {
+		where = append(where, "success = ?")
+		args = append(args, *f.Success)
+	}
+	if !f.From.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, f.From)
+	}
+	if !f.To.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, f.To)
+	}
+
+	q := `SELECT id, user_id, email, ip, user_agent, success, reason, created_at FROM login_events`
+	if len(where) > 0 {
+		q += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	q += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, f.Limit)
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.LoginEvent{}
+	for rows.Next() {
+		var (
+			e      domain.LoginEvent
+			userID sql.NullInt64
+		)
+		if err := rows.Scan(&e.ID, &userID, &e.Email, &e.IP, &e.UserAgent, &e.Success, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if userID.Valid {
+			id := int(userID.Int64)
+			e.UserID = &id
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+type MedicalRecordRepository interface {
+	CreateTx(ctx context.Context, tx *sql.Tx, m *domain.MedicalRecord) error
+	UpdateTx(ctx context.Context, tx *sql.Tx, m *domain.MedicalRecord) error
+	GetByAppointmentID(ctx context.Context, appointmentID int64) (*domain.MedicalRecord, error)
+	GetByPatient(ctx context.Context, patientID int64, filter domain.MedicalRecordFilter) ([]domain.MedicalRecord, error)
+}
+
+type medicalRecordRepoMySQL struct {
+	db *sql.DB
+}
+
+func NewMedicalRecordRepository(db *sql.DB) MedicalRecordRepository {
+	return &medicalRecordRepoMySQL{db: db}
+}
+
+var _ MedicalRecordRepository = (*medicalRecordRepoMySQL)(nil)
+
+func (r *medicalRecordRepoMySQL) CreateTx(ctx context.Context, tx *sql.Tx, m *domain.MedicalRecord) error {
+	const q = `
+		INSERT INTO medical_records
+			(appointment_id, diagnosis, prescription, doctor_notes, examination_date, created_at, updated_at)
+		VALUES
+			(?, ?, ?, ?, ?, NOW(), NOW())
+	`
+
+	res, err := tx.ExecContext(
+		ctx,
+		q,
+		m.AppointmentID,
+		m.Diagnosis,
+		m.Prescription,
+		m.DoctorNotes,
+		m.ExaminationDate,
+	)
+	if err != nil {
+		return err
+	}
+
+	insertID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	m.ID = int(insertID)
+	m.CreatedAt = now
+	m.UpdatedAt = now
+
+	return nil
+}
+
+func (r *medicalRecordRepoMySQL) UpdateTx(ctx context.Context, tx *sql.Tx, m *domain.MedicalRecord) error {
+	const q = `
+		UPDATE medical_records
+		SET diagnosis = ?, prescription = ?, doctor_notes = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+
+	res, err := tx.ExecContext(ctx, q, m.Diagnosis, m.Prescription, m.DoctorNotes, m.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	m.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *medicalRecordRepoMySQL) GetByAppointmentID(ctx context.Context, appointmentID int64) (*domain.MedicalRecord, error) {
+	const q = `
+		SELECT id, appointment_id, diagnosis, prescription, doctor_notes,
+		       examination_date, created_at, updated_at
+		FROM medical_records
+		WHERE appointment_id = ?
+	`
+
+	var (
+		m            domain.MedicalRecord
+		diagnosis    sql.NullString
+		prescription sql.NullString
+		doctorNotes  sql.NullString
+	)
+
+	if err := r.db.QueryRowContext(ctx, q, appointmentID).Scan(
+		&m.ID,
+		&m.AppointmentID,
+		&diagnosis,
+		&prescription,
+		&doctorNotes,
+		&m.ExaminationDate,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	m.Diagnosis = diagnosis.String
+	m.Prescription = prescription.String
+	m.DoctorNotes = doctorNotes.String
+
+	return &m, nil
+}
+
+func (r *medicalRecordRepoMySQL) GetByPatient(
+	ctx context.Context,
+	patientID int64,
+	filter domain.MedicalRecordFilter,
+) ([]domain.MedicalRecord, error) {
+	q := `
+		SELECT m.id, m.appointment_id, m.diagnosis, m.prescription, m.doctor_notes,
+		       m.examination_date, m.created_at, m.updated_at,
+		       a.patient_id, a.doctor_id, a.schedule_id,
+		       a.appointment_date, a.start_time_slot, a.complaint,
+		       a.status, a.created_at, a.updated_at,
+		       du.name, du.email,
+		       d.specialization_id, s.name
+		FROM medical_records m
+		JOIN appointments a ON a.id = m.appointment_id
+		LEFT JOIN doctors d ON a.doctor_id = d.id
+		LEFT JOIN users du ON d.user_id = du.id
+		LEFT JOIN specializations s ON d.specialization_id = s.id
+		WHERE a.patient_id = ?
+	`
+	args := []any{patientID}
+
+	if filter.From != nil {
+		q += " AND a.appointment_date >= ?"
+		args = append(args, filter.From.Format("2006-01-02"))
+	}
+	if filter.To != nil {
+		q += " AND a.appointment_date <= ?"
+		args = append(args, filter.To.Format("2006-01-02"))
+	}
+	if filter.DoctorID != 0 {
+		q += " AND a.doctor_id = ?"
+		args = append(args, filter.DoctorID)
+	}
+	q += " ORDER BY a.appointment_date ASC, a.start_time_slot ASC"
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.MedicalRecord
+	for rows.Next() {
+		var (
+			m                  domain.MedicalRecord
+			a                  domain.Appointment
+			diagnosis          sql.NullString
+			prescription       sql.NullString
+			doctorNotes        sql.NullString
+			scheduleID         sql.NullInt64
+			startTime          sql.NullString
+			complaint          sql.NullString
+			doctorName         sql.NullString
+			doctorEmail        sql.NullString
+			specializationID   sql.NullInt64
+			specializationName sql.NullString
+		)
+		if err := rows.Scan(
+			&m.ID,
+			&m.AppointmentID,
+			&diagnosis,
+			&prescription,
+			&doctorNotes,
+			&m.ExaminationDate,
+			&m.CreatedAt,
+			&m.UpdatedAt,
+			&a.PatientID,
+			&a.DoctorID,
+			&scheduleID,
+			&a.AppointmentDate,
+			&startTime,
+			&complaint,
+			&a.Status,
+			&a.CreatedAt,
+			&a.UpdatedAt,
+			&doctorName,
+			&doctorEmail,
+			&specializationID,
+			&specializationName,
+		); err != nil {
+			return nil, err
+		}
+
+		m.Diagnosis = diagnosis.String
+		m.Prescription = prescription.String
+		m.DoctorNotes = doctorNotes.String
+
+		a.ID = m.AppointmentID
+		if scheduleID.Valid {
+			v := int(scheduleID.Int64)
+			a.ScheduleID = &v
+		}
+		a.StartTimeSlot = startTime.String
+		a.Complaint = complaint.String
+		if doctorName.Valid || doctorEmail.Valid {
+			a.Doctor = &domain.Doctor{
+				ID: a.DoctorID,
+				User: &domain.User{
+					Name:  doctorName.String,
+					Email: doctorEmail.String,
+				},
+			}
+			if specializationID.Valid {
+				a.Doctor.SpecializationID = int(specializationID.Int64)
+				a.Doctor.Specialization = &domain.Specialization{
+					ID:   int(specializationID.Int64),
+					Name: specializationName.String,
+				}
+			}
+		}
+
+		m.Appointment = &a
+		result = append(result, m)
+	}
+
+	return result, rows.Err()
+}
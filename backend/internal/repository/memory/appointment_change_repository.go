@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type appointmentChangeRepo struct {
+	store *Store
+}
+
+func NewAppointmentChangeRepository(s *Store) repository.AppointmentChangeRepository {
+	return &appointmentChangeRepo{store: s}
+}
+
+var _ repository.AppointmentChangeRepository = (*appointmentChangeRepo)(nil)
+
+func (r *appointmentChangeRepo) CreateTx(ctx context.Context, tx *sql.Tx, c *domain.AppointmentChange) error {
+	return r.store.writeTx(func(t *tables) error {
+		if _, ok := t.appointments[c.AppointmentID]; !ok {
+			return sql.ErrNoRows
+		}
+		c.ID = t.nextID("appointment_changes")
+		c.CreatedAt = time.Now()
+
+		row := *c
+		row.OldDate = dateOnly(row.OldDate)
+		row.NewDate = dateOnly(row.NewDate)
+		row.OldStartTimeSlot = timeOfDay(row.OldStartTimeSlot)
+		row.NewStartTimeSlot = timeOfDay(row.NewStartTimeSlot)
+		t.appointmentChanges[c.ID] = row
+		return nil
+	})
+}
+
+// GetByAppointment returns the reschedule history of an appointment, oldest
+// first.
+func (r *appointmentChangeRepo) GetByAppointment(ctx context.Context, appointmentID int64) ([]domain.AppointmentChange, error) {
+	var result []domain.AppointmentChange
+	r.store.read(func(t *tables) {
+		for _, c := range sortedByID(t.appointmentChanges) {
+			if c.AppointmentID == int(appointmentID) {
+				result = append(result, c)
+			}
+		}
+	})
+	return result, nil
+}
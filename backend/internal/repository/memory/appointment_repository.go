@@ -0,0 +1,262 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type appointmentRepo struct {
+	store *Store
+}
+
+func NewAppointmentRepository(s *Store) repository.AppointmentRepository {
+	return &appointmentRepo{store: s}
+}
+
+var _ repository.AppointmentRepository = (*appointmentRepo)(nil)
+
+func (r *appointmentRepo) CreateTx(ctx context.Context, tx *sql.Tx, a *domain.Appointment) error {
+	return r.store.writeTx(func(t *tables) error {
+		now := time.Now()
+		a.ID = t.nextID("appointments")
+		a.CreatedAt = now
+		a.UpdatedAt = now
+		t.appointments[a.ID] = appointmentRow(*a)
+		return nil
+	})
+}
+
+func (r *appointmentRepo) GetByID(ctx context.Context, id int64) (*domain.Appointment, error) {
+	var (
+		a  domain.Appointment
+		ok bool
+	)
+	r.store.read(func(t *tables) {
+		a, ok = t.appointments[int(id)]
+		a.Doctor = t.appointmentDoctor(a)
+		a.Patient = t.appointmentPatient(a)
+	})
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &a, nil
+}
+
+func (r *appointmentRepo) GetByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (*domain.Appointment, error) {
+	var (
+		a  domain.Appointment
+		ok bool
+	)
+	r.store.read(func(t *tables) {
+		a, ok = t.appointments[int(id)]
+	})
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &a, nil
+}
+
+func (r *appointmentRepo) UpdateStatusTx(ctx context.Context, tx *sql.Tx, id int64, status domain.AppointmentStatus) error {
+	return r.store.writeTx(func(t *tables) error {
+		a, ok := t.appointments[int(id)]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		a.Status = status
+		a.UpdatedAt = time.Now()
+		t.appointments[a.ID] = a
+		return nil
+	})
+}
+
+// RescheduleTx moves the appointment to a.ScheduleID, a.AppointmentDate and
+// a.StartTimeSlot. The status is left untouched.
+func (r *appointmentRepo) RescheduleTx(ctx context.Context, tx *sql.Tx, a *domain.Appointment) error {
+	return r.store.writeTx(func(t *tables) error {
+		current, ok := t.appointments[a.ID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		moved := appointmentRow(*a)
+		current.ScheduleID = moved.ScheduleID
+		current.AppointmentDate = moved.AppointmentDate
+		current.StartTimeSlot = moved.StartTimeSlot
+		current.UpdatedAt = time.Now()
+		t.appointments[a.ID] = current
+
+		a.UpdatedAt = current.UpdatedAt
+		return nil
+	})
+}
+
+// CountActiveByScheduleTx counts the appointments that still hold a place in
+// the schedule's patient quota on the given date.
+func (r *appointmentRepo) CountActiveByScheduleTx(ctx context.Context, tx *sql.Tx, scheduleID int64, date time.Time) (int, error) {
+	day := dateOnly(date)
+	count := 0
+	r.store.read(func(t *tables) {
+		for _, a := range t.appointments {
+			if a.ScheduleID != nil && *a.ScheduleID == int(scheduleID) &&
+				a.AppointmentDate.Equal(day) && holdsQuota(a.Status) {
+				count++
+			}
+		}
+	})
+	return count, nil
+}
+
+// CountActiveByDoctor groups the doctor's quota-holding appointments between
+// from and to (inclusive) by schedule and date.
+func (r *appointmentRepo) CountActiveByDoctor(ctx context.Context, doctorID int64, from, to time.Time) ([]domain.ScheduleBooking, error) {
+	type key struct {
+		scheduleID int
+		date       time.Time
+	}
+	from, to = dateOnly(from), dateOnly(to)
+
+	counts := map[key]int{}
+	r.store.read(func(t *tables) {
+		for _, a := range t.appointments {
+			if a.DoctorID != int(doctorID) || a.ScheduleID == nil || !holdsQuota(a.Status) ||
+				a.AppointmentDate.Before(from) || a.AppointmentDate.After(to) {
+				continue
+			}
+			counts[key{*a.ScheduleID, a.AppointmentDate}]++
+		}
+	})
+
+	var result []domain.ScheduleBooking
+	for k, count := range counts {
+		result = append(result, domain.ScheduleBooking{ScheduleID: k.scheduleID, Date: k.date, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Date.Equal(result[j].Date) {
+			return result[i].Date.Before(result[j].Date)
+		}
+		return result[i].ScheduleID < result[j].ScheduleID
+	})
+	return result, nil
+}
+
+// GetByPatient returns the latest appointments first, with the doctor's name.
+func (r *appointmentRepo) GetByPatient(ctx context.Context, patientID int64) ([]domain.Appointment, error) {
+	var result []domain.Appointment
+	r.store.read(func(t *tables) {
+		for _, a := range sortedByID(t.appointments) {
+			if a.PatientID == int(patientID) {
+				a.Doctor = t.appointmentDoctor(a)
+				result = append(result, a)
+			}
+		}
+	})
+	sort.SliceStable(result, func(i, j int) bool { return appointmentBefore(result[j], result[i]) })
+	return result, nil
+}
+
+// GetByDoctor returns the earliest appointments first, with the patient's
+// name.
+func (r *appointmentRepo) GetByDoctor(ctx context.Context, doctorID int64) ([]domain.Appointment, error) {
+	var result []domain.Appointment
+	r.store.read(func(t *tables) {
+		for _, a := range sortedByID(t.appointments) {
+			if a.DoctorID == int(doctorID) {
+				a.Patient = t.appointmentPatient(a)
+				result = append(result, a)
+			}
+		}
+	})
+	sort.SliceStable(result, func(i, j int) bool { return appointmentBefore(result[i], result[j]) })
+	return result, nil
+}
+
+// ExistsForDoctorAndPatient reports whether the patient ever booked the doctor.
+func (r *appointmentRepo) ExistsForDoctorAndPatient(ctx context.Context, doctorID, patientID int64) (bool, error) {
+	exists := false
+	r.store.read(func(t *tables) {
+		for _, a := range t.appointments {
+			if a.DoctorID == int(doctorID) && a.PatientID == int(patientID) {
+				exists = true
+				return
+			}
+		}
+	})
+	return exists, nil
+}
+
+// appointmentRow is a as stored: a DATE, a TIME and no joined rows.
+func appointmentRow(a domain.Appointment) domain.Appointment {
+	if a.ScheduleID != nil {
+		id := *a.ScheduleID
+		a.ScheduleID = &id
+	}
+	a.AppointmentDate = dateOnly(a.AppointmentDate)
+	a.StartTimeSlot = timeOfDay(a.StartTimeSlot)
+	a.Patient = nil
+	a.Doctor = nil
+	a.Schedule = nil
+	a.Changes = nil
+	return a
+}
+
+func (t *tables) appointmentDoctor(a domain.Appointment) *domain.Doctor {
+	d, ok := t.doctors[a.DoctorID]
+	if !ok {
+		return nil
+	}
+	u, ok := t.users[d.UserID]
+	if !ok {
+		return nil
+	}
+	return &domain.Doctor{ID: a.DoctorID, User: &domain.User{Name: u.Name, Email: u.Email}}
+}
+
+func (t *tables) appointmentPatient(a domain.Appointment) *domain.User {
+	p, ok := t.patients[a.PatientID]
+	if !ok {
+		return nil
+	}
+	u, ok := t.users[p.UserID]
+	if !ok {
+		return nil
+	}
+	return &domain.User{Name: u.Name, Email: u.Email}
+}
+
+// deleteAppointment removes the appointment and, through ON DELETE CASCADE,
+// its medical record and reschedule history.
+func (t *tables) deleteAppointment(id int) {
+	delete(t.appointments, id)
+	for recordID, m := range t.medicalRecords {
+		if m.AppointmentID == id {
+			delete(t.medicalRecords, recordID)
+		}
+	}
+	for changeID, c := range t.appointmentChanges {
+		if c.AppointmentID == id {
+			delete(t.appointmentChanges, changeID)
+		}
+	}
+}
+
+// holdsQuota reports whether an appointment in status counts against the
+// schedule's patient quota.
+func holdsQuota(status domain.AppointmentStatus) bool {
+	return status != domain.AppointmentStatusRejected && status != domain.AppointmentStatusCancelled
+}
+
+func appointmentBefore(a, b domain.Appointment) bool {
+	if !a.AppointmentDate.Equal(b.AppointmentDate) {
+		return a.AppointmentDate.Before(b.AppointmentDate)
+	}
+	return a.StartTimeSlot < b.StartTimeSlot
+}
+
+// dateOnly keeps the calendar date of t, as a DATE column does.
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type auditRepo struct {
+	store *Store
+}
+
+func NewAuditRepository(s *Store) repository.AuditRepository {
+	return &auditRepo{store: s}
+}
+
+var _ repository.AuditRepository = (*auditRepo)(nil)
+
+func (r *auditRepo) Create(ctx context.Context, e *domain.AuditEvent) error {
+	return r.store.write(ctx, func(t *tables) error {
+		id := t.nextID("audit_events")
+		e.ID = int64(id)
+		t.auditEvents[id] = *e
+		return nil
+	})
+}
+
+func (r *auditRepo) CreateBatch(ctx context.Context, events []domain.AuditEvent) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, e := range events {
+			id := t.nextID("audit_events")
+			e.ID = int64(id)
+			t.auditEvents[id] = e
+		}
+		return nil
+	})
+}
+
+func (r *auditRepo) List(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEvent, error) {
+	events := r.matching(f)
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	if limit := max(f.Limit, 0); len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (r *auditRepo) Each(ctx context.Context, f domain.AuditFilter, fn func(domain.AuditEvent) error) error {
+	for _, e := range r.matching(f) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matching returns the events f selects, oldest first.
+func (r *auditRepo) matching(f domain.AuditFilter) []domain.AuditEvent {
+	events := []domain.AuditEvent{}
+	r.store.read(func(t *tables) {
+		for _, e := range t.auditEvents {
+			switch {
+			case f.ActorUserID != 0 && (e.ActorUserID == nil || *e.ActorUserID != f.ActorUserID),
+				f.PatientID != 0 && (e.PatientID == nil || *e.PatientID != f.PatientID),
+				f.Action != "" && e.Action != f.Action,
+				f.ResourceType != "" && e.ResourceType != f.ResourceType,
+				f.ResourceID != "" && e.ResourceID != f.ResourceID,
+				!f.From.IsZero() && e.CreatedAt.Before(f.From),
+				!f.To.IsZero() && !e.CreatedAt.Before(f.To):
+				continue
+			}
+			events = append(events, e)
+		}
+	})
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].CreatedAt.Before(events[j].CreatedAt)
+		}
+		return events[i].ID < events[j].ID
+	})
+	return events
+}
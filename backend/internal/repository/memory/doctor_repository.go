@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type doctorRepo struct {
+	store *Store
+}
+
+func NewDoctorRepository(s *Store) repository.DoctorRepository {
+	return &doctorRepo{store: s}
+}
+
+var _ repository.DoctorRepository = (*doctorRepo)(nil)
+
+func (r *doctorRepo) CreateWithUser(ctx context.Context, user domain.User, doctor domain.Doctor) (domain.Doctor, error) {
+	err := r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.userByEmail(user.Email); ok {
+			return errors.New("email already exists")
+		}
+		if t.licenseTaken(doctor.LicenseNumber, 0) {
+			return errors.New("license number already exists")
+		}
+
+		t.insertUser(&user)
+
+		now := time.Now()
+		doctor.ID = t.nextID("doctors")
+		doctor.UserID = user.ID
+		doctor.CreatedAt = now
+		doctor.UpdatedAt = now
+		doctor.User = nil
+		doctor.Specialization = nil
+		t.doctors[doctor.ID] = doctor
+		return nil
+	})
+	if err != nil {
+		return domain.Doctor{}, err
+	}
+	return r.GetByDoctorID(ctx, doctor.ID)
+}
+
+// GetAll returns the newest doctors first.
+func (r *doctorRepo) GetAll(ctx context.Context) ([]domain.Doctor, error) {
+	var doctors []domain.Doctor
+	r.store.read(func(t *tables) {
+		for _, d := range t.doctors {
+			doctors = append(doctors, t.doctorWithUser(d))
+		}
+	})
+	sort.Slice(doctors, func(i, j int) bool {
+		if !doctors[i].CreatedAt.Equal(doctors[j].CreatedAt) {
+			return doctors[i].CreatedAt.After(doctors[j].CreatedAt)
+		}
+		return doctors[i].ID > doctors[j].ID
+	})
+	return doctors, nil
+}
+
+func (r *doctorRepo) GetByDoctorID(ctx context.Context, doctorID int) (domain.Doctor, error) {
+	return r.getOne(func(d domain.Doctor) bool { return d.ID == doctorID })
+}
+
+func (r *doctorRepo) GetByUserId(ctx context.Context, userID int) (domain.Doctor, error) {
+	return r.getOne(func(d domain.Doctor) bool { return d.UserID == userID })
+}
+
+func (r *doctorRepo) getOne(match func(domain.Doctor) bool) (domain.Doctor, error) {
+	var (
+		doctor domain.Doctor
+		found  bool
+	)
+	r.store.read(func(t *tables) {
+		for _, d := range t.doctors {
+			if match(d) {
+				doctor, found = t.doctorWithUser(d), true
+				return
+			}
+		}
+	})
+	if !found {
+		return domain.Doctor{}, errors.New("doctor not found")
+	}
+	return doctor, nil
+}
+
+func (r *doctorRepo) UpdateWithUser(ctx context.Context, user domain.User, doctor domain.Doctor) (domain.Doctor, error) {
+	err := r.store.write(ctx, func(t *tables) error {
+		if user.Email != "" {
+			if other, ok := t.userByEmail(user.Email); ok && other.ID != user.ID {
+				return errors.New("email already exists")
+			}
+		}
+
+		now := time.Now()
+		currentUser, ok := t.users[user.ID]
+		if !ok {
+			return errors.New("user not found or no changes made")
+		}
+		current, ok := t.doctors[doctor.ID]
+		if !ok {
+			return errors.New("doctor not found or no changes made")
+		}
+		if t.licenseTaken(doctor.LicenseNumber, doctor.ID) {
+			return errors.New("license number already exists")
+		}
+
+		currentUser.Name = user.Name
+		currentUser.Email = user.Email
+		currentUser.ProfilePicture = user.ProfilePicture
+		currentUser.UpdatedAt = now
+		t.users[user.ID] = currentUser
+
+		current.SpecializationID = doctor.SpecializationID
+		current.Gender = doctor.Gender
+		current.Address = doctor.Address
+		current.LicenseNumber = doctor.LicenseNumber
+		current.UpdatedAt = now
+		t.doctors[doctor.ID] = current
+		return nil
+	})
+	if err != nil {
+		return domain.Doctor{}, err
+	}
+	return r.GetByDoctorID(ctx, doctor.ID)
+}
+
+// Delete also removes the doctor's schedules and appointments, as the
+// ON DELETE CASCADE foreign keys do. The user account is kept.
+func (r *doctorRepo) Delete(ctx context.Context, id int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctors[id]; !ok {
+			return errors.New("doctor not found")
+		}
+		t.deleteDoctor(id)
+		return nil
+	})
+}
+
+// Search matches keyword anywhere in the doctor's name, ignoring case like
+// LIKE does under MySQL's default collation.
+func (r *doctorRepo) Search(ctx context.Context, keyword string, specializationID int) ([]domain.Doctor, error) {
+	keyword = strings.ToLower(keyword)
+
+	var doctors []domain.Doctor
+	r.store.read(func(t *tables) {
+		for _, d := range sortedByID(t.doctors) {
+			u, ok := t.users[d.UserID]
+			if !ok || !strings.Contains(strings.ToLower(u.Name), keyword) {
+				continue
+			}
+			if specializationID != 0 && d.SpecializationID != specializationID {
+				continue
+			}
+			d.User = &domain.User{ID: u.ID, Name: u.Name, Email: u.Email}
+			doctors = append(doctors, d)
+		}
+	})
+	return doctors, nil
+}
+
+// doctorWithUser fills in what the SQL version joins from users and
+// specializations.
+func (t *tables) doctorWithUser(d domain.Doctor) domain.Doctor {
+	u := t.users[d.UserID]
+	d.User = &domain.User{
+		Name:           u.Name,
+		Email:          u.Email,
+		Role:           u.Role,
+		ProfilePicture: u.ProfilePicture,
+	}
+	if s, ok := t.specializations[d.SpecializationID]; ok {
+		d.Specialization = &domain.Specialization{Name: s.Name}
+	}
+	return d
+}
+
+// licenseTaken reports whether another doctor than exceptID already has the
+// license number, which is UNIQUE in the schema.
+func (t *tables) licenseTaken(license string, exceptID int) bool {
+	if license == "" {
+		return false
+	}
+	for _, d := range t.doctors {
+		if d.ID != exceptID && d.LicenseNumber == license {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *tables) deleteDoctor(id int) {
+	delete(t.doctors, id)
+	for scheduleID, s := range t.doctorSchedules {
+		if s.DoctorID == id {
+			t.deleteSchedule(scheduleID)
+		}
+	}
+	for appointmentID, a := range t.appointments {
+		if a.DoctorID == id {
+			t.deleteAppointment(appointmentID)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type doctorScheduleRepo struct {
+	store *Store
+}
+
+func NewDoctorScheduleRepository(s *Store) repository.DoctorScheduleRepository {
+	return &doctorScheduleRepo{store: s}
+}
+
+var _ repository.DoctorScheduleRepository = (*doctorScheduleRepo)(nil)
+
+func (r *doctorScheduleRepo) Create(ctx context.Context, schedule domain.DoctorSchedule) (domain.DoctorSchedule, error) {
+	err := r.store.write(ctx, func(t *tables) error {
+		row := scheduleRow(schedule)
+		if t.scheduleTaken(row, 0) {
+			return errors.New("doctor schedule already exists")
+		}
+		now := time.Now()
+		row.ID = t.nextID("doctor_schedules")
+		row.CreatedAt = now
+		row.UpdatedAt = now
+		t.doctorSchedules[row.ID] = row
+		schedule.ID = row.ID
+		return nil
+	})
+	return schedule, err
+}
+
+func (r *doctorScheduleRepo) GetByID(ctx context.Context, id int) (domain.DoctorSchedule, error) {
+	var (
+		schedule domain.DoctorSchedule
+		ok       bool
+	)
+	r.store.read(func(t *tables) {
+		schedule, ok = t.doctorSchedules[id]
+		schedule = t.scheduleWithDoctor(schedule)
+	})
+	if !ok {
+		return domain.DoctorSchedule{}, errors.New("doctor schedule not found")
+	}
+	return schedule, nil
+}
+
+// GetByDoctorID returns the schedules by weekday, then start time.
+func (r *doctorScheduleRepo) GetByDoctorID(ctx context.Context, doctorID int) ([]domain.DoctorSchedule, error) {
+	var schedules []domain.DoctorSchedule
+	r.store.read(func(t *tables) {
+		for _, s := range t.doctorSchedules {
+			if s.DoctorID == doctorID {
+				schedules = append(schedules, t.scheduleWithDoctor(s))
+			}
+		}
+	})
+	sortSchedules(schedules)
+	return schedules, nil
+}
+
+func (r *doctorScheduleRepo) GetAll(ctx context.Context) ([]domain.DoctorSchedule, error) {
+	var schedules []domain.DoctorSchedule
+	r.store.read(func(t *tables) {
+		for _, s := range t.doctorSchedules {
+			schedules = append(schedules, t.scheduleWithDoctor(s))
+		}
+	})
+	sortSchedules(schedules)
+	return schedules, nil
+}
+
+// Update, like the SQL version, does not report a missing schedule.
+func (r *doctorScheduleRepo) Update(ctx context.Context, id int, schedule domain.DoctorSchedule) (domain.DoctorSchedule, error) {
+	err := r.store.write(ctx, func(t *tables) error {
+		current, ok := t.doctorSchedules[id]
+		if !ok {
+			return nil
+		}
+		row := scheduleRow(schedule)
+		if t.scheduleTaken(row, id) {
+			return errors.New("doctor schedule already exists")
+		}
+		row.ID = id
+		row.CreatedAt = current.CreatedAt
+		row.UpdatedAt = time.Now()
+		t.doctorSchedules[id] = row
+		return nil
+	})
+	schedule.ID = id
+	return schedule, err
+}
+
+func (r *doctorScheduleRepo) Delete(ctx context.Context, id int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		t.deleteSchedule(id)
+		return nil
+	})
+}
+
+// scheduleRow is s as stored: the times as a TIME column returns them and no
+// joined doctor.
+func scheduleRow(s domain.DoctorSchedule) domain.DoctorSchedule {
+	s.StartTime = timeOfDay(s.StartTime)
+	s.EndTime = timeOfDay(s.EndTime)
+	s.Doctor = nil
+	return s
+}
+
+// scheduleTaken checks the unique key on doctor, day, start and end time.
+func (t *tables) scheduleTaken(s domain.DoctorSchedule, exceptID int) bool {
+	for _, other := range t.doctorSchedules {
+		if other.ID != exceptID && other.DoctorID == s.DoctorID && other.WorkDay == s.WorkDay &&
+			other.StartTime == s.StartTime && other.EndTime == s.EndTime {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *tables) scheduleWithDoctor(s domain.DoctorSchedule) domain.DoctorSchedule {
+	d, ok := t.doctors[s.DoctorID]
+	if !ok {
+		return s
+	}
+	u := t.users[d.UserID]
+	d.User = &domain.User{Name: u.Name, Email: u.Email, Role: u.Role}
+	s.Doctor = &d
+	return s
+}
+
+// deleteSchedule removes the schedule and, through ON DELETE CASCADE, the
+// appointments booked on it.
+func (t *tables) deleteSchedule(id int) {
+	delete(t.doctorSchedules, id)
+	for appointmentID, a := range t.appointments {
+		if a.ScheduleID != nil && *a.ScheduleID == id {
+			t.deleteAppointment(appointmentID)
+		}
+	}
+}
+
+// workDayOrder is the order of the work_day ENUM in MySQL, which ORDER BY
+// follows.
+var workDayOrder = map[domain.WorkDay]int{
+	domain.WorkDayMonday:    1,
+	domain.WorkDayTuesday:   2,
+	domain.WorkDayWednesday: 3,
+	domain.WorkDayThursday:  4,
+	domain.WorkDayFriday:    5,
+	domain.WorkDaySaturday:  6,
+	domain.WorkDaySunday:    7,
+}
+
+// sortSchedules orders by doctor, weekday and start time.
+func sortSchedules(schedules []domain.DoctorSchedule) {
+	sort.Slice(schedules, func(i, j int) bool {
+		a, b := schedules[i], schedules[j]
+		if a.DoctorID != b.DoctorID {
+			return a.DoctorID < b.DoctorID
+		}
+		if a.WorkDay != b.WorkDay {
+			return workDayOrder[a.WorkDay] < workDayOrder[b.WorkDay]
+		}
+		return a.StartTime < b.StartTime
+	})
+}
+
+// timeOfDay writes HH:MM as HH:MM:SS, the way TIME columns come back.
+func timeOfDay(clock string) string {
+	if len(clock) == len("15:04") {
+		return clock + ":00"
+	}
+	return clock
+}
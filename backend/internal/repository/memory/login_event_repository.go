@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type loginEventRepo struct {
+	store *Store
+}
+
+func NewLoginEventRepository(s *Store) repository.LoginEventRepository {
+	return &loginEventRepo{store: s}
+}
+
+var _ repository.LoginEventRepository = (*loginEventRepo)(nil)
+
+func (r *loginEventRepo) Create(ctx context.Context, e *domain.LoginEvent) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	return r.store.write(ctx, func(t *tables) error {
+		e.ID = t.nextID("login_events")
+		row := *e
+		if e.UserID != nil {
+			id := *e.UserID
+			row.UserID = &id
+		}
+		t.loginEvents[e.ID] = row
+		return nil
+	})
+}
+
+func (r *loginEventRepo) FailuresForEmail(ctx context.Context, email string, since time.Time) (int, time.Time, error) {
+	var lastSuccess time.Time
+	r.store.read(func(t *tables) {
+		for _, e := range t.loginEvents {
+			if e.Email == email && e.Success && e.CreatedAt.After(lastSuccess) {
+				lastSuccess = e.CreatedAt
+			}
+		}
+	})
+	if lastSuccess.After(since) {
+		since = lastSuccess
+	}
+	return r.failures(since, func(e domain.LoginEvent) bool { return e.Email == email })
+}
+
+func (r *loginEventRepo) FailuresForIP(ctx context.Context, ip string, since time.Time) (int, time.Time, error) {
+	return r.failures(since, func(e domain.LoginEvent) bool { return e.IP == ip })
+}
+
+// failures counts the failed attempts after since that were not rejected
+// because of a lockout, and returns when the latest one happened.
+func (r *loginEventRepo) failures(since time.Time, match func(domain.LoginEvent) bool) (int, time.Time, error) {
+	var (
+		count int
+		last  time.Time
+	)
+	r.store.read(func(t *tables) {
+		for _, e := range t.loginEvents {
+			if !match(e) || e.Success || e.Reason == domain.LoginReasonLocked || !e.CreatedAt.After(since) {
+				continue
+			}
+			count++
+			if e.CreatedAt.After(last) {
+				last = e.CreatedAt
+			}
+		}
+	})
+	return count, last, nil
+}
+
+// List returns the newest events first.
+func (r *loginEventRepo) List(ctx context.Context, f domain.LoginEventFilter) ([]domain.LoginEvent, error) {
+	events := []domain.LoginEvent{}
+	r.store.read(func(t *tables) {
+		for _, e := range t.loginEvents {
+			switch {
+			case f.Email != "" && e.Email != f.Email,
+				f.IP != "" && e.IP != f.IP,
+				f.UserID != 0 && (e.UserID == nil || *e.UserID != f.UserID),
+				f.Success != nil && e.Success != *f.Success,
+				!f.From.IsZero() && e.CreatedAt.Before(f.From),
+				!f.To.IsZero() && !e.CreatedAt.Before(f.To):
+				continue
+			}
+			events = append(events, e)
+		}
+	})
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].CreatedAt.After(events[j].CreatedAt)
+		}
+		return events[i].ID > events[j].ID
+	})
+	if limit := max(f.Limit, 0); len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
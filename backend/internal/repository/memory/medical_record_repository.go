@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type medicalRecordRepo struct {
+	store *Store
+}
+
+func NewMedicalRecordRepository(s *Store) repository.MedicalRecordRepository {
+	return &medicalRecordRepo{store: s}
+}
+
+var _ repository.MedicalRecordRepository = (*medicalRecordRepo)(nil)
+
+func (r *medicalRecordRepo) CreateTx(ctx context.Context, tx *sql.Tx, m *domain.MedicalRecord) error {
+	return r.store.writeTx(func(t *tables) error {
+		for _, other := range t.medicalRecords {
+			if other.AppointmentID == m.AppointmentID {
+				return errors.New("medical record already exists for appointment")
+			}
+		}
+		now := time.Now()
+		m.ID = t.nextID("medical_records")
+		m.CreatedAt = now
+		m.UpdatedAt = now
+
+		row := *m
+		row.Appointment = nil
+		t.medicalRecords[m.ID] = row
+		return nil
+	})
+}
+
+func (r *medicalRecordRepo) UpdateTx(ctx context.Context, tx *sql.Tx, m *domain.MedicalRecord) error {
+	return r.store.writeTx(func(t *tables) error {
+		current, ok := t.medicalRecords[m.ID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		current.Diagnosis = m.Diagnosis
+		current.Prescription = m.Prescription
+		current.DoctorNotes = m.DoctorNotes
+		current.UpdatedAt = time.Now()
+		t.medicalRecords[m.ID] = current
+
+		m.UpdatedAt = current.UpdatedAt
+		return nil
+	})
+}
+
+func (r *medicalRecordRepo) GetByAppointmentID(ctx context.Context, appointmentID int64) (*domain.MedicalRecord, error) {
+	var (
+		record domain.MedicalRecord
+		found  bool
+	)
+	r.store.read(func(t *tables) {
+		for _, m := range t.medicalRecords {
+			if m.AppointmentID == int(appointmentID) {
+				record, found = m, true
+				return
+			}
+		}
+	})
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return &record, nil
+}
+
+// GetByPatient returns the patient's records in the order of their
+// appointments, each with the appointment, its doctor and specialization.
+func (r *medicalRecordRepo) GetByPatient(ctx context.Context, patientID int64, filter domain.MedicalRecordFilter) ([]domain.MedicalRecord, error) {
+	var result []domain.MedicalRecord
+	r.store.read(func(t *tables) {
+		for _, m := range sortedByID(t.medicalRecords) {
+			a, ok := t.appointments[m.AppointmentID]
+			if !ok || a.PatientID != int(patientID) {
+				continue
+			}
+			if filter.From != nil && a.AppointmentDate.Before(dateOnly(*filter.From)) {
+				continue
+			}
+			if filter.To != nil && a.AppointmentDate.After(dateOnly(*filter.To)) {
+				continue
+			}
+			if filter.DoctorID != 0 && a.DoctorID != filter.DoctorID {
+				continue
+			}
+
+			a.Doctor = t.appointmentDoctor(a)
+			if a.Doctor != nil {
+				if s, ok := t.specializations[t.doctors[a.DoctorID].SpecializationID]; ok {
+					a.Doctor.SpecializationID = s.ID
+					a.Doctor.Specialization = &domain.Specialization{ID: s.ID, Name: s.Name}
+				}
+			}
+			m.Appointment = &a
+			result = append(result, m)
+		}
+	})
+	sort.SliceStable(result, func(i, j int) bool {
+		return appointmentBefore(*result[i].Appointment, *result[j].Appointment)
+	})
+	return result, nil
+}
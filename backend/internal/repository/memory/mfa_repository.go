@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type mfaRepo struct {
+	store *Store
+}
+
+func NewMFARepository(s *Store) repository.MFARepository {
+	return &mfaRepo{store: s}
+}
+
+var _ repository.MFARepository = (*mfaRepo)(nil)
+
+func (r *mfaRepo) Get(ctx context.Context, userID int) (*domain.UserMFA, error) {
+	var (
+		m  domain.UserMFA
+		ok bool
+	)
+	r.store.read(func(t *tables) {
+		m, ok = t.userMFA[userID]
+	})
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &m, nil
+}
+
+func (r *mfaRepo) SavePending(ctx context.Context, userID int, secret string) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if m, ok := t.userMFA[userID]; ok && m.EnabledAt != nil {
+			return sql.ErrNoRows
+		}
+		t.userMFA[userID] = domain.UserMFA{UserID: userID, Secret: secret, CreatedAt: time.Now()}
+		return nil
+	})
+}
+
+func (r *mfaRepo) Enable(ctx context.Context, userID int, step int64) error {
+	return r.store.write(ctx, func(t *tables) error {
+		m, ok := t.userMFA[userID]
+		if !ok || m.EnabledAt != nil {
+			return sql.ErrNoRows
+		}
+		now := time.Now()
+		m.EnabledAt = &now
+		m.LastUsedStep = step
+		t.userMFA[userID] = m
+		return nil
+	})
+}
+
+func (r *mfaRepo) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	used := false
+	err := r.store.write(ctx, func(t *tables) error {
+		m, ok := t.userMFA[userID]
+		if !ok || m.LastUsedStep >= step {
+			return nil
+		}
+		m.LastUsedStep = step
+		t.userMFA[userID] = m
+		used = true
+		return nil
+	})
+	return used, err
+}
+
+func (r *mfaRepo) Delete(ctx context.Context, userID int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		t.deleteRecoveryCodes(userID)
+		delete(t.userMFA, userID)
+		return nil
+	})
+}
+
+func (r *mfaRepo) ReplaceRecoveryCodes(ctx context.Context, userID int, hashes []string) error {
+	return r.store.write(ctx, func(t *tables) error {
+		t.deleteRecoveryCodes(userID)
+		for _, hash := range hashes {
+			t.recoveryCodes[t.nextID("mfa_recovery_codes")] = recoveryCode{userID: userID, hash: hash}
+		}
+		return nil
+	})
+}
+
+func (r *mfaRepo) UseRecoveryCode(ctx context.Context, userID int, hash string) (bool, error) {
+	used := false
+	err := r.store.write(ctx, func(t *tables) error {
+		for id, code := range t.recoveryCodes {
+			if code.userID == userID && code.hash == hash && !code.used {
+				code.used = true
+				t.recoveryCodes[id] = code
+				used = true
+				return nil
+			}
+		}
+		return nil
+	})
+	return used, err
+}
+
+func (t *tables) deleteRecoveryCodes(userID int) {
+	for id, code := range t.recoveryCodes {
+		if code.userID == userID {
+			delete(t.recoveryCodes, id)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type patientRepo struct {
+	store *Store
+}
+
+func NewPatientRepository(s *Store) repository.PatientRepository {
+	return &patientRepo{store: s}
+}
+
+var _ repository.PatientRepository = (*patientRepo)(nil)
+
+func (r *patientRepo) GetByUserID(ctx context.Context, userID int64) (*domain.Patient, error) {
+	return r.getOne(func(p domain.Patient) bool { return p.UserID == int(userID) })
+}
+
+func (r *patientRepo) GetByID(ctx context.Context, id int64) (*domain.Patient, error) {
+	return r.getOne(func(p domain.Patient) bool { return p.ID == int(id) })
+}
+
+// getOne loads a patient together with the name and email of its user.
+func (r *patientRepo) getOne(match func(domain.Patient) bool) (*domain.Patient, error) {
+	var (
+		patient domain.Patient
+		found   bool
+	)
+	r.store.read(func(t *tables) {
+		for _, p := range t.patients {
+			if !match(p) {
+				continue
+			}
+			u, ok := t.users[p.UserID]
+			if !ok {
+				return
+			}
+			p.User = &domain.User{ID: u.ID, Name: u.Name, Email: u.Email, Role: domain.RolePatient}
+			patient, found = p, true
+			return
+		}
+	})
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return &patient, nil
+}
+
+func (r *patientRepo) CreateForUser(ctx context.Context, userID int64) (*domain.Patient, error) {
+	var patient domain.Patient
+	err := r.store.write(ctx, func(t *tables) error {
+		for _, p := range t.patients {
+			if p.UserID == int(userID) {
+				return errors.New("patient already exists for user")
+			}
+		}
+		now := time.Now()
+		patient = domain.Patient{
+			ID:        t.nextID("patients"),
+			UserID:    int(userID),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		t.patients[patient.ID] = patient
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &patient, nil
+}
+
+// Update stores the patient's demographics. Like the SQL version it does not
+// report a missing patient.
+func (r *patientRepo) Update(ctx context.Context, p *domain.Patient) error {
+	return r.store.write(ctx, func(t *tables) error {
+		current, ok := t.patients[p.ID]
+		if !ok {
+			return nil
+		}
+		if p.DateOfBirth != nil {
+			dob := *p.DateOfBirth
+			current.DateOfBirth = &dob
+		} else {
+			current.DateOfBirth = nil
+		}
+		current.Phone = p.Phone
+		current.Address = p.Address
+		current.BloodType = p.BloodType
+		current.UpdatedAt = time.Now()
+		t.patients[p.ID] = current
+
+		p.UpdatedAt = current.UpdatedAt
+		return nil
+	})
+}
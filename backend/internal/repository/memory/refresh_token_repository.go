@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type refreshTokenRepo struct {
+	store *Store
+}
+
+func NewRefreshTokenRepository(s *Store) repository.RefreshTokenRepository {
+	return &refreshTokenRepo{store: s}
+}
+
+var _ repository.RefreshTokenRepository = (*refreshTokenRepo)(nil)
+
+func (r *refreshTokenRepo) CreateTx(ctx context.Context, tx *sql.Tx, tok *domain.RefreshToken) error {
+	return r.store.writeTx(func(t *tables) error {
+		for _, other := range t.refreshTokens {
+			if other.TokenHash == tok.TokenHash || other.AccessJTI == tok.AccessJTI {
+				return errors.New("refresh token already exists")
+			}
+		}
+		tok.ID = t.nextID("refresh_tokens")
+		tok.CreatedAt = time.Now()
+		t.refreshTokens[tok.ID] = *tok
+		return nil
+	})
+}
+
+func (r *refreshTokenRepo) GetByHashForUpdateTx(ctx context.Context, tx *sql.Tx, hash string) (*domain.RefreshToken, error) {
+	return r.getOne(func(tok domain.RefreshToken) bool { return tok.TokenHash == hash })
+}
+
+func (r *refreshTokenRepo) GetByAccessJTI(ctx context.Context, jti string) (*domain.RefreshToken, error) {
+	return r.getOne(func(tok domain.RefreshToken) bool { return tok.AccessJTI == jti })
+}
+
+func (r *refreshTokenRepo) getOne(match func(domain.RefreshToken) bool) (*domain.RefreshToken, error) {
+	var (
+		token domain.RefreshToken
+		found bool
+	)
+	r.store.read(func(t *tables) {
+		for _, tok := range t.refreshTokens {
+			if match(tok) {
+				token, found = tok, true
+				return
+			}
+		}
+	})
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepo) MarkRotatedTx(ctx context.Context, tx *sql.Tx, id int) error {
+	return r.store.writeTx(func(t *tables) error {
+		tok, ok := t.refreshTokens[id]
+		if !ok || tok.RotatedAt != nil {
+			return sql.ErrNoRows
+		}
+		now := time.Now()
+		tok.RotatedAt = &now
+		t.refreshTokens[id] = tok
+		return nil
+	})
+}
+
+func (r *refreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.revoke(ctx, func(tok domain.RefreshToken) bool { return tok.FamilyID == familyID })
+}
+
+func (r *refreshTokenRepo) RevokeAllForUser(ctx context.Context, userID int) error {
+	return r.revoke(ctx, func(tok domain.RefreshToken) bool { return tok.UserID == userID })
+}
+
+func (r *refreshTokenRepo) revoke(ctx context.Context, match func(domain.RefreshToken) bool) error {
+	return r.store.write(ctx, func(t *tables) error {
+		now := time.Now()
+		for id, tok := range t.refreshTokens {
+			if match(tok) && tok.RevokedAt == nil {
+				tok.RevokedAt = &now
+				t.refreshTokens[id] = tok
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type scheduleRepo struct {
+	store *Store
+}
+
+func NewScheduleRepository(s *Store) repository.ScheduleRepository {
+	return &scheduleRepo{store: s}
+}
+
+var _ repository.ScheduleRepository = (*scheduleRepo)(nil)
+
+func (r *scheduleRepo) GetByID(ctx context.Context, id int64) (*domain.DoctorSchedule, error) {
+	var (
+		schedule domain.DoctorSchedule
+		ok       bool
+	)
+	r.store.read(func(t *tables) {
+		schedule, ok = t.doctorSchedules[int(id)]
+	})
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &schedule, nil
+}
+
+// GetByIDForUpdate needs no lock of its own, the transaction already
+// excludes every other one.
+func (r *scheduleRepo) GetByIDForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*domain.DoctorSchedule, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *scheduleRepo) GetByDoctorAndDayTx(ctx context.Context, tx *sql.Tx, doctorID int64, day domain.WorkDay) ([]domain.DoctorSchedule, error) {
+	return r.list(func(s domain.DoctorSchedule) bool {
+		return s.DoctorID == int(doctorID) && s.WorkDay == day
+	}), nil
+}
+
+func (r *scheduleRepo) GetByDoctor(ctx context.Context, doctorID int64) ([]domain.DoctorSchedule, error) {
+	return r.list(func(s domain.DoctorSchedule) bool {
+		return s.DoctorID == int(doctorID)
+	}), nil
+}
+
+// list returns the matching schedules ordered by start time.
+func (r *scheduleRepo) list(match func(domain.DoctorSchedule) bool) []domain.DoctorSchedule {
+	var result []domain.DoctorSchedule
+	r.store.read(func(t *tables) {
+		for _, s := range sortedByID(t.doctorSchedules) {
+			if match(s) {
+				result = append(result, s)
+			}
+		}
+	})
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].StartTime < result[j].StartTime
+	})
+	return result
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type specializationRepo struct {
+	store *Store
+}
+
+func NewSpecializationRepository(s *Store) repository.SpecializationRepository {
+	return &specializationRepo{store: s}
+}
+
+var _ repository.SpecializationRepository = (*specializationRepo)(nil)
+
+func (r *specializationRepo) GetAll(ctx context.Context) ([]domain.Specialization, error) {
+	specializations := []domain.Specialization{}
+	r.store.read(func(t *tables) {
+		for _, s := range t.specializations {
+			specializations = append(specializations, s)
+		}
+	})
+	sort.Slice(specializations, func(i, j int) bool {
+		return specializations[i].Name < specializations[j].Name
+	})
+	return specializations, nil
+}
+
+func (r *specializationRepo) GetByID(ctx context.Context, id int) (domain.Specialization, error) {
+	var (
+		specialization domain.Specialization
+		ok             bool
+	)
+	r.store.read(func(t *tables) {
+		specialization, ok = t.specializations[id]
+	})
+	if !ok {
+		return domain.Specialization{}, errors.New("specialization not found")
+	}
+	return specialization, nil
+}
+
+func (r *specializationRepo) FindByName(ctx context.Context, name string) (domain.Specialization, error) {
+	var (
+		specialization domain.Specialization
+		ok             bool
+	)
+	r.store.read(func(t *tables) {
+		specialization, ok = t.specializationByName(name)
+	})
+	if !ok {
+		return domain.Specialization{}, errors.New("specialization not found")
+	}
+	return specialization, nil
+}
+
+func (r *specializationRepo) Create(ctx context.Context, specialization domain.Specialization) (domain.Specialization, error) {
+	err := r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.specializationByName(specialization.Name); ok {
+			return errors.New("specialization name already exists")
+		}
+		now := time.Now()
+		specialization.ID = t.nextID("specializations")
+		specialization.CreatedAt = now
+		specialization.UpdatedAt = now
+		t.specializations[specialization.ID] = specialization
+		return nil
+	})
+	return specialization, err
+}
+
+func (r *specializationRepo) Update(ctx context.Context, specialization domain.Specialization) (domain.Specialization, error) {
+	err := r.store.write(ctx, func(t *tables) error {
+		if other, ok := t.specializationByName(specialization.Name); ok && other.ID != specialization.ID {
+			return errors.New("specialization name already exists")
+		}
+		current, ok := t.specializations[specialization.ID]
+		if !ok {
+			return nil
+		}
+		current.Name = specialization.Name
+		current.UpdatedAt = time.Now()
+		t.specializations[current.ID] = current
+		return nil
+	})
+	if err != nil {
+		return specialization, err
+	}
+	return r.GetByID(ctx, specialization.ID)
+}
+
+// Delete refuses specializations doctors still point at, like the
+// ON DELETE RESTRICT foreign key.
+func (r *specializationRepo) Delete(ctx context.Context, id int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if t.countDoctors(id) > 0 {
+			return errors.New("specialization is still used by doctors")
+		}
+		if _, ok := t.specializations[id]; !ok {
+			return errors.New("specialization not found")
+		}
+		delete(t.specializations, id)
+		return nil
+	})
+}
+
+func (r *specializationRepo) CountDoctors(ctx context.Context, id int) (int, error) {
+	var count int
+	r.store.read(func(t *tables) {
+		count = t.countDoctors(id)
+	})
+	return count, nil
+}
+
+func (t *tables) specializationByName(name string) (domain.Specialization, bool) {
+	for _, s := range t.specializations {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return domain.Specialization{}, false
+}
+
+func (t *tables) countDoctors(specializationID int) int {
+	count := 0
+	for _, d := range t.doctors {
+		if d.SpecializationID == specializationID {
+			count++
+		}
+	}
+	return count
+}
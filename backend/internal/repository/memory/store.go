@@ -0,0 +1,187 @@
+// Package memory implements the repository interfaces on top of plain Go
+// maps, for unit tests of the service layer that should not need a database.
+//
+// All repositories created from one Store share its tables, so a user
+// registered through the UserRepository is seen by the PatientRepository,
+// and the TxRunner from NewTxRunner rolls back writes made by any of them.
+// The repositories mirror the SQL ones: the same errors, the same ordering,
+// and the foreign key cascades of the schema.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+// Store holds the tables. It is safe for concurrent use.
+type Store struct {
+	// txMu is held for the whole of a transaction, which serializes them the
+	// way the row locks taken with FOR UPDATE do
+	txMu sync.Mutex
+
+	mu sync.Mutex
+	t  tables
+}
+
+// tables is one map per table, keyed by primary key. Rows are stored by value
+// with the joined relations left nil, so a snapshot is a copy of the maps.
+type tables struct {
+	seq map[string]int
+
+	users              map[int]domain.User
+	specializations    map[int]domain.Specialization
+	doctors            map[int]domain.Doctor
+	doctorSchedules    map[int]domain.DoctorSchedule
+	patients           map[int]domain.Patient
+	appointments       map[int]domain.Appointment
+	appointmentChanges map[int]domain.AppointmentChange
+	medicalRecords     map[int]domain.MedicalRecord
+	userTokens         map[int]domain.UserToken
+	refreshTokens      map[int]domain.RefreshToken
+	loginEvents        map[int]domain.LoginEvent
+	auditEvents        map[int]domain.AuditEvent
+	userMFA            map[int]domain.UserMFA
+	recoveryCodes      map[int]recoveryCode
+}
+
+type recoveryCode struct {
+	userID int
+	hash   string
+	used   bool
+}
+
+func NewStore() *Store {
+	return &Store{t: tables{
+		seq:                map[string]int{},
+		users:              map[int]domain.User{},
+		specializations:    map[int]domain.Specialization{},
+		doctors:            map[int]domain.Doctor{},
+		doctorSchedules:    map[int]domain.DoctorSchedule{},
+		patients:           map[int]domain.Patient{},
+		appointments:       map[int]domain.Appointment{},
+		appointmentChanges: map[int]domain.AppointmentChange{},
+		medicalRecords:     map[int]domain.MedicalRecord{},
+		userTokens:         map[int]domain.UserToken{},
+		refreshTokens:      map[int]domain.RefreshToken{},
+		loginEvents:        map[int]domain.LoginEvent{},
+		auditEvents:        map[int]domain.AuditEvent{},
+		userMFA:            map[int]domain.UserMFA{},
+		recoveryCodes:      map[int]recoveryCode{},
+	}}
+}
+
+func (t *tables) clone() tables {
+	return tables{
+		seq:                maps.Clone(t.seq),
+		users:              maps.Clone(t.users),
+		specializations:    maps.Clone(t.specializations),
+		doctors:            maps.Clone(t.doctors),
+		doctorSchedules:    maps.Clone(t.doctorSchedules),
+		patients:           maps.Clone(t.patients),
+		appointments:       maps.Clone(t.appointments),
+		appointmentChanges: maps.Clone(t.appointmentChanges),
+		medicalRecords:     maps.Clone(t.medicalRecords),
+		userTokens:         maps.Clone(t.userTokens),
+		refreshTokens:      maps.Clone(t.refreshTokens),
+		loginEvents:        maps.Clone(t.loginEvents),
+		auditEvents:        maps.Clone(t.auditEvents),
+		userMFA:            maps.Clone(t.userMFA),
+		recoveryCodes:      maps.Clone(t.recoveryCodes),
+	}
+}
+
+// nextID hands out the next auto increment value of table.
+func (t *tables) nextID(table string) int {
+	t.seq[table]++
+	return t.seq[table]
+}
+
+// read runs fn with the tables locked.
+func (s *Store) read(fn func(t *tables)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.t)
+}
+
+// write runs fn with the tables locked. Outside a transaction it first waits
+// for the running one to finish, like a write would on a row locked by it.
+// The services hand the transaction down in the context under "tx", that is
+// how calls made from within a transaction are told apart.
+func (s *Store) write(ctx context.Context, fn func(t *tables) error) error {
+	if !inTx(ctx) {
+		s.txMu.Lock()
+		defer s.txMu.Unlock()
+	}
+	return s.writeTx(fn)
+}
+
+// writeTx is write for the *Tx repository methods, which are only called
+// inside a transaction.
+func (s *Store) writeTx(fn func(t *tables) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(&s.t)
+}
+
+func inTx(ctx context.Context) bool {
+	_, ok := ctx.Value("tx").(*sql.Tx)
+	return ok
+}
+
+type txRunner struct {
+	store *Store
+}
+
+// NewTxRunner returns a repository.TxRunner for the repositories of s. The
+// tx passed to fn is nil; the memory repositories do not need it.
+func NewTxRunner(s *Store) repository.TxRunner {
+	return &txRunner{store: s}
+}
+
+var _ repository.TxRunner = (*txRunner)(nil)
+
+// WithTx restores the tables as they were before fn when it fails. Writes
+// made outside a transaction wait until it is over, so nothing else is lost;
+// reads do not wait and may see its uncommitted rows.
+func (r *txRunner) WithTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	s := r.store
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	snapshot := s.t.clone()
+	s.mu.Unlock()
+
+	defer func() {
+		p := recover()
+		if err != nil || p != nil {
+			s.mu.Lock()
+			s.t = snapshot
+			s.mu.Unlock()
+		}
+		if p != nil {
+			panic(p)
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fn(nil)
+}
+
+// sortedByID returns the rows of a table in primary key order, which is the
+// order the SQL queries without ORDER BY give back in practice.
+func sortedByID[V any](m map[int]V) []V {
+	rows := make([]V, 0, len(m))
+	for _, id := range slices.Sorted(maps.Keys(m)) {
+		rows = append(rows, m[id])
+	}
+	return rows
+}
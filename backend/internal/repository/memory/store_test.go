@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+func TestWithTxRollsBack(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	tx := NewTxRunner(store)
+	users := NewUserRepository(store)
+	tokens := NewUserTokenRepository(store)
+
+	user, err := users.Register(ctx, domain.User{Name: "Budi", Email: "budi@example.com"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	token := &domain.UserToken{UserID: user.ID, Purpose: domain.TokenPurposeInvite, TokenHash: "h", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := tokens.Create(ctx, token); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	failed := errors.New("failed")
+	err = tx.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := tokens.MarkUsedTx(ctx, tx, token.ID); err != nil {
+			return err
+		}
+		if err := users.UpdatePassword(context.WithValue(ctx, "tx", tx), user.ID, "secret"); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("WithTx = %v, want the error of fn", err)
+	}
+
+	got, err := tokens.GetByHash(ctx, domain.TokenPurposeInvite, "h")
+	if err != nil {
+		t.Fatalf("GetByHash: %v", err)
+	}
+	if got.UsedAt != nil {
+		t.Error("token stayed used after the rollback")
+	}
+	if u, _ := users.FindByID(ctx, user.ID); u.Password != "" {
+		t.Errorf("password = %q after the rollback, want it unchanged", u.Password)
+	}
+}
+
+func TestWriteWaitsForTx(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	tx := NewTxRunner(store)
+	users := NewUserRepository(store)
+
+	inTx := make(chan struct{})
+	written := make(chan error)
+	err := tx.WithTx(ctx, func(*sql.Tx) error {
+		close(inTx)
+		go func() {
+			_, err := users.Register(ctx, domain.User{Name: "Budi", Email: "budi@example.com"})
+			written <- err
+		}()
+
+		select {
+		case <-written:
+			t.Error("a write outside the transaction did not wait for it")
+		case <-time.After(50 * time.Millisecond):
+		}
+		return errors.New("rollback")
+	})
+	if err == nil {
+		t.Fatal("WithTx should return the error of fn")
+	}
+	<-inTx
+
+	// The rollback must not have swallowed the write that waited for it
+	if err := <-written; err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := users.FindByEmail(ctx, "budi@example.com"); err != nil {
+		t.Errorf("FindByEmail: %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type userRepo struct {
+	store *Store
+}
+
+func NewUserRepository(s *Store) repository.UserRepository {
+	return &userRepo{store: s}
+}
+
+var _ repository.UserRepository = (*userRepo)(nil)
+
+func (r *userRepo) Register(ctx context.Context, user domain.User) (domain.User, error) {
+	err := r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.userByEmail(user.Email); ok {
+			return errors.New("email sudah ada")
+		}
+		t.insertUser(&user)
+		return nil
+	})
+	return user, err
+}
+
+func (r *userRepo) FindByEmail(ctx context.Context, email string) (domain.User, error) {
+	var (
+		user domain.User
+		ok   bool
+	)
+	r.store.read(func(t *tables) {
+		user, ok = t.userByEmail(email)
+	})
+	if !ok {
+		return domain.User{}, errors.New("user not found")
+	}
+	return user, nil
+}
+
+func (r *userRepo) FindByID(ctx context.Context, id int) (domain.User, error) {
+	var (
+		user domain.User
+		ok   bool
+	)
+	r.store.read(func(t *tables) {
+		user, ok = t.users[id]
+	})
+	if !ok {
+		return domain.User{}, errors.New("user not found")
+	}
+	return user, nil
+}
+
+func (r *userRepo) Update(ctx context.Context, user domain.User) (domain.User, error) {
+	err := r.store.write(ctx, func(t *tables) error {
+		current, ok := t.users[user.ID]
+		if !ok {
+			return errors.New("user not found or no changes made")
+		}
+		if other, ok := t.userByEmail(user.Email); ok && other.ID != user.ID {
+			return errors.New("email sudah ada")
+		}
+
+		current.Name = user.Name
+		current.Email = user.Email
+		current.Role = user.Role
+		current.ProfilePicture = user.ProfilePicture
+		current.UpdatedAt = time.Now()
+		t.users[user.ID] = current
+		return nil
+	})
+	return user, err
+}
+
+func (r *userRepo) UpdatePassword(ctx context.Context, userID int, hashedPassword string) error {
+	return r.store.write(ctx, func(t *tables) error {
+		user, ok := t.users[userID]
+		if !ok {
+			return errors.New("user not found")
+		}
+		user.Password = hashedPassword
+		user.UpdatedAt = time.Now()
+		t.users[userID] = user
+		return nil
+	})
+}
+
+// MarkEmailVerified keeps the first timestamp, like the SQL version.
+func (r *userRepo) MarkEmailVerified(ctx context.Context, userID int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		user, ok := t.users[userID]
+		if ok && user.EmailVerifiedAt == nil {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+			t.users[userID] = user
+		}
+		return nil
+	})
+}
+
+func (t *tables) userByEmail(email string) (domain.User, bool) {
+	for _, user := range t.users {
+		if user.Email == email {
+			return user, true
+		}
+	}
+	return domain.User{}, false
+}
+
+// insertUser stores user and sets its ID and timestamps.
+func (t *tables) insertUser(user *domain.User) {
+	if user.Role == "" {
+		user.Role = domain.RolePatient
+	}
+	now := time.Now()
+	user.ID = t.nextID("users")
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	t.users[user.ID] = *user
+}
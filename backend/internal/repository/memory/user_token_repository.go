@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type userTokenRepo struct {
+	store *Store
+}
+
+func NewUserTokenRepository(s *Store) repository.UserTokenRepository {
+	return &userTokenRepo{store: s}
+}
+
+var _ repository.UserTokenRepository = (*userTokenRepo)(nil)
+
+func (r *userTokenRepo) Create(ctx context.Context, tok *domain.UserToken) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, other := range t.userTokens {
+			if other.TokenHash == tok.TokenHash {
+				return errors.New("token hash already exists")
+			}
+		}
+		tok.ID = t.nextID("user_tokens")
+		tok.CreatedAt = time.Now()
+		t.userTokens[tok.ID] = *tok
+		return nil
+	})
+}
+
+func (r *userTokenRepo) GetByHashForUpdateTx(ctx context.Context, tx *sql.Tx, purpose domain.TokenPurpose, hash string) (*domain.UserToken, error) {
+	return r.GetByHash(ctx, purpose, hash)
+}
+
+func (r *userTokenRepo) GetByHash(ctx context.Context, purpose domain.TokenPurpose, hash string) (*domain.UserToken, error) {
+	var (
+		token domain.UserToken
+		found bool
+	)
+	r.store.read(func(t *tables) {
+		for _, tok := range t.userTokens {
+			if tok.Purpose == purpose && tok.TokenHash == hash {
+				token, found = tok, true
+				return
+			}
+		}
+	})
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return &token, nil
+}
+
+func (r *userTokenRepo) MarkUsedTx(ctx context.Context, tx *sql.Tx, id int) error {
+	return r.store.writeTx(func(t *tables) error { return t.markUserTokenUsed(id) })
+}
+
+func (r *userTokenRepo) MarkUsed(ctx context.Context, id int) error {
+	return r.store.write(ctx, func(t *tables) error { return t.markUserTokenUsed(id) })
+}
+
+// markUserTokenUsed returns sql.ErrNoRows when there is no unused token id.
+func (t *tables) markUserTokenUsed(id int) error {
+	tok, ok := t.userTokens[id]
+	if !ok || tok.UsedAt != nil {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	tok.UsedAt = &now
+	t.userTokens[id] = tok
+	return nil
+}
+
+// RevokeUnused expires every outstanding token of the user for purpose, so
+// only the newest one handed out stays redeemable.
+func (r *userTokenRepo) RevokeUnused(ctx context.Context, userID int, purpose domain.TokenPurpose) error {
+	return r.store.write(ctx, func(t *tables) error {
+		now := time.Now()
+		for id, tok := range t.userTokens {
+			if tok.UserID == userID && tok.Purpose == purpose && tok.UsedAt == nil && tok.ExpiresAt.After(now) {
+				tok.ExpiresAt = now
+				t.userTokens[id] = tok
+			}
+		}
+		return nil
+	})
+}
+
+func (r *userTokenRepo) HasUsed(ctx context.Context, userID int, purpose domain.TokenPurpose) (bool, error) {
+	used := false
+	r.store.read(func(t *tables) {
+		for _, tok := range t.userTokens {
+			if tok.UserID == userID && tok.Purpose == purpose && tok.UsedAt != nil {
+				used = true
+				return
+			}
+		}
+	})
+	return used, nil
+}
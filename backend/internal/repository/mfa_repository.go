@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+type MFARepository interface {
+	Get(ctx context.Context, userID int) (*domain.UserMFA, error)
+	// SavePending stores a new, not yet enabled secret, replacing a pending
+	// one. An enabled enrollment is left alone and sql.ErrNoRows is returned.
+	SavePending(ctx context.Context, userID int, secret string) error
+	Enable(ctx context.Context, userID int, step int64) error
+	// UseStep records step as used; false means it was not newer than the
+	// last accepted one, i.e. the code is replayed.
+	UseStep(ctx context.Context, userID int, step int64) (bool, error)
+	Delete(ctx context.Context, userID int) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int, hashes []string) error
+	// UseRecoveryCode burns the code and reports whether it was valid.
+	UseRecoveryCode(ctx context.Context, userID int, hash string) (bool, error)
+}
+
+type mfaRepoMySQL struct {
+	db *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) MFARepository {
+	return &mfaRepoMySQL{db: db}
+}
+
+var _ MFARepository = (*mfaRepoMySQL)(nil)
+
+func (r *mfaRepoMySQL) Get(ctx context.Context, userID int) (*domain.UserMFA, error) {
+	const q = `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at
+		FROM user_mfa
+		WHERE user_id = ?
+	`
+
+	var (
+		m         domain.UserMFA
+		enabledAt sql.NullTime
+	)
+	if err := r.db.QueryRowContext(ctx, q, userID).Scan(
+		&m.UserID,
+		&m.Secret,
+		&enabledAt,
+		&m.LastUsedStep,
+		&m.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	if enabledAt.Valid {
+		m.EnabledAt = &enabledAt.Time
+	}
+	return &m, nil
+}
+
+// SavePending is a delete and insert rather than an upsert so the same SQL
+// runs on MySQL and PostgreSQL.
+func (r *mfaRepoMySQL) SavePending(ctx context.Context, userID int, secret string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = ? AND enabled_at IS NULL`, userID); err != nil {
+		return err
+	}
+
+	// Whatever is left is an enabled enrollment
+	var enabled int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_mfa WHERE user_id = ?`, userID).Scan(&enabled)
+	if err != nil {
+		return err
+	}
+	if enabled > 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err = tx.ExecContext(ctx,
+		`INSERT INTO user_mfa (user_id, secret, created_at) VALUES (?, ?, NOW())`,
+		userID, secret,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *mfaRepoMySQL) Enable(ctx context.Context, userID int, step int64) error {
+	const q = `
+		UPDATE user_mfa
+		SET enabled_at = NOW(), last_used_step = ?
+		WHERE user_id = ? AND enabled_at IS NULL
+	`
+
+	res, err := r.db.ExecContext(ctx, q, step, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *mfaRepoMySQL) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	const q = `
+		UPDATE user_mfa
+		SET last_used_step = ?
+		WHERE user_id = ? AND last_used_step < ?
+	`
+
+	res, err := r.db.ExecContext(ctx, q, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *mfaRepoMySQL) Delete(ctx context.Context, userID int) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *mfaRepoMySQL) ReplaceRecoveryCodes(ctx context.Context, userID int, hashes []string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		if _, err = tx.ExecContext(ctx,
+			`INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)`,
+			userID, hash, time.Now(),
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *mfaRepoMySQL) UseRecoveryCode(ctx context.Context, userID int, hash string) (bool, error) {
+	const q = `
+		UPDATE mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+	`
+
+	res, err := r.db.ExecContext(ctx, q, userID, hash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
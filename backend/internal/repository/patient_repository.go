@@ -11,7 +11,9 @@ import (
 // PatientRepository exposes helpers for working with the patients table.
 type PatientRepository interface {
 	GetByUserID(ctx context.Context, userID int64) (*domain.Patient, error)
+	GetByID(ctx context.Context, id int64) (*domain.Patient, error)
 	CreateForUser(ctx context.Context, userID int64) (*domain.Patient, error)
+	Update(ctx context.Context, p *domain.Patient) error
 }
 
 type patientRepoMySQL struct {
@@ -23,22 +25,33 @@ func NewPatientRepository(db *sql.DB) PatientRepository {
 }
 
 func (r *patientRepoMySQL) GetByUserID(ctx context.Context, userID int64) (*domain.Patient, error) {
-	const q = `
-        SELECT id, user_id, date_of_birth, phone, address, blood_type,
-               created_at, updated_at
-        FROM patients
-        WHERE user_id = ?
-    `
+	return r.getOne(ctx, "p.user_id = ?", userID)
+}
+
+func (r *patientRepoMySQL) GetByID(ctx context.Context, id int64) (*domain.Patient, error) {
+	return r.getOne(ctx, "p.id = ?", id)
+}
+
+// getOne loads a patient together with the name and email of its user.
+func (r *patientRepoMySQL) getOne(ctx context.Context, where string, arg any) (*domain.Patient, error) {
+	q := `
+        SELECT p.id, p.user_id, p.date_of_birth, p.phone, p.address, p.blood_type,
+               p.created_at, p.updated_at,
+               u.name, u.email
+        FROM patients p
+        JOIN users u ON u.id = p.user_id
+        WHERE ` + where
 
 	var (
 		patient   domain.Patient
+		user      domain.User
 		dob       sql.NullTime
 		phone     sql.NullString
 		address   sql.NullString
 		bloodType sql.NullString
 	)
 
-	err := r.db.QueryRowContext(ctx, q, userID).Scan(
+	err := r.db.QueryRowContext(ctx, q, arg).Scan(
 		&patient.ID,
 		&patient.UserID,
 		&dob,
@@ -47,13 +60,15 @@ func (r *patientRepoMySQL) GetByUserID(ctx context.Context, userID int64) (*doma
 		&bloodType,
 		&patient.CreatedAt,
 		&patient.UpdatedAt,
+		&user.Name,
+		&user.Email,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	if dob.Valid {
-		patient.DateOfBirth = dob.Time
+		patient.DateOfBirth = &dob.Time
 	}
 	if phone.Valid {
 		patient.Phone = phone.String
@@ -64,6 +79,9 @@ func (r *patientRepoMySQL) GetByUserID(ctx context.Context, userID int64) (*doma
 	if bloodType.Valid {
 		patient.BloodType = domain.BloodType(bloodType.String)
 	}
+	user.ID = patient.UserID
+	user.Role = domain.RolePatient
+	patient.User = &user
 
 	return &patient, nil
 }
@@ -93,3 +111,34 @@ func (r *patientRepoMySQL) CreateForUser(ctx context.Context, userID int64) (*do
 	}
 	return patient, nil
 }
+
+// Update stores the patient's demographics. Empty values are written as NULL.
+func (r *patientRepoMySQL) Update(ctx context.Context, p *domain.Patient) error {
+	const q = `
+        UPDATE patients
+        SET date_of_birth = ?, phone = ?, address = ?, blood_type = ?, updated_at = NOW()
+        WHERE id = ?
+    `
+
+	// MySQL reports 0 affected rows when nothing changed, so the caller is
+	// expected to have loaded the patient first
+	_, err := r.db.ExecContext(
+		ctx,
+		q,
+		p.DateOfBirth,
+		nullableString(p.Phone),
+		nullableString(p.Address),
+		nullableString(string(p.BloodType)),
+		p.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func nullableString(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+type RefreshTokenRepository interface {
+	CreateTx(ctx context.Context, tx *sql.Tx, t *domain.RefreshToken) error
+	GetByHashForUpdateTx(ctx context.Context, tx *sql.Tx, hash string) (*domain.RefreshToken, error)
+	GetByAccessJTI(ctx context.Context, jti string) (*domain.RefreshToken, error)
+	MarkRotatedTx(ctx context.Context, tx *sql.Tx, id int) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int) error
+}
+
+type refreshTokenRepoMySQL struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepoMySQL{db: db}
+}
+
+var _ RefreshTokenRepository = (*refreshTokenRepoMySQL)(nil)
+
+const refreshTokenColumns = `
+	id, user_id, family_id, token_hash, access_jti,
+	expires_at, rotated_at, revoked_at, created_at
+`
+
+func (r *refreshTokenRepoMySQL) CreateTx(ctx context.Context, tx *sql.Tx, t *domain.RefreshToken) error {
+	const q = `
+		INSERT INTO refresh_tokens
+			(user_id, family_id, token_hash, access_jti, expires_at, created_at)
+		VALUES
+			(?, ?, ?, ?, ?, NOW())
+	`
+
+	res, err := tx.ExecContext(ctx, q, t.UserID, t.FamilyID, t.TokenHash, t.AccessJTI, t.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	insertID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	t.ID = int(insertID)
+	t.CreatedAt = time.Now()
+	return nil
+}
+
+// GetByHashForUpdateTx locks the row so two refreshes with the same token
+// cannot both rotate it.
+func (r *refreshTokenRepoMySQL) GetByHashForUpdateTx(ctx context.Context, tx *sql.Tx, hash string) (*domain.RefreshToken, error) {
+	q := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token_hash = ? FOR UPDATE`
+	return scanRefreshToken(tx.QueryRowContext(ctx, q, hash))
+}
+
+func (r *refreshTokenRepoMySQL) GetByAccessJTI(ctx context.Context, jti string) (*domain.RefreshToken, error) {
+	q := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE access_jti = ?`
+	return scanRefreshToken(r.db.QueryRowContext(ctx, q, jti))
+}
+
+func (r *refreshTokenRepoMySQL) MarkRotatedTx(ctx context.Context, tx *sql.Tx, id int) error {
+	const q = `
+		UPDATE refresh_tokens
+		SET rotated_at = NOW()
+		WHERE id = ? AND rotated_at IS NULL
+	`
+
+	res, err := tx.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RevokeFamily ends a whole session: every refresh token of the family and
+// the access tokens issued with them stop working.
+func (r *refreshTokenRepoMySQL) RevokeFamily(ctx context.Context, familyID string) error {
+	const q = `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE family_id = ? AND revoked_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, q, familyID)
+	return err
+}
+
+func (r *refreshTokenRepoMySQL) RevokeAllForUser(ctx context.Context, userID int) error {
+	const q = `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE user_id = ? AND revoked_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, q, userID)
+	return err
+}
+
+func scanRefreshToken(row *sql.Row) (*domain.RefreshToken, error) {
+	var (
+		t         domain.RefreshToken
+		rotatedAt sql.NullTime
+		revokedAt sql.NullTime
+	)
+	if err := row.Scan(
+		&t.ID,
+		&t.UserID,
+		&t.FamilyID,
+		&t.TokenHash,
+		&t.AccessJTI,
+		&t.ExpiresAt,
+		&rotatedAt,
+		&revokedAt,
+		&t.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	if rotatedAt.Valid {
+		t.RotatedAt = &rotatedAt.Time
+	}
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+	return &t, nil
+}
@@ -10,6 +10,8 @@ import (
 type ScheduleRepository interface {
 	GetByID(ctx context.Context, id int64) (*domain.DoctorSchedule, error)
 	GetByIDForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*domain.DoctorSchedule, error)
+	GetByDoctorAndDayTx(ctx context.Context, tx *sql.Tx, doctorID int64, day domain.WorkDay) ([]domain.DoctorSchedule, error)
+	GetByDoctor(ctx context.Context, doctorID int64) ([]domain.DoctorSchedule, error)
 }
 
 type scheduleRepoMySQL struct {
@@ -42,4 +44,38 @@ func (r *scheduleRepoMySQL) GetByIDForUpdate(ctx context.Context, tx *sql.Tx, id
 		return nil, err
 	}
 	return &s, nil
-}
\ No newline at end of file
+}
+
+func (r *scheduleRepoMySQL) GetByDoctorAndDayTx(ctx context.Context, tx *sql.Tx, doctorID int64, day domain.WorkDay) ([]domain.DoctorSchedule, error) {
+	q := `SELECT id, doctor_id, work_day, start_time, end_time, patient_quota, created_at, updated_at
+	      FROM doctor_schedules WHERE doctor_id = ? AND work_day = ? ORDER BY start_time`
+	rows, err := tx.QueryContext(ctx, q, doctorID, day)
+	if err != nil {
+		return nil, err
+	}
+	return scanSchedules(rows)
+}
+
+func scanSchedules(rows *sql.Rows) ([]domain.DoctorSchedule, error) {
+	defer rows.Close()
+
+	var result []domain.DoctorSchedule
+	for rows.Next() {
+		var s domain.DoctorSchedule
+		if err := rows.Scan(&s.ID, &s.DoctorID, &s.WorkDay, &s.StartTime, &s.EndTime, &s.PatientQuota, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+func (r *scheduleRepoMySQL) GetByDoctor(ctx context.Context, doctorID int64) ([]domain.DoctorSchedule, error) {
+	q := `SELECT id, doctor_id, work_day, start_time, end_time, patient_quota, created_at, updated_at
+	      FROM doctor_schedules WHERE doctor_id = ? ORDER BY start_time`
+	rows, err := r.db.QueryContext(ctx, q, doctorID)
+	if err != nil {
+		return nil, err
+	}
+	return scanSchedules(rows)
+}
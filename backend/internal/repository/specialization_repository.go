@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/storage"
+)
+
+type SpecializationRepository interface {
+	GetAll(ctx context.Context) ([]domain.Specialization, error)
+	GetByID(ctx context.Context, id int) (domain.Specialization, error)
+	FindByName(ctx context.Context, name string) (domain.Specialization, error)
+	Create(ctx context.Context, specialization domain.Specialization) (domain.Specialization, error)
+	Update(ctx context.Context, specialization domain.Specialization) (domain.Specialization, error)
+	Delete(ctx context.Context, id int) error
+	CountDoctors(ctx context.Context, id int) (int, error)
+}
+
+type SpecializationRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewSpecializationRepository(db *sql.DB) SpecializationRepository {
+	return &SpecializationRepositoryImpl{DB: db}
+}
+
+func (repo *SpecializationRepositoryImpl) GetAll(ctx context.Context) ([]domain.Specialization, error) {
+	query := `
+		SELECT id, name, created_at, updated_at
+		FROM specializations
+		ORDER BY name ASC
+	`
+
+	rows, err := repo.DB.QueryContext(ctx, query)
+	if err != nil {
+		log.Println("ERROR getting specializations:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	specializations := []domain.Specialization{}
+	for rows.Next() {
+		var specialization domain.Specialization
+		if err := rows.Scan(
+			&specialization.ID, &specialization.Name,
+			&specialization.CreatedAt, &specialization.UpdatedAt,
+		); err != nil {
+			log.Println("ERROR scanning specialization:", err)
+			return nil, err
+		}
+		specializations = append(specializations, specialization)
+	}
+
+	return specializations, rows.Err()
+}
+
+func (repo *SpecializationRepositoryImpl) GetByID(ctx context.Context, id int) (domain.Specialization, error) {
+	query := `
+		SELECT id, name, created_at, updated_at
+		FROM specializations
+		WHERE id = ?
+	`
+
+	var specialization domain.Specialization
+	err := repo.DB.QueryRowContext(ctx, query, id).Scan(
+		&specialization.ID, &specialization.Name,
+		&specialization.CreatedAt, &specialization.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return specialization, errors.New("specialization not found")
+		}
+		log.Println("ERROR getting specialization by id:", err)
+		return specialization, err
+	}
+
+	return specialization, nil
+}
+
+func (repo *SpecializationRepositoryImpl) FindByName(ctx context.Context, name string) (domain.Specialization, error) {
+	query := `
+		SELECT id, name, created_at, updated_at
+		FROM specializations
+		WHERE name = ?
+	`
+
+	var specialization domain.Specialization
+	err := repo.DB.QueryRowContext(ctx, query, name).Scan(
+		&specialization.ID, &specialization.Name,
+		&specialization.CreatedAt, &specialization.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return specialization, errors.New("specialization not found")
+		}
+		log.Println("ERROR getting specialization by name:", err)
+		return specialization, err
+	}
+
+	return specialization, nil
+}
+
+func (repo *SpecializationRepositoryImpl) Create(ctx context.Context, specialization domain.Specialization) (domain.Specialization, error) {
+	result, err := repo.DB.ExecContext(ctx, "INSERT INTO specializations (name) VALUES (?)", specialization.Name)
+	if err != nil {
+		log.Println("ERROR creating specialization:", err)
+		return specialization, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Println("ERROR getting specialization id:", err)
+		return specialization, err
+	}
+
+	return repo.GetByID(ctx, int(id))
+}
+
+func (repo *SpecializationRepositoryImpl) Update(ctx context.Context, specialization domain.Specialization) (domain.Specialization, error) {
+	query := `
+		UPDATE specializations
+		SET name = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	if _, err := repo.DB.ExecContext(ctx, query, specialization.Name, specialization.ID); err != nil {
+		log.Println("ERROR updating specialization:", err)
+		return specialization, err
+	}
+
+	// Re-read so an unchanged name does not look like a missing row
+	return repo.GetByID(ctx, specialization.ID)
+}
+
+func (repo *SpecializationRepositoryImpl) Delete(ctx context.Context, id int) error {
+	result, err := repo.DB.ExecContext(ctx, "DELETE FROM specializations WHERE id = ?", id)
+	if err != nil {
+		// Blocked by the ON DELETE RESTRICT foreign key on doctors
+		if storage.IsRowReferenced(err) {
+			return errors.New("specialization is still used by doctors")
+		}
+		log.Println("ERROR deleting specialization:", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("ERROR getting rows affected:", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("specialization not found")
+	}
+
+	return nil
+}
+
+func (repo *SpecializationRepositoryImpl) CountDoctors(ctx context.Context, id int) (int, error) {
+	var count int
+	err := repo.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM doctors WHERE specialization_id = ?", id).Scan(&count)
+	if err != nil {
+		log.Println("ERROR counting doctors of specialization:", err)
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// TxRunner runs a unit of work in one transaction. The services go through it
+// instead of calling BeginTx themselves, so they can be tested against the
+// in-memory repositories in the memory package.
+type TxRunner interface {
+	// WithTx commits when fn returns nil and rolls back otherwise. The tx
+	// handed to fn is what the *Tx repository methods expect; it may be nil
+	// for implementations that do not need one.
+	WithTx(ctx context.Context, fn func(tx *sql.Tx) error) error
+}
+
+type sqlTxRunner struct {
+	db *sql.DB
+}
+
+func NewTxRunner(db *sql.DB) TxRunner {
+	return &sqlTxRunner{db: db}
+}
+
+var _ TxRunner = (*sqlTxRunner)(nil)
+
+func (r *sqlTxRunner) WithTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
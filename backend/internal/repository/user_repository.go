@@ -14,6 +14,8 @@ type UserRepository interface {
 	FindByEmail(ctx context.Context, email string) (domain.User, error)
 	FindByID(ctx context.Context, id int) (domain.User, error)
 	Update(ctx context.Context, user domain.User) (domain.User, error)
+	UpdatePassword(ctx context.Context, userID int, hashedPassword string) error
+	MarkEmailVerified(ctx context.Context, userID int) error
 }
 
 type UserRepositoryImpl struct {
@@ -67,13 +69,14 @@ func (repo *UserRepositoryImpl) Register(ctx context.Context, user domain.User)
 
 func (repo *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (domain.User, error) {
 	query := `
-		SELECT id, email, password, name, role, created_at, updated_at
+		SELECT id, email, password, name, role, email_verified_at, created_at, updated_at
 		FROM users
 		WHERE email = ?
 	`
 
 	var user domain.User
 	var profilePicture sql.NullString
+	var emailVerifiedAt sql.NullTime
 
 	err := repo.DB.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
@@ -81,6 +84,7 @@ func (repo *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (
 		&user.Password,
 		&user.Name,
 		&user.Role,
+		&emailVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -97,27 +101,41 @@ func (repo *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (
 	if profilePicture.Valid {
 		user.ProfilePicture = profilePicture.String
 	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
 
 	return user, nil
 }
 
 func (repo *UserRepositoryImpl) FindByID(ctx context.Context, id int) (domain.User, error) {
 	query := `
-		SELECT id, email, password, name, role, profile_picture, created_at, updated_at
+		SELECT id, email, password, name, role, profile_picture, email_verified_at, created_at, updated_at
 		FROM users
 		WHERE id = ?
 	`
 
 	var user domain.User
 	var profilePicture sql.NullString
+	var emailVerifiedAt sql.NullTime
 
-	err := repo.DB.QueryRowContext(ctx, query, id).Scan(
+	// Inside a transaction the read goes through it; on SQLite a second
+	// connection would wait for the write lock the transaction holds
+	var row *sql.Row
+	if tx, ok := ctx.Value("tx").(*sql.Tx); ok {
+		row = tx.QueryRowContext(ctx, query, id)
+	} else {
+		row = repo.DB.QueryRowContext(ctx, query, id)
+	}
+
+	err := row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.Password,
 		&user.Name,
 		&user.Role,
 		&profilePicture,
+		&emailVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -134,6 +152,9 @@ func (repo *UserRepositoryImpl) FindByID(ctx context.Context, id int) (domain.Us
 	if profilePicture.Valid {
 		user.ProfilePicture = profilePicture.String
 	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
 
 	return user, nil
 }
@@ -180,3 +201,60 @@ func (repo *UserRepositoryImpl) Update(ctx context.Context, user domain.User) (d
 
 	return user, nil
 }
+
+func (repo *UserRepositoryImpl) UpdatePassword(ctx context.Context, userID int, hashedPassword string) error {
+	query := `
+		UPDATE users
+		SET password = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	// Check if transaction is available in context
+	var result sql.Result
+	var err error
+	if tx, ok := ctx.Value("tx").(*sql.Tx); ok {
+		result, err = tx.ExecContext(ctx, query, hashedPassword, userID)
+	} else {
+		result, err = repo.DB.ExecContext(ctx, query, hashedPassword, userID)
+	}
+	if err != nil {
+		log.Println("ERROR UpdatePassword:", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Println("ERROR getting rows affected:", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// MarkEmailVerified stamps email_verified_at once; verifying again keeps the
+// first timestamp.
+func (repo *UserRepositoryImpl) MarkEmailVerified(ctx context.Context, userID int) error {
+	query := `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP)
+		WHERE id = ?
+	`
+
+	// Check if transaction is available in context
+	var err error
+	if tx, ok := ctx.Value("tx").(*sql.Tx); ok {
+		_, err = tx.ExecContext(ctx, query, userID)
+	} else {
+		_, err = repo.DB.ExecContext(ctx, query, userID)
+	}
+	if err != nil {
+		log.Println("ERROR MarkEmailVerified:", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, t *domain.UserToken) error
+	GetByHashForUpdateTx(ctx context.Context, tx *sql.Tx, purpose domain.TokenPurpose, hash string) (*domain.UserToken, error)
+	GetByHash(ctx context.Context, purpose domain.TokenPurpose, hash string) (*domain.UserToken, error)
+	MarkUsedTx(ctx context.Context, tx *sql.Tx, id int) error
+	MarkUsed(ctx context.Context, id int) error
+	RevokeUnused(ctx context.Context, userID int, purpose domain.TokenPurpose) error
+	HasUsed(ctx context.Context, userID int, purpose domain.TokenPurpose) (bool, error)
+}
+
+type userTokenRepoMySQL struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) UserTokenRepository {
+	return &userTokenRepoMySQL{db: db}
+}
+
+var _ UserTokenRepository = (*userTokenRepoMySQL)(nil)
+
+func (r *userTokenRepoMySQL) Create(ctx context.Context, t *domain.UserToken) error {
+	const q = `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, NOW())
+	`
+
+	res, err := r.db.ExecContext(ctx, q, t.UserID, t.Purpose, t.TokenHash, t.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	insertID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	t.ID = int(insertID)
+	t.CreatedAt = time.Now()
+	return nil
+}
+
+// GetByHashForUpdateTx locks the token row so it can be redeemed only once.
+func (r *userTokenRepoMySQL) GetByHashForUpdateTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	purpose domain.TokenPurpose,
+	hash string,
+) (*domain.UserToken, error) {
+	q := `SELECT ` + userTokenColumns + ` FROM user_tokens WHERE purpose = ? AND token_hash = ? FOR UPDATE`
+	return scanUserToken(tx.QueryRowContext(ctx, q, purpose, hash))
+}
+
+// GetByHash reads a token without locking it. Redeem it with MarkUsed, which
+// only succeeds once.
+func (r *userTokenRepoMySQL) GetByHash(ctx context.Context, purpose domain.TokenPurpose, hash string) (*domain.UserToken, error) {
+	q := `SELECT ` + userTokenColumns + ` FROM user_tokens WHERE purpose = ? AND token_hash = ?`
+	return scanUserToken(r.db.QueryRowContext(ctx, q, purpose, hash))
+}
+
+const userTokenColumns = `id, user_id, purpose, token_hash, expires_at, used_at, created_at`
+
+func scanUserToken(row *sql.Row) (*domain.UserToken, error) {
+	var (
+		t      domain.UserToken
+		usedAt sql.NullTime
+	)
+	if err := row.Scan(
+		&t.ID,
+		&t.UserID,
+		&t.Purpose,
+		&t.TokenHash,
+		&t.ExpiresAt,
+		&usedAt,
+		&t.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		t.UsedAt = &usedAt.Time
+	}
+	return &t, nil
+}
+
+func (r *userTokenRepoMySQL) MarkUsedTx(ctx context.Context, tx *sql.Tx, id int) error {
+	res, err := tx.ExecContext(ctx, markUserTokenUsed, id)
+	return usedResult(res, err)
+}
+
+func (r *userTokenRepoMySQL) MarkUsed(ctx context.Context, id int) error {
+	res, err := r.db.ExecContext(ctx, markUserTokenUsed, id)
+	return usedResult(res, err)
+}
+
+const markUserTokenUsed = `
+	UPDATE user_tokens
+	SET used_at = NOW()
+	WHERE id = ? AND used_at IS NULL
+`
+
+// usedResult turns an update that matched no unused token into sql.ErrNoRows.
+func usedResult(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RevokeUnused expires every outstanding token of the user for purpose, so
+// only the newest one handed out stays redeemable.
+func (r *userTokenRepoMySQL) RevokeUnused(ctx context.Context, userID int, purpose domain.TokenPurpose) error {
+	const q = `
+		UPDATE user_tokens
+		SET expires_at = NOW()
+		WHERE user_id = ? AND purpose = ? AND used_at IS NULL AND expires_at > NOW()
+	`
+
+	_, err := r.db.ExecContext(ctx, q, userID, purpose)
+	return err
+}
+
+func (r *userTokenRepoMySQL) HasUsed(ctx context.Context, userID int, purpose domain.TokenPurpose) (bool, error) {
+	const q = `
+		SELECT EXISTS(
+			SELECT 1 FROM user_tokens
+			WHERE user_id = ? AND purpose = ? AND used_at IS NOT NULL
+		)
+	`
+
+	var used bool
+	if err := r.db.QueryRowContext(ctx, q, userID, purpose).Scan(&used); err != nil {
+		return false, err
+	}
+	return used, nil
+}
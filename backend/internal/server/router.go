@@ -0,0 +1,275 @@
+// Package server builds the HTTP API of the medical record backend and runs
+// it with timeouts and a graceful shutdown. main only reads the environment;
+// tests and other programs mount the same API through NewRouter.
+package server
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/audit"
+	"github.com/JinXVIII/BE-Medical-Record/internal/auth"
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/handler"
+	"github.com/JinXVIII/BE-Medical-Record/internal/mailer"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+	"github.com/JinXVIII/BE-Medical-Record/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
+)
+
+// Deps is what the API is built from.
+type Deps struct {
+	DB    *sql.DB
+	Keys  *auth.KeySet
+	Mail  mailer.Mailer
+	Links service.AccountLinks
+	MFA   service.MFAConfig
+
+	// CORSOrigins are the browser origins allowed to call the API. Without
+	// any, cross-origin requests are not answered with CORS headers
+	CORSOrigins []string
+
+	// TrustProxy takes the client address from X-Forwarded-For. Only set it
+	// behind a reverse proxy, otherwise clients could spoof their address.
+	// Behind a proxy it is required: without it every client shares the
+	// proxy's address and the per-IP login limit backs off everyone at once
+	TrustProxy bool
+}
+
+// Services are the services the API is built on. The subcommands of main use
+// them without starting the server.
+type Services struct {
+	Sessions        service.SessionService
+	Accounts        service.AccountService
+	Invites         service.InviteService
+	LoginGuard      service.LoginGuard
+	Users           service.UserService
+	Audit           service.AuditService
+	Doctors         service.DoctorService
+	Specializations service.SpecializationService
+	Schedules       service.DoctorScheduleService
+	Patients        service.PatientService
+	MedicalRecords  service.MedicalRecordService
+}
+
+// NewServices wires the repositories on deps.DB into the services.
+func NewServices(deps Deps) *Services {
+	db := deps.DB
+
+	// Services run their transactions through txRunner
+	txRunner := repository.NewTxRunner(db)
+
+	// User Auth
+	userRepo := repository.NewUserRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	sessionService := service.NewSessionService(txRunner, refreshTokenRepo, userRepo, deps.Keys)
+	accountService := service.NewAccountService(txRunner, userTokenRepo, userRepo, sessionService, deps.Mail, deps.Links)
+
+	// Invites for accounts created by an admin
+	inviteService := service.NewInviteService(txRunner, userTokenRepo, userRepo)
+
+	// Login throttling and the login_events log
+	loginGuard := service.NewLoginGuard(repository.NewLoginEventRepository(db))
+
+	// Second factor, enforced for the roles in deps.MFA
+	mfaRepo := repository.NewMFARepository(db)
+	userService := service.NewUserService(userRepo, sessionService, accountService, inviteService, loginGuard, mfaRepo, userTokenRepo, deps.MFA)
+
+	// Audit trail of access to patient data
+	auditRepo := repository.NewAuditRepository(db)
+	auditor := audit.New(auditRepo)
+
+	// Doctors, their specializations and schedules
+	doctorRepo := repository.NewDoctorRepository(db)
+	doctorService := service.NewDoctorService(doctorRepo, userRepo, inviteService, auditor)
+	specializationService := service.NewSpecializationService(repository.NewSpecializationRepository(db))
+	scheduleService := service.NewDoctorScheduleService(repository.NewDoctorScheduleRepository(db), doctorRepo)
+
+	// Appointments and medical records
+	appointmentRepo := repository.NewAppointmentRepository(db)
+	patientRepo := repository.NewPatientRepository(db)
+	accessPolicy := service.NewAccessPolicy(doctorRepo, patientRepo, appointmentRepo)
+	patientService := service.NewPatientService(txRunner, appointmentRepo, patientRepo, repository.NewScheduleRepository(db), repository.NewAppointmentChangeRepository(db), accessPolicy, auditor)
+	medicalRecordService := service.NewMedicalRecordService(txRunner, repository.NewMedicalRecordRepository(db), appointmentRepo, patientRepo, auditor)
+
+	return &Services{
+		Sessions:        sessionService,
+		Accounts:        accountService,
+		Invites:         inviteService,
+		LoginGuard:      loginGuard,
+		Users:           userService,
+		Audit:           service.NewAuditService(auditRepo),
+		Doctors:         doctorService,
+		Specializations: specializationService,
+		Schedules:       scheduleService,
+		Patients:        patientService,
+		MedicalRecords:  medicalRecordService,
+	}
+}
+
+// NewRouter builds the services on deps and mounts the API on a router.
+func NewRouter(deps Deps) http.Handler {
+	return newRouter(deps, NewServices(deps))
+}
+
+func newRouter(deps Deps, svc *Services) http.Handler {
+	userHandler := handler.NewUserHandler(svc.Users)
+	accountHandler := handler.NewAccountHandler(svc.Accounts)
+	inviteHandler := handler.NewInviteHandler(svc.Invites)
+	loginEventHandler := handler.NewLoginEventHandler(svc.LoginGuard)
+	auditHandler := handler.NewAuditHandler(svc.Audit)
+	doctorHandler := handler.NewDoctorHandler(svc.Doctors)
+	doctorProfileHandler := handler.NewDoctorProfileHandler(svc.Doctors)
+	specializationHandler := handler.NewSpecializationHandler(svc.Specializations)
+	scheduleHandler := handler.NewDoctorScheduleHandler(svc.Schedules)
+	patientHandler := handler.NewPatientHandler(svc.Patients)
+	doctorAppointmentHandler := handler.NewDoctorAppointmentHandler(svc.Patients)
+	medicalRecordHandler := handler.NewMedicalRecordHandler(svc.MedicalRecords)
+
+	r := chi.NewRouter()
+	if deps.TrustProxy {
+		r.Use(middleware.RealIP)
+	}
+	r.Use(middleware.RequestID)
+	r.Use(audit.Middleware)
+	r.Use(middleware.Logger)
+	// cors reads an empty origin list as "allow every origin"
+	if len(deps.CORSOrigins) > 0 {
+		r.Use(cors.Handler(cors.Options{
+			AllowedOrigins:   deps.CORSOrigins,
+			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+			ExposedHeaders:   []string{"Link"},
+			AllowCredentials: true,
+			MaxAge:           300,
+		}))
+	}
+
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello World!"))
+	})
+
+	r.Get("/.well-known/jwks.json", handler.NewJWKSHandler(deps.Keys).GetJWKS)
+
+	r.Route("/api", func(r chi.Router) {
+		// User endpoints
+		r.Post("/register", userHandler.Register)
+		r.Post("/login", userHandler.Login)
+		r.Post("/login/mfa", userHandler.LoginMFA)                             // Exchange the MFA challenge and a code for tokens
+		r.Post("/login/mfa/enroll", userHandler.LoginMFAEnroll)                // Enroll during login when the role requires MFA
+		r.Post("/token/refresh", userHandler.RefreshToken)                     // Rotate refresh token
+		r.Post("/invite/accept", inviteHandler.AcceptInvite)                   // Invited user sets a password
+		r.Post("/password/forgot", accountHandler.ForgotPassword)              // Mail a reset link
+		r.Post("/password/reset", accountHandler.ResetPassword)                // Set a new password with the reset token
+		r.Get("/verify-email", accountHandler.VerifyEmail)                     // Link from the verification mail
+		r.Get("/specializations", specializationHandler.GetAllSpecializations) // Filter values for doctor search
+
+		r.Group(func(r chi.Router) {
+			r.Use(auth.Authenticate(deps.Keys, svc.Sessions))
+
+			r.Post("/logout", userHandler.Logout) // Revoke this session (or all with {"all": true})
+
+			// Two-factor authentication of the logged in user
+			r.Route("/mfa", func(r chi.Router) {
+				r.Post("/enroll", userHandler.EnrollMFA)   // New TOTP secret and otpauth URI
+				r.Post("/confirm", userHandler.ConfirmMFA) // Enable with a first code, returns recovery codes
+				r.Post("/disable", userHandler.DisableMFA) // Not allowed for roles that require MFA
+			})
+
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(auth.RequireRole(domain.RoleAdmin))
+
+				r.Post("/users", userHandler.CreateUser)                 // Create an admin or patient account with an invite
+				r.Get("/login-events", loginEventHandler.GetLoginEvents) // Failed and successful login attempts
+				r.Get("/audit", auditHandler.GetAuditEvents)             // Who viewed or changed patient data
+				r.Get("/audit/export", auditHandler.ExportAuditEvents)   // Same filters, streamed as NDJSON
+
+				// Doctor Management (admins only)
+				r.Route("/doctors", func(r chi.Router) {
+					r.Get("/", doctorHandler.GetAllDoctors)            // List doctors
+					r.Post("/", doctorHandler.CreateDoctor)            // Create doctor and invite
+					r.Get("/{id}", doctorHandler.GetDoctorByID)        // Get doctor
+					r.Put("/{id}", doctorHandler.UpdateDoctor)         // Update doctor
+					r.Delete("/{id}", doctorHandler.DeleteDoctor)      // Delete doctor
+					r.Post("/{id}/invite", doctorHandler.ResendInvite) // Resend invite
+				})
+
+				// Specialization Management (admins only)
+				r.Route("/specializations", func(r chi.Router) {
+					r.Get("/", specializationHandler.GetAllSpecializations)       // List specializations
+					r.Post("/", specializationHandler.CreateSpecialization)       // Create specialization
+					r.Get("/{id}", specializationHandler.GetSpecializationByID)   // Get specialization
+					r.Put("/{id}", specializationHandler.UpdateSpecialization)    // Rename specialization
+					r.Delete("/{id}", specializationHandler.DeleteSpecialization) // Delete unused specialization
+				})
+			})
+
+			r.Route("/doctor", func(r chi.Router) {
+				r.Use(auth.RequireDoctorProfile(svc.Doctors))
+
+				// Doctor Profile
+				r.Route("/profile", func(r chi.Router) {
+					r.Get("/", doctorProfileHandler.GetMyProfile)    // Get my profile
+					r.Put("/", doctorProfileHandler.UpdateMyProfile) // Update my profile
+				})
+
+				// Doctor Schedules (doctors only)
+				r.Route("/schedules", func(r chi.Router) {
+					r.Get("/", scheduleHandler.GetMySchedules)        // Get my schedules
+					r.Post("/", scheduleHandler.CreateSchedule)       // Create schedule
+					r.Put("/{id}", scheduleHandler.UpdateSchedule)    // Update schedule
+					r.Delete("/{id}", scheduleHandler.DeleteSchedule) // Delete schedule
+				})
+
+				// Demographics of patients who booked this doctor
+				r.Get("/patients/{id}", doctorAppointmentHandler.GetPatient)
+
+				r.Route("/appointments", func(r chi.Router) {
+					r.Get("/", doctorAppointmentHandler.GetAppointments)
+					r.Patch("/{id}", doctorAppointmentHandler.UpdateStatus)
+					r.Patch("/{id}/reschedule", doctorAppointmentHandler.Reschedule)
+
+					// Medical record of an appointment (writing it completes the appointment)
+					r.Route("/{id}/record", func(r chi.Router) {
+						r.Get("/", medicalRecordHandler.GetRecord)
+						r.Post("/", medicalRecordHandler.CreateRecord)
+						r.Put("/", medicalRecordHandler.UpdateRecord)
+					})
+				})
+			})
+
+			r.Route("/patient", func(r chi.Router) {
+				patientOnly := auth.RequireRole(domain.RolePatient)
+
+				// Patient Profile
+				r.Route("/profile", func(r chi.Router) {
+					r.Use(patientOnly)
+					r.Get("/", patientHandler.GetProfile)    // Get my profile
+					r.Put("/", patientHandler.UpdateProfile) // Update my demographics
+				})
+
+				r.Route("/doctors", func(r chi.Router) {
+					r.Get("/{id}/schedules", scheduleHandler.GetDoctorSchedules)      //Get Schedule
+					r.Get("/{id}/availability", patientHandler.GetDoctorAvailability) // Bookable slots
+					r.Get("/search", doctorHandler.SearchDoctors)                     // Search Doctors
+				})
+
+				r.Route("/appointments", func(r chi.Router) {
+					r.With(patientOnly).Get("/", patientHandler.GetAppointments)                // Get Appointment
+					r.With(patientOnly).Post("/", patientHandler.CreateAppointment)             // Create Appointment
+					r.Get("/{id}", patientHandler.GetAppointmentDetail)                         // Get Appointment detail (owner, doctor or admin)
+					r.With(patientOnly).Patch("/{id}/cancel", patientHandler.CancelAppointment) // Canceled Appointment
+					r.With(patientOnly).Patch("/{id}/reschedule", patientHandler.Reschedule)    // Move to another slot
+				})
+
+				r.With(patientOnly).Get("/records", medicalRecordHandler.GetMyRecords) // Medical history timeline
+			})
+
+		})
+	})
+
+	return r
+}
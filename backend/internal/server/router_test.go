@@ -0,0 +1,691 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/auth"
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/mailer"
+	"github.com/JinXVIII/BE-Medical-Record/internal/service"
+	"github.com/JinXVIII/BE-Medical-Record/internal/storage"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/totp"
+	"github.com/go-chi/chi/v5"
+)
+
+// testAPI is the router of NewRouter on a fresh in-memory SQLite database
+// seeded with the demo dataset.
+type testAPI struct {
+	server *httptest.Server
+	router http.Handler
+	mail   *testMailer
+}
+
+// defaultMFA is what main configures without MFA_REQUIRED_ROLES.
+var defaultMFA = service.MFAConfig{
+	Issuer:        "Medical Record Test",
+	RequiredRoles: []domain.UserRole{domain.RoleAdmin, domain.RoleDoctor},
+}
+
+// newTestAPI starts the API with no role needing a second factor, so the
+// demo doctors and the admin log in with their password alone.
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	return newTestAPIWithMFA(t, service.MFAConfig{Issuer: "Medical Record Test"})
+}
+
+func newTestAPIWithMFA(t *testing.T, mfa service.MFAConfig) *testAPI {
+	t.Helper()
+
+	t.Setenv("DB_URL", "sqlite://:memory:")
+	db, err := storage.GetConnection()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := storage.InitializeDatabase(db, true); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := storage.Seed(context.Background(), db, storage.DatasetDemo, storage.DefaultSeedOptions()); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	keys, err := auth.LoadKeySet(auth.KeyConfig{HMACSecret: "integration-test-secret"})
+	if err != nil {
+		t.Fatalf("load keys: %v", err)
+	}
+	mail := &testMailer{}
+	router := NewRouter(Deps{
+		DB:   db,
+		Keys: keys,
+		Mail: mail,
+		Links: service.AccountLinks{
+			VerifyEmailURL:   "http://api.test/api/verify-email",
+			ResetPasswordURL: "http://app.test/reset-password",
+		},
+		MFA: mfa,
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return &testAPI{server: server, router: router, mail: mail}
+}
+
+// response is a finished request. Bodies are decoded lazily since some
+// handlers answer in plain text.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func (a *testAPI) call(t *testing.T, method, path, token string, body any) response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, a.server.URL+path, reader)
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := a.server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return response{status: res.StatusCode, header: res.Header, body: data}
+}
+
+// expect fails the test unless the request ended with status.
+func (a *testAPI) expect(t *testing.T, status int, method, path, token string, body any) response {
+	t.Helper()
+
+	res := a.call(t, method, path, token, body)
+	if res.status != status {
+		t.Fatalf("%s %s = %d, want %d: %s", method, path, res.status, status, res.body)
+	}
+	return res
+}
+
+func (a *testAPI) login(t *testing.T, email, password string) (access, refresh string) {
+	t.Helper()
+
+	res := a.expect(t, http.StatusOK, "POST", "/api/login", "", map[string]string{"email": email, "password": password})
+	return res.str(t, "data.token"), res.str(t, "data.refresh_token")
+}
+
+// field looks path up in the JSON body, e.g. "data.doctor.id" or
+// "data.0.status".
+func (r response) field(path string) (any, bool) {
+	var v any
+	if err := json.Unmarshal(r.body, &v); err != nil {
+		return nil, false
+	}
+	if path == "" {
+		return v, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = node[key]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func (r response) str(t *testing.T, path string) string {
+	t.Helper()
+
+	v, ok := r.field(path)
+	s, isString := v.(string)
+	if !ok || !isString || s == "" {
+		t.Fatalf("%s is not a non-empty string in %s", path, r.body)
+	}
+	return s
+}
+
+func (r response) id(t *testing.T, path string) int {
+	t.Helper()
+
+	v, ok := r.field(path)
+	n, isNumber := v.(float64)
+	if !ok || !isNumber || n <= 0 {
+		t.Fatalf("%s is not an id in %s", path, r.body)
+	}
+	return int(n)
+}
+
+func (r response) length(t *testing.T, path string) int {
+	t.Helper()
+
+	v, ok := r.field(path)
+	list, isList := v.([]any)
+	if !ok || !isList {
+		t.Fatalf("%s is not a list in %s", path, r.body)
+	}
+	return len(list)
+}
+
+// testMailer keeps the messages instead of sending them.
+type testMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+func (m *testMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *testMailer) lastToken(t *testing.T) string {
+	t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sent) == 0 {
+		t.Fatal("no mail was sent")
+	}
+	_, rest, ok := strings.Cut(m.sent[len(m.sent)-1].Body, "?token=")
+	if !ok {
+		t.Fatal("last mail has no token link")
+	}
+	token, _, _ := strings.Cut(rest, "\n")
+	return token
+}
+
+// nextWeekday returns the next date after today falling on day as
+// YYYY-MM-DD.
+func nextWeekday(day time.Weekday) string {
+	date := time.Now().AddDate(0, 0, 1)
+	for date.Weekday() != day {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date.Format("2006-01-02")
+}
+
+const (
+	adminEmail   = "admin@hospital.com"
+	doctorEmail  = "john.smith@hospital.com"
+	patientEmail = "patient1@email.com"
+)
+
+// TestEndpoints checks single requests against the demo data: who may call
+// what, how bad input is answered and the shape of the responses.
+func TestEndpoints(t *testing.T) {
+	api := newTestAPI(t)
+	tokens := map[string]string{}
+	tokens["admin"], _ = api.login(t, adminEmail, "admin123")
+	tokens["doctor"], _ = api.login(t, doctorEmail, "doctor123")
+	tokens["patient"], _ = api.login(t, patientEmail, "patient123")
+
+	tests := []struct {
+		name   string
+		as     string
+		method string
+		path   string
+		body   any
+		status int
+		fields []string
+	}{
+		// Auth
+		{"register", "", "POST", "/api/register", map[string]string{"name": "pasien", "email": "pasien@gmail.com", "password": "password"}, http.StatusCreated, []string{"message", "data"}},
+		{"register with a taken email", "", "POST", "/api/register", map[string]string{"name": "Patient One", "email": patientEmail, "password": "password"}, http.StatusConflict, []string{"message"}},
+		{"register without a password", "", "POST", "/api/register", map[string]string{"name": "pasien", "email": "new@gmail.com"}, http.StatusBadRequest, []string{"message", "data"}},
+		{"login", "", "POST", "/api/login", map[string]string{"email": doctorEmail, "password": "doctor123"}, http.StatusOK, []string{"data.token", "data.refresh_token", "data.expires_at", "data.user.role"}},
+		{"login with a wrong password", "", "POST", "/api/login", map[string]string{"email": doctorEmail, "password": "wrong-password"}, http.StatusUnauthorized, []string{"message"}},
+		{"refresh an unknown token", "", "POST", "/api/token/refresh", map[string]string{"refresh_token": "unknown"}, http.StatusUnauthorized, []string{"message"}},
+		{"public specializations", "", "GET", "/api/specializations", nil, http.StatusOK, []string{"data.0.id", "data.0.name"}},
+		{"jwks", "", "GET", "/.well-known/jwks.json", nil, http.StatusOK, []string{"keys"}},
+
+		// Authentication and roles
+		{"no token", "", "GET", "/api/admin/doctors", nil, http.StatusUnauthorized, []string{"message"}},
+		{"patient on admin routes", "patient", "GET", "/api/admin/doctors", nil, http.StatusForbidden, []string{"message"}},
+		{"doctor on admin routes", "doctor", "GET", "/api/admin/doctors", nil, http.StatusForbidden, []string{"message"}},
+		{"patient on doctor routes", "patient", "GET", "/api/doctor/schedules", nil, http.StatusForbidden, []string{"message"}},
+		{"doctor booking", "doctor", "POST", "/api/patient/appointments", map[string]any{"doctor_id": 1}, http.StatusForbidden, []string{"message"}},
+
+		// Admin
+		{"list doctors", "admin", "GET", "/api/admin/doctors", nil, http.StatusOK, []string{"data.0.id", "data.0.user.name", "data.0.license_number"}},
+		{"get a doctor", "admin", "GET", "/api/admin/doctors/1", nil, http.StatusOK, []string{"data.id", "data.user.email", "data.specialization.name"}},
+		{"get a missing doctor", "admin", "GET", "/api/admin/doctors/999", nil, http.StatusNotFound, []string{"message"}},
+		{"create a doctor with a taken email", "admin", "POST", "/api/admin/doctors", map[string]any{"name": "Dr. Jin", "email": doctorEmail, "specialization_id": 1, "gender": "male", "license_number": "DOC999"}, http.StatusConflict, []string{"message"}},
+		{"create a doctor with a bad gender", "admin", "POST", "/api/admin/doctors", map[string]any{"name": "Dr. Jin", "email": "jin@hospital.com", "specialization_id": 1, "gender": "other", "license_number": "DOC999"}, http.StatusBadRequest, []string{"message"}},
+		{"delete a specialization in use", "admin", "DELETE", "/api/admin/specializations/1", nil, http.StatusConflict, []string{"message"}},
+		{"audit trail", "admin", "GET", "/api/admin/audit", nil, http.StatusOK, []string{"data"}},
+		{"audit export", "admin", "GET", "/api/admin/audit/export", nil, http.StatusOK, nil},
+		{"login events", "admin", "GET", "/api/admin/login-events", nil, http.StatusOK, []string{"data.0.email"}},
+		{"login events up to today", "admin", "GET", "/api/admin/login-events?to=" + time.Now().UTC().Format("2006-01-02"), nil, http.StatusOK, []string{"data.0.email"}},
+
+		// Doctor
+		{"own profile", "doctor", "GET", "/api/doctor/profile", nil, http.StatusOK, []string{"data.id", "data.user.name"}},
+		{"own schedules", "doctor", "GET", "/api/doctor/schedules", nil, http.StatusOK, []string{"data.0.work_day", "data.0.start_time", "data.0.patient_quota"}},
+		{"schedule with a bad day", "doctor", "POST", "/api/doctor/schedules", map[string]any{"work_day": "someday", "start_time": "09:00", "end_time": "12:00", "patient_quota": 10}, http.StatusBadRequest, []string{"message"}},
+		{"no appointments yet", "doctor", "GET", "/api/doctor/appointments", nil, http.StatusOK, []string{"message"}},
+
+		// Patient
+		{"own profile", "patient", "GET", "/api/patient/profile", nil, http.StatusOK, []string{"blood_type", "user.name"}},
+		{"search doctors", "patient", "GET", "/api/patient/doctors/search?q=john", nil, http.StatusOK, []string{"data.0.id", "data.0.user.name"}},
+		{"doctor schedules", "patient", "GET", "/api/patient/doctors/1/schedules", nil, http.StatusOK, []string{"data.0.work_day"}},
+		{"doctor availability", "patient", "GET", "/api/patient/doctors/1/availability", nil, http.StatusOK, []string{""}},
+		{"booking with a bad date", "patient", "POST", "/api/patient/appointments", map[string]any{"doctor_id": 1, "appointment_date": "next monday", "start_time_slot": "09:00"}, http.StatusBadRequest, nil},
+		{"booking on a day off", "patient", "POST", "/api/patient/appointments", map[string]any{"doctor_id": 1, "appointment_date": nextWeekday(time.Sunday), "start_time_slot": "09:00"}, http.StatusUnprocessableEntity, nil},
+		{"booking outside the hours", "patient", "POST", "/api/patient/appointments", map[string]any{"doctor_id": 1, "appointment_date": nextWeekday(time.Monday), "start_time_slot": "13:00"}, http.StatusUnprocessableEntity, nil},
+		{"missing appointment", "patient", "GET", "/api/patient/appointments/999", nil, http.StatusNotFound, nil},
+		{"empty medical history", "patient", "GET", "/api/patient/records", nil, http.StatusOK, []string{"data"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.as+" "+tt.name, func(t *testing.T) {
+			res := api.expect(t, tt.status, tt.method, tt.path, tokens[tt.as], tt.body)
+			for _, path := range tt.fields {
+				if _, ok := res.field(path); !ok {
+					t.Errorf("response has no %q: %s", path, res.body)
+				}
+			}
+		})
+	}
+}
+
+// TestAppointmentLifecycle books an appointment and follows it through the
+// doctor confirming it, writing the medical record and the patient reading
+// their history.
+func TestAppointmentLifecycle(t *testing.T) {
+	api := newTestAPI(t)
+	patient, _ := api.login(t, patientEmail, "patient123")
+	other, _ := api.login(t, "patient2@email.com", "patient123")
+	doctor, _ := api.login(t, doctorEmail, "doctor123")
+
+	search := api.expect(t, http.StatusOK, "GET", "/api/patient/doctors/search?q=john", patient, nil)
+	doctorID := search.id(t, "data.0.id")
+
+	monday := nextWeekday(time.Monday)
+	booked := api.expect(t, http.StatusCreated, "POST", "/api/patient/appointments", patient, map[string]any{
+		"doctor_id":        doctorID,
+		"appointment_date": monday,
+		"start_time_slot":  "09:30",
+		"complaint":        "Demam tiga hari",
+	})
+	appointmentID := booked.id(t, "id")
+	if status := booked.str(t, "status"); status != "Pending" {
+		t.Fatalf("new appointment is %s, want Pending", status)
+	}
+	booked.id(t, "schedule_id")
+	path := fmt.Sprintf("/api/patient/appointments/%d", appointmentID)
+
+	history := api.expect(t, http.StatusOK, "GET", "/api/patient/appointments", patient, nil)
+	if n := history.length(t, ""); n != 1 {
+		t.Fatalf("patient has %d appointments, want 1", n)
+	}
+	history.str(t, "0.doctor.user.name")
+
+	// Only the patient, the doctor and admins see it
+	api.expect(t, http.StatusOK, "GET", path, doctor, nil)
+	api.expect(t, http.StatusNotFound, "GET", path, other, nil)
+	api.expect(t, http.StatusForbidden, "PATCH", path+"/cancel", other, nil)
+
+	listed := api.expect(t, http.StatusOK, "GET", "/api/doctor/appointments", doctor, nil)
+	if id := listed.id(t, "data.0.id"); id != appointmentID {
+		t.Fatalf("doctor sees appointment %d, want %d", id, appointmentID)
+	}
+	listed.str(t, "data.0.patient.name")
+
+	// The record can only be written once the appointment is confirmed
+	recordPath := fmt.Sprintf("/api/doctor/appointments/%d/record", appointmentID)
+	record := map[string]string{"diagnosis": "Influenza", "prescription": "Paracetamol 3x1"}
+	api.expect(t, http.StatusConflict, "POST", recordPath, doctor, record)
+	api.expect(t, http.StatusBadRequest, "PATCH", fmt.Sprintf("/api/doctor/appointments/%d", appointmentID), doctor, map[string]string{"status": "Lost"})
+	api.expect(t, http.StatusOK, "PATCH", fmt.Sprintf("/api/doctor/appointments/%d", appointmentID), doctor, map[string]string{"status": "Confirmed"})
+	api.expect(t, http.StatusConflict, "PATCH", fmt.Sprintf("/api/doctor/appointments/%d", appointmentID), doctor, map[string]string{"status": "Completed"})
+
+	// The patient moves it to the afternoon, which is another schedule
+	moved := api.expect(t, http.StatusOK, "PATCH", path+"/reschedule", patient, map[string]string{
+		"appointment_date": monday,
+		"start_time_slot":  "15:00",
+		"reason":           "Ada rapat pagi",
+	})
+	if slot := moved.str(t, "start_time_slot"); !strings.HasPrefix(slot, "15:00") {
+		t.Errorf("rescheduled to %s, want 15:00", slot)
+	}
+	detail := api.expect(t, http.StatusOK, "GET", path, patient, nil)
+	if n := detail.length(t, "changes"); n != 1 {
+		t.Errorf("%d changes recorded, want 1", n)
+	}
+
+	created := api.expect(t, http.StatusCreated, "POST", recordPath, doctor, record)
+	created.id(t, "data.id")
+	api.expect(t, http.StatusConflict, "POST", recordPath, doctor, record)
+	api.expect(t, http.StatusOK, "PUT", recordPath, doctor, map[string]string{"diagnosis": "Dengue", "doctor_notes": "Cek trombosit"})
+
+	completed := api.expect(t, http.StatusOK, "GET", path, patient, nil)
+	if status := completed.str(t, "status"); status != "Completed" {
+		t.Errorf("appointment is %s after the record was written, want Completed", status)
+	}
+	api.expect(t, http.StatusConflict, "PATCH", path+"/cancel", patient, nil)
+
+	records := api.expect(t, http.StatusOK, "GET", "/api/patient/records", patient, nil)
+	if n := records.length(t, "data"); n != 1 {
+		t.Fatalf("patient has %d records, want 1", n)
+	}
+	if diagnosis := records.str(t, "data.0.diagnosis"); diagnosis != "Dengue" {
+		t.Errorf("diagnosis = %s, want the amended Dengue", diagnosis)
+	}
+	records.str(t, "data.0.appointment.doctor.specialization.name")
+
+	// The doctor may now read the patient's demographics
+	patientID := booked.id(t, "patient_id")
+	api.expect(t, http.StatusOK, "GET", fmt.Sprintf("/api/doctor/patients/%d", patientID), doctor, nil)
+}
+
+// TestPatientProfileUpdate sends the seeded profile back and checks how the
+// update request is validated.
+func TestPatientProfileUpdate(t *testing.T) {
+	api := newTestAPI(t)
+	patient, _ := api.login(t, patientEmail, "patient123")
+
+	// The profile as read, with the date of birth in the request format
+	current := api.expect(t, http.StatusOK, "GET", "/api/patient/profile", patient, nil)
+	unchanged := map[string]string{
+		"date_of_birth": current.str(t, "date_of_birth")[:len("2006-01-02")],
+		"phone":         current.str(t, "phone"),
+		"address":       current.str(t, "address"),
+		"blood_type":    current.str(t, "blood_type"),
+	}
+	api.expect(t, http.StatusOK, "PUT", "/api/patient/profile", patient, unchanged)
+
+	// Validation errors are keyed by the request's field name
+	bad := map[string]map[string]string{
+		"DateOfBirth": {"date_of_birth": "15-01-1990"},
+		"Phone":       {"phone": "555-0101"},
+		"BloodType":   {"blood_type": "C+"},
+	}
+	for field, body := range bad {
+		res := api.expect(t, http.StatusBadRequest, "PUT", "/api/patient/profile", patient, body)
+		if _, ok := res.field(field); !ok {
+			t.Errorf("invalid %s not reported: %s", field, res.body)
+		}
+	}
+	api.expect(t, http.StatusBadRequest, "PUT", "/api/patient/profile", patient, map[string]string{"date_of_birth": "2999-01-01"})
+
+	// Empty fields are cleared
+	cleared := api.expect(t, http.StatusOK, "PUT", "/api/patient/profile", patient, map[string]string{"address": "Jl. Merdeka 1"})
+	for _, field := range []string{"date_of_birth", "phone", "blood_type"} {
+		if v, _ := cleared.field(field); v != nil && v != "" {
+			t.Errorf("%s = %v after sending it empty, want it cleared", field, v)
+		}
+	}
+	if address := cleared.str(t, "address"); address != "Jl. Merdeka 1" {
+		t.Errorf("address = %s, want Jl. Merdeka 1", address)
+	}
+}
+
+// TestDoctorOnboarding follows the Admin and Doctor folders of the Postman
+// collection: an admin creates a doctor, who accepts the invite and manages
+// their schedule.
+func TestDoctorOnboarding(t *testing.T) {
+	api := newTestAPI(t)
+	admin, _ := api.login(t, adminEmail, "admin123")
+
+	created := api.expect(t, http.StatusCreated, "POST", "/api/admin/doctors", admin, map[string]any{
+		"name":              "Dr. Jin",
+		"email":             "doctor@hospital.com",
+		"specialization_id": 1,
+		"gender":            "male",
+		"address":           "123 Main Tolitoli",
+		"license_number":    "DOC100",
+	})
+	doctorID := created.id(t, "data.doctor.id")
+	invite := created.str(t, "data.invite.token")
+
+	// No password is set until the invite is accepted
+	if res := api.call(t, "POST", "/api/login", "", map[string]string{"email": "doctor@hospital.com", "password": "password"}); res.status != http.StatusUnauthorized {
+		t.Fatalf("login before accepting the invite = %d, want 401", res.status)
+	}
+	api.expect(t, http.StatusOK, "POST", "/api/invite/accept", "", map[string]string{"token": invite, "password": "password"})
+	api.expect(t, http.StatusBadRequest, "POST", "/api/invite/accept", "", map[string]string{"token": invite, "password": "password"})
+
+	api.expect(t, http.StatusOK, "PUT", fmt.Sprintf("/api/admin/doctors/%d", doctorID), admin, map[string]any{
+		"name":              "Dr. Jin",
+		"email":             "jin@hospital.com",
+		"role":              "doctor",
+		"specialization_id": 2,
+		"gender":            "male",
+		"address":           "123 Main Tolitoli",
+		"license_number":    "DOC100",
+	})
+	doctor, _ := api.login(t, "jin@hospital.com", "password")
+
+	schedule := api.expect(t, http.StatusCreated, "POST", "/api/doctor/schedules", doctor, map[string]any{
+		"work_day":      "monday",
+		"start_time":    "09:00",
+		"end_time":      "12:00",
+		"patient_quota": 10,
+	})
+	scheduleID := schedule.id(t, "data.id")
+	schedulePath := fmt.Sprintf("/api/doctor/schedules/%d", scheduleID)
+
+	updated := api.expect(t, http.StatusOK, "PUT", schedulePath, doctor, map[string]any{
+		"work_day":      "monday",
+		"start_time":    "08:00",
+		"end_time":      "11:00",
+		"patient_quota": 20,
+	})
+	if quota := updated.id(t, "data.patient_quota"); quota != 20 {
+		t.Errorf("patient_quota = %d after the update, want 20", quota)
+	}
+
+	// Another doctor's schedule is off limits
+	john, _ := api.login(t, doctorEmail, "doctor123")
+	if res := api.call(t, "DELETE", schedulePath, john, nil); res.status == http.StatusOK {
+		t.Fatal("a doctor deleted another doctor's schedule")
+	}
+
+	api.expect(t, http.StatusOK, "DELETE", schedulePath, doctor, nil)
+	mine := api.expect(t, http.StatusOK, "GET", "/api/doctor/schedules", doctor, nil)
+	if n := mine.length(t, "data"); n != 0 {
+		t.Errorf("%d schedules left after deleting the only one", n)
+	}
+
+	api.expect(t, http.StatusOK, "DELETE", fmt.Sprintf("/api/admin/doctors/%d", doctorID), admin, nil)
+	api.expect(t, http.StatusNotFound, "GET", fmt.Sprintf("/api/admin/doctors/%d", doctorID), admin, nil)
+}
+
+// TestSessions covers refresh token rotation, reuse detection and logout.
+func TestSessions(t *testing.T) {
+	api := newTestAPI(t)
+	access, refresh := api.login(t, patientEmail, "patient123")
+
+	rotated := api.expect(t, http.StatusOK, "POST", "/api/token/refresh", "", map[string]string{"refresh_token": refresh})
+	newAccess := rotated.str(t, "data.token")
+	api.expect(t, http.StatusOK, "GET", "/api/patient/profile", newAccess, nil)
+
+	// Replaying the rotated token ends the whole session
+	api.expect(t, http.StatusUnauthorized, "POST", "/api/token/refresh", "", map[string]string{"refresh_token": refresh})
+	api.expect(t, http.StatusUnauthorized, "GET", "/api/patient/profile", newAccess, nil)
+	api.expect(t, http.StatusUnauthorized, "GET", "/api/patient/profile", access, nil)
+
+	access, _ = api.login(t, patientEmail, "patient123")
+	api.expect(t, http.StatusOK, "POST", "/api/logout", access, nil)
+	api.expect(t, http.StatusUnauthorized, "GET", "/api/patient/profile", access, nil)
+}
+
+// TestMFALogin runs the login of a doctor under the default configuration,
+// where admins and doctors must use a second factor: the first login enrolls
+// an authenticator, later ones ask for a code or a recovery code.
+func TestMFALogin(t *testing.T) {
+	api := newTestAPIWithMFA(t, defaultMFA)
+
+	// Patients are not asked for a second factor
+	api.login(t, patientEmail, "patient123")
+
+	first := api.expect(t, http.StatusOK, "POST", "/api/login", "", map[string]string{"email": doctorEmail, "password": "doctor123"})
+	if enroll, _ := first.field("data.mfa_enrollment_required"); enroll != true {
+		t.Fatalf("first doctor login does not ask to enroll: %s", first.body)
+	}
+	if _, ok := first.field("data.token"); ok {
+		t.Fatalf("first doctor login handed out a token before the second factor: %s", first.body)
+	}
+	challenge := first.str(t, "data.mfa_token")
+
+	api.expect(t, http.StatusBadRequest, "POST", "/api/login/mfa", "", map[string]string{"mfa_token": challenge, "code": "123456"})
+	enrollment := api.expect(t, http.StatusOK, "POST", "/api/login/mfa/enroll", "", map[string]string{"mfa_token": challenge})
+	secret := enrollment.str(t, "data.secret")
+	enrollment.str(t, "data.otpauth_uri")
+
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+	enrolled := api.expect(t, http.StatusOK, "POST", "/api/login/mfa", "", map[string]string{"mfa_token": challenge, "code": code})
+	if n := enrolled.length(t, "data.recovery_codes"); n != service.RecoveryCodeCount {
+		t.Fatalf("%d recovery codes handed out, want %d", n, service.RecoveryCodeCount)
+	}
+	recovery := enrolled.str(t, "data.recovery_codes.0")
+	api.expect(t, http.StatusOK, "GET", "/api/doctor/profile", enrolled.str(t, "data.token"), nil)
+
+	// The challenge is spent, and the code cannot be used again
+	api.expect(t, http.StatusUnauthorized, "POST", "/api/login/mfa", "", map[string]string{"mfa_token": challenge, "code": code})
+	again := api.expect(t, http.StatusOK, "POST", "/api/login", "", map[string]string{"email": doctorEmail, "password": "doctor123"})
+	if enroll, _ := again.field("data.mfa_enrollment_required"); enroll == true {
+		t.Fatalf("enrolled doctor asked to enroll again: %s", again.body)
+	}
+	challenge = again.str(t, "data.mfa_token")
+	api.expect(t, http.StatusUnauthorized, "POST", "/api/login/mfa", "", map[string]string{"mfa_token": challenge, "code": code})
+
+	done := api.expect(t, http.StatusOK, "POST", "/api/login/mfa", "", map[string]string{"mfa_token": challenge, "recovery_code": recovery})
+	access := done.str(t, "data.token")
+
+	// Doctors cannot switch MFA off
+	api.expect(t, http.StatusForbidden, "POST", "/api/mfa/disable", access, map[string]string{"code": recovery})
+}
+
+// TestLoginLockout checks that an account backs off after repeated wrong
+// passwords, even for the right one, and says when to come back.
+func TestLoginLockout(t *testing.T) {
+	api := newTestAPI(t)
+
+	wrong := map[string]string{"email": patientEmail, "password": "wrong-password"}
+	for range service.AccountFreeAttempts {
+		api.expect(t, http.StatusUnauthorized, "POST", "/api/login", "", wrong)
+	}
+
+	for _, body := range []map[string]string{wrong, {"email": patientEmail, "password": "patient123"}} {
+		res := api.expect(t, http.StatusTooManyRequests, "POST", "/api/login", "", body)
+		if seconds, err := strconv.Atoi(res.header.Get("Retry-After")); err != nil || seconds < 1 {
+			t.Errorf("Retry-After = %q, want a number of seconds", res.header.Get("Retry-After"))
+		}
+	}
+
+	// Other accounts are not affected
+	api.login(t, "patient2@email.com", "patient123")
+}
+
+// TestPasswordReset redeems the link from the reset mail.
+func TestPasswordReset(t *testing.T) {
+	api := newTestAPI(t)
+	access, _ := api.login(t, patientEmail, "patient123")
+
+	api.expect(t, http.StatusOK, "POST", "/api/password/forgot", "", map[string]string{"email": patientEmail})
+	token := api.mail.lastToken(t)
+
+	api.expect(t, http.StatusOK, "POST", "/api/password/reset", "", map[string]string{"token": token, "password": "baru12345"})
+	api.expect(t, http.StatusBadRequest, "POST", "/api/password/reset", "", map[string]string{"token": token, "password": "lagi12345"})
+
+	api.expect(t, http.StatusUnauthorized, "GET", "/api/patient/profile", access, nil)
+	api.expect(t, http.StatusUnauthorized, "POST", "/api/login", "", map[string]string{"email": patientEmail, "password": "patient123"})
+	api.login(t, patientEmail, "baru12345")
+}
+
+// TestPostmanCollection makes sure every request documented in the Postman
+// collection still reaches a route.
+func TestPostmanCollection(t *testing.T) {
+	data, err := os.ReadFile("../../../HMC-Medical-Record.postman_collection.json")
+	if err != nil {
+		t.Fatalf("read collection: %v", err)
+	}
+
+	type item struct {
+		Name    string `json:"name"`
+		Item    []item `json:"item"`
+		Request *struct {
+			Method string `json:"method"`
+			URL    struct {
+				Raw string `json:"raw"`
+			} `json:"url"`
+		} `json:"request"`
+	}
+	var collection item
+	if err := json.Unmarshal(data, &collection); err != nil {
+		t.Fatalf("parse collection: %v", err)
+	}
+
+	routes := newTestAPI(t).router.(chi.Routes)
+	count := 0
+	var walk func(prefix string, items []item)
+	walk = func(prefix string, items []item) {
+		for _, it := range items {
+			name := prefix + it.Name
+			if it.Request == nil {
+				walk(name+"/", it.Item)
+				continue
+			}
+			count++
+
+			path, ok := strings.CutPrefix(it.Request.URL.Raw, "{{url_golang}}")
+			if !ok {
+				t.Errorf("%s: unexpected URL %s", name, it.Request.URL.Raw)
+				continue
+			}
+			path, _, _ = strings.Cut(path, "?")
+			if !routes.Match(chi.NewRouteContext(), it.Request.Method, "/api"+path) {
+				t.Errorf("%s: no route for %s /api%s", name, it.Request.Method, path)
+			}
+		}
+	}
+	walk("", collection.Item)
+
+	if count == 0 {
+		t.Fatal("collection has no requests")
+	}
+}
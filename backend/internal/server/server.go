@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Config holds the listen address and timeouts of the HTTP server.
+type Config struct {
+	Addr string
+
+	// ReadTimeout covers reading a whole request, WriteTimeout writing the
+	// response and IdleTimeout how long a keep-alive connection may wait for
+	// the next request
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+
+	// ShutdownTimeout is how long in-flight requests get to finish once the
+	// server is asked to stop
+	ShutdownTimeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Addr:            ":8080",
+		ReadTimeout:     15 * time.Second,
+		WriteTimeout:    30 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: 20 * time.Second,
+	}
+}
+
+// Server serves a handler until it is stopped and then closes the database
+// pool behind it.
+type Server struct {
+	http            *http.Server
+	db              *sql.DB
+	shutdownTimeout time.Duration
+}
+
+func New(cfg Config, handler http.Handler, db *sql.DB) *Server {
+	return &Server{
+		http: &http.Server{
+			Addr:         cfg.Addr,
+			Handler:      handler,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+		},
+		db:              db,
+		shutdownTimeout: cfg.ShutdownTimeout,
+	}
+}
+
+// Run listens on the configured address and serves until ctx is done or the
+// process receives SIGINT or SIGTERM.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		s.db.Close()
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve is Run on a listener the caller opened. On the way out it stops
+// accepting connections, waits up to the shutdown timeout for in-flight
+// requests and closes the database pool.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- s.http.Serve(ln)
+	}()
+	log.Printf("Server berjalan di %s", ln.Addr())
+
+	var err error
+	select {
+	case err = <-served:
+	case <-ctx.Done():
+		// A second signal kills the process instead of waiting
+		stop()
+		log.Println("Server dihentikan, menunggu request yang masih berjalan")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+		defer cancel()
+		if err = s.http.Shutdown(shutdownCtx); errors.Is(err, context.DeadlineExceeded) {
+			// Cut off whatever did not finish in time
+			s.http.Close()
+		}
+	}
+
+	if closeErr := s.db.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/storage"
+)
+
+// startServer serves handler on a free port until the returned cancel is
+// called. Serve's result arrives on the channel.
+func startServer(t *testing.T, cfg Config, handler http.Handler) (*sql.DB, string, context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	t.Setenv("DB_URL", "sqlite://:memory:")
+	db, err := storage.GetConnection()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	served := make(chan error, 1)
+	go func() {
+		served <- New(cfg, handler, db).Serve(ctx, ln)
+	}()
+	return db, "http://" + ln.Addr().String(), cancel, served
+}
+
+func TestServeDrainsRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+	})
+	db, url, stop, served := startServer(t, DefaultConfig(), handler)
+
+	type result struct {
+		body string
+		err  error
+	}
+	got := make(chan result, 1)
+	go func() {
+		res, err := http.Get(url)
+		if err != nil {
+			got <- result{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		got <- result{string(body), err}
+	}()
+
+	<-started
+	stop()
+	select {
+	case err := <-served:
+		t.Fatalf("Serve returned %v while a request was in flight", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	if r := <-got; r.err != nil || r.body != "done" {
+		t.Fatalf("in-flight request = %q, %v; want it answered", r.body, r.err)
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	if err := db.Ping(); err == nil {
+		t.Error("database pool is still open after the shutdown")
+	}
+}
+
+func TestServeCutsOffSlowRequests(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+	cfg := DefaultConfig()
+	cfg.ShutdownTimeout = 50 * time.Millisecond
+	db, url, stop, served := startServer(t, cfg, handler)
+
+	go http.Get(url)
+	<-started
+	stop()
+
+	select {
+	case err := <-served:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Serve = %v, want the shutdown deadline", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve waited past its shutdown timeout")
+	}
+	if err := db.Ping(); err == nil {
+		t.Error("database pool is still open after the shutdown")
+	}
+}
+
+func TestCORSOrigins(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		allowed bool
+	}{
+		{"listed origin", []string{"https://app.example.com"}, "https://app.example.com", true},
+		{"other origin", []string{"https://app.example.com"}, "https://evil.example.com", false},
+		{"no origins configured", nil, "https://app.example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewRouter(Deps{CORSOrigins: tt.origins})
+
+			req := httptest.NewRequest(http.MethodOptions, "/api/login", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			got := rec.Header().Get("Access-Control-Allow-Origin")
+			if tt.allowed && got != tt.origin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.origin)
+			}
+			if !tt.allowed && got != "" {
+				t.Errorf("Access-Control-Allow-Origin = %q for an origin that is not allowed", got)
+			}
+		})
+	}
+}
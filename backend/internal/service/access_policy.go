@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+// AccessPolicy decides who may read patient data. Callers that get false
+// should answer as if the resource did not exist, so its existence is not
+// leaked to other users.
+type AccessPolicy interface {
+	// CanViewAppointment allows the owning patient, the assigned doctor and admins.
+	CanViewAppointment(ctx context.Context, actor domain.Actor, ap *domain.Appointment) (bool, error)
+	// CanViewPatient allows the patient themself, doctors the patient has
+	// booked and admins.
+	CanViewPatient(ctx context.Context, actor domain.Actor, patientID int) (bool, error)
+}
+
+type accessPolicy struct {
+	doctorRepo      repository.DoctorRepository
+	patientRepo     repository.PatientRepository
+	appointmentRepo repository.AppointmentRepository
+}
+
+func NewAccessPolicy(
+	dr repository.DoctorRepository,
+	pr repository.PatientRepository,
+	ar repository.AppointmentRepository,
+) AccessPolicy {
+	return &accessPolicy{
+		doctorRepo:      dr,
+		patientRepo:     pr,
+		appointmentRepo: ar,
+	}
+}
+
+func (p *accessPolicy) CanViewAppointment(ctx context.Context, actor domain.Actor, ap *domain.Appointment) (bool, error) {
+	switch actor.Role {
+	case domain.RoleAdmin:
+		return true, nil
+	case domain.RolePatient:
+		patientID, err := p.patientIDOf(ctx, actor.UserID)
+		if err != nil {
+			return false, err
+		}
+		return patientID != 0 && patientID == ap.PatientID, nil
+	case domain.RoleDoctor:
+		doctorID, err := p.doctorIDOf(ctx, actor.UserID)
+		if err != nil {
+			return false, err
+		}
+		return doctorID != 0 && doctorID == ap.DoctorID, nil
+	}
+	return false, nil
+}
+
+func (p *accessPolicy) CanViewPatient(ctx context.Context, actor domain.Actor, patientID int) (bool, error) {
+	switch actor.Role {
+	case domain.RoleAdmin:
+		return true, nil
+	case domain.RolePatient:
+		ownID, err := p.patientIDOf(ctx, actor.UserID)
+		if err != nil {
+			return false, err
+		}
+		return ownID != 0 && ownID == patientID, nil
+	case domain.RoleDoctor:
+		doctorID, err := p.doctorIDOf(ctx, actor.UserID)
+		if err != nil || doctorID == 0 {
+			return false, err
+		}
+		return p.appointmentRepo.ExistsForDoctorAndPatient(ctx, int64(doctorID), int64(patientID))
+	}
+	return false, nil
+}
+
+// patientIDOf returns 0 when the user has no patient profile.
+func (p *accessPolicy) patientIDOf(ctx context.Context, userID int64) (int, error) {
+	patient, err := p.patientRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return patient.ID, nil
+}
+
+// doctorIDOf returns 0 when the user has no doctor profile.
+func (p *accessPolicy) doctorIDOf(ctx context.Context, userID int64) (int, error) {
+	doctor, err := p.doctorRepo.GetByUserId(ctx, int(userID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return doctor.ID, nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/mailer"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	PasswordResetTTL = time.Hour
+	VerifyEmailTTL   = 48 * time.Hour
+)
+
+// AccountLinks are the base URLs put into emails. VerifyEmailURL points at
+// GET /api/verify-email, ResetPasswordURL at the page where the user picks a
+// new password; the token is appended as ?token=.
+type AccountLinks struct {
+	VerifyEmailURL   string
+	ResetPasswordURL string
+}
+
+// AccountService runs the password reset and email verification flows. The
+// tokens are single use, expire and are stored hashed in user_tokens.
+type AccountService interface {
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, req domain.ResetPasswordRequest) error
+	SendVerification(ctx context.Context, user domain.User) error
+	VerifyEmail(ctx context.Context, token string) error
+}
+
+type accountService struct {
+	tx        repository.TxRunner
+	tokenRepo repository.UserTokenRepository
+	userRepo  repository.UserRepository
+	sessions  SessionService
+	mail      mailer.Mailer
+	links     AccountLinks
+}
+
+func NewAccountService(
+	tx repository.TxRunner,
+	tr repository.UserTokenRepository,
+	ur repository.UserRepository,
+	sessions SessionService,
+	mail mailer.Mailer,
+	links AccountLinks,
+) AccountService {
+	return &accountService{
+		tx:        tx,
+		tokenRepo: tr,
+		userRepo:  ur,
+		sessions:  sessions,
+		mail:      mail,
+		links:     links,
+	}
+}
+
+// ForgotPassword mails a reset link. Unknown addresses are not reported, so
+// the endpoint cannot be used to find out who has an account.
+func (s *accountService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		log.Println("password reset requested for unknown email:", err)
+		return nil
+	}
+
+	raw, err := s.issue(ctx, user.ID, domain.TokenPurposePasswordReset, PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nBuka tautan berikut untuk mengganti password Anda:\n%s\n\nTautan berlaku %s dan hanya bisa dipakai sekali. Abaikan email ini jika Anda tidak meminta reset password.\n",
+			user.Name, withToken(s.links.ResetPasswordURL, raw), PasswordResetTTL,
+		),
+	})
+}
+
+// ResetPassword sets a new password and logs out every session of the user.
+func (s *accountService) ResetPassword(ctx context.Context, req domain.ResetPasswordRequest) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	var userID int
+	err = s.redeem(ctx, domain.TokenPurposePasswordReset, req.Token, func(txCtx context.Context, token *domain.UserToken) error {
+		userID = token.UserID
+		return s.userRepo.UpdatePassword(txCtx, token.UserID, string(hashedPassword))
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.sessions.Logout(ctx, userID, "", true)
+}
+
+// SendVerification mails a link that proves the user owns their address.
+func (s *accountService) SendVerification(ctx context.Context, user domain.User) error {
+	raw, err := s.issue(ctx, user.ID, domain.TokenPurposeVerifyEmail, VerifyEmailTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mail.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nKonfirmasi alamat email Anda dengan membuka tautan berikut:\n%s\n\nTautan berlaku %s.\n",
+			user.Name, withToken(s.links.VerifyEmailURL, raw), VerifyEmailTTL,
+		),
+	})
+}
+
+func (s *accountService) VerifyEmail(ctx context.Context, token string) error {
+	return s.redeem(ctx, domain.TokenPurposeVerifyEmail, token, func(txCtx context.Context, t *domain.UserToken) error {
+		return s.userRepo.MarkEmailVerified(txCtx, t.UserID)
+	})
+}
+
+// issue revokes the outstanding tokens of purpose and creates a new one.
+func (s *accountService) issue(ctx context.Context, userID int, purpose domain.TokenPurpose, ttl time.Duration) (string, error) {
+	if err := s.tokenRepo.RevokeUnused(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	token := &domain.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// redeem runs apply and burns the token in one transaction. apply gets a
+// context carrying the transaction for the user repository.
+func (s *accountService) redeem(
+	ctx context.Context,
+	purpose domain.TokenPurpose,
+	raw string,
+	apply func(txCtx context.Context, token *domain.UserToken) error,
+) error {
+	return s.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		token, err := s.tokenRepo.GetByHashForUpdateTx(ctx, tx, purpose, hashToken(raw))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+		if token.UsedAt != nil || !time.Now().Before(token.ExpiresAt) {
+			return ErrInvalidToken
+		}
+
+		txCtx := context.WithValue(ctx, "tx", tx)
+		if err := apply(txCtx, token); err != nil {
+			return err
+		}
+		return s.tokenRepo.MarkUsedTx(ctx, tx, token.ID)
+	})
+}
+
+func withToken(base, token string) string {
+	return base + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestInviteAccept(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.inviteService()
+	doctor := f.addDoctor(t, "Ana Putri")
+
+	stale, err := svc.Issue(ctx, doctor.UserID)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	invite, err := svc.Issue(ctx, doctor.UserID)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if err := svc.Accept(ctx, domain.AcceptInviteRequest{Token: stale.Token, Password: "rahasia1"}); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("superseded invite: err = %v, want ErrInvalidToken", err)
+	}
+
+	if err := svc.Accept(ctx, domain.AcceptInviteRequest{Token: invite.Token, Password: "rahasia1"}); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	assertPassword(t, f, doctor.UserID, "rahasia1")
+
+	if err := svc.Accept(ctx, domain.AcceptInviteRequest{Token: invite.Token, Password: "rahasia2"}); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("second accept: err = %v, want ErrInvalidToken", err)
+	}
+	assertPassword(t, f, doctor.UserID, "rahasia1")
+
+	if _, err := svc.Issue(ctx, doctor.UserID); !errors.Is(err, ErrInviteAccepted) {
+		t.Errorf("re-inviting: err = %v, want ErrInviteAccepted", err)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.accountService()
+	sessions := f.sessionService()
+	user := f.addUser(t, "Budi Santoso", domain.RolePatient)
+
+	session, err := sessions.Issue(ctx, user)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	// Unknown addresses look the same as known ones to the caller
+	if err := svc.ForgotPassword(ctx, "nobody@example.com"); err != nil {
+		t.Fatalf("ForgotPassword for unknown email: %v", err)
+	}
+	if len(f.mail.sent) != 0 {
+		t.Fatal("mail sent to an unknown address")
+	}
+
+	if err := svc.ForgotPassword(ctx, user.Email); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	token := f.mail.lastToken(t)
+
+	if err := svc.ResetPassword(ctx, domain.ResetPasswordRequest{Token: token, Password: "baru123"}); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	assertPassword(t, f, user.ID, "baru123")
+	if revoked, _ := sessions.IsRevoked(ctx, session.AccessToken); !revoked {
+		t.Error("sessions survived the password reset")
+	}
+
+	if err := svc.ResetPassword(ctx, domain.ResetPasswordRequest{Token: token, Password: "lagi123"}); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("reused reset token: err = %v, want ErrInvalidToken", err)
+	}
+	assertPassword(t, f, user.ID, "baru123")
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.accountService()
+	user := f.addUser(t, "Budi Santoso", domain.RolePatient)
+
+	if err := svc.SendVerification(ctx, user); err != nil {
+		t.Fatalf("SendVerification: %v", err)
+	}
+	token := f.mail.lastToken(t)
+
+	// A reset token cannot verify an email
+	if err := svc.ForgotPassword(ctx, user.Email); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	if err := svc.VerifyEmail(ctx, f.mail.lastToken(t)); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("reset token: err = %v, want ErrInvalidToken", err)
+	}
+
+	if err := svc.VerifyEmail(ctx, token); err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	got, err := f.users.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.EmailVerifiedAt == nil {
+		t.Error("email is not marked verified")
+	}
+}
+
+func assertPassword(t *testing.T, f *fixture, userID int, password string) {
+	t.Helper()
+
+	user, err := f.users.FindByID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		t.Errorf("password of user %d is not %q", userID, password)
+	}
+}
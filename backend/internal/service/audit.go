@@ -0,0 +1,29 @@
+package service
+
+import (
+	"github.com/JinXVIII/BE-Medical-Record/internal/audit"
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+// auditEvent describes an action on a resource that belongs to patientID.
+// A patientID of 0 means the resource is not tied to one patient.
+func auditEvent(action, resourceType string, resourceID, patientID int64) domain.AuditEvent {
+	e := domain.AuditEvent{
+		Action:       action,
+		ResourceType: resourceType,
+	}
+	if resourceID != 0 {
+		e.ResourceID = audit.ID(resourceID)
+	}
+	if patientID != 0 {
+		e.PatientID = &patientID
+	}
+	return e
+}
+
+// deniedEvent is auditEvent for an access the policy refused.
+func deniedEvent(action, resourceType string, resourceID, patientID int64) domain.AuditEvent {
+	e := auditEvent(action, resourceType, resourceID, patientID)
+	e.Outcome = domain.AuditOutcomeDenied
+	return e
+}
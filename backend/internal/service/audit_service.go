@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+const (
+	DefaultAuditEventLimit = 100
+	MaxAuditEventLimit     = 500
+)
+
+// AuditService reads the audit trail for administrators.
+type AuditService interface {
+	Events(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEvent, error)
+	// Export streams every matching event, oldest first, to fn.
+	Export(ctx context.Context, f domain.AuditFilter, fn func(domain.AuditEvent) error) error
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+func (s *auditService) Events(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEvent, error) {
+	if f.Limit <= 0 {
+		f.Limit = DefaultAuditEventLimit
+	}
+	f.Limit = min(f.Limit, MaxAuditEventLimit)
+	return s.repo.List(ctx, f)
+}
+
+func (s *auditService) Export(ctx context.Context, f domain.AuditFilter, fn func(domain.AuditEvent) error) error {
+	return s.repo.Each(ctx, f, fn)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+const (
+	DefaultSlotMinutes  = 30
+	MaxAvailabilityDays = 31
+)
+
+var ErrInvalidAvailabilityRange = errors.New("rentang tanggal atau durasi slot tidak valid")
+
+// scheduleLoad maps a schedule ID to the number of active appointments booked
+// on it for a single date.
+type scheduleLoad map[int]int
+
+// GetDoctorAvailability expands the doctor's weekly schedules into dated slots
+// between from and to (inclusive). Every slot is judged by checkSlot, the same
+// rule CreateAppointment enforces, so a slot shown as available is bookable.
+func (s *patientService) GetDoctorAvailability(
+	ctx context.Context,
+	doctorID int64,
+	from, to time.Time,
+	slotMinutes int,
+) ([]domain.AvailabilitySlot, error) {
+	if slotMinutes <= 0 {
+		slotMinutes = DefaultSlotMinutes
+	}
+	from = truncateDate(from)
+	to = truncateDate(to)
+	if to.Before(from) || slotMinutes > 24*60 || to.Sub(from) >= MaxAvailabilityDays*24*time.Hour {
+		return nil, ErrInvalidAvailabilityRange
+	}
+
+	schedules, err := s.scheduleRepo.GetByDoctor(ctx, doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.appointmentRepo.CountActiveByDoctor(ctx, doctorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	loads := make(map[string]scheduleLoad)
+	for _, b := range bookings {
+		key := b.Date.Format("2006-01-02")
+		if loads[key] == nil {
+			loads[key] = scheduleLoad{}
+		}
+		loads[key][b.ScheduleID] += b.Count
+	}
+
+	slots := []domain.AvailabilitySlot{}
+	step := time.Duration(slotMinutes) * time.Minute
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := workDayOf(date)
+		key := date.Format("2006-01-02")
+
+		for _, schedule := range schedules {
+			if schedule.WorkDay != day {
+				continue
+			}
+			start, err := parseClock(schedule.StartTime)
+			if err != nil {
+				continue
+			}
+			end, err := parseClock(schedule.EndTime)
+			if err != nil {
+				continue
+			}
+
+			for slotStart := start; slotStart.Before(end); slotStart = slotStart.Add(step) {
+				slotEnd := slotStart.Add(step)
+				if slotEnd.After(end) {
+					slotEnd = end
+				}
+
+				startTime := slotStart.Format("15:04:05")
+				_, checkErr := checkSlot([]domain.DoctorSchedule{schedule}, startTime, loads[key])
+
+				slot := domain.AvailabilitySlot{
+					Date:         key,
+					StartTime:    startTime,
+					EndTime:      slotEnd.Format("15:04:05"),
+					ScheduleID:   schedule.ID,
+					PatientQuota: schedule.PatientQuota,
+					Available:    checkErr == nil,
+				}
+				if schedule.PatientQuota > 0 {
+					remaining := max(schedule.PatientQuota-loads[key][schedule.ID], 0)
+					slot.RemainingQuota = &remaining
+				}
+				slots = append(slots, slot)
+			}
+		}
+	}
+
+	return slots, nil
+}
+
+// checkSlot is the booking rule: the doctor must work that day, the slot must
+// fall inside one of the day's schedules and that schedule must still have
+// quota left. A quota of 0 means the schedule has no patient limit.
+func checkSlot(schedules []domain.DoctorSchedule, startTimeSlot string, load scheduleLoad) (*domain.DoctorSchedule, error) {
+	schedule, err := matchSchedule(schedules, startTimeSlot)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.PatientQuota > 0 && load[schedule.ID] >= schedule.PatientQuota {
+		return nil, ErrQuotaFull
+	}
+	return schedule, nil
+}
+
+// matchSchedule returns the schedule whose working hours contain the slot.
+// schedules must all belong to the same doctor and work day.
+func matchSchedule(schedules []domain.DoctorSchedule, startTimeSlot string) (*domain.DoctorSchedule, error) {
+	if len(schedules) == 0 {
+		return nil, ErrDoctorOffDay
+	}
+
+	slot, err := parseClock(startTimeSlot)
+	if err != nil {
+		return nil, ErrSlotOutsideSchedule
+	}
+
+	for i := range schedules {
+		start, err := parseClock(schedules[i].StartTime)
+		if err != nil {
+			continue
+		}
+		end, err := parseClock(schedules[i].EndTime)
+		if err != nil {
+			continue
+		}
+		if !slot.Before(start) && slot.Before(end) {
+			return &schedules[i], nil
+		}
+	}
+
+	return nil, ErrSlotOutsideSchedule
+}
+
+func workDayOf(date time.Time) domain.WorkDay {
+	return domain.WorkDay(strings.ToLower(date.Weekday().String()))
+}
+
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseClock parses a TIME column value or a HH:MM slot.
+func parseClock(value string) (time.Time, error) {
+	if t, err := time.Parse("15:04:05", value); err == nil {
+		return t, nil
+	}
+	return time.Parse("15:04", value)
+}
+
+// sameClock reports whether two slot values name the same time of day.
+func sameClock(a, b string) bool {
+	ta, err := parseClock(a)
+	if err != nil {
+		return false
+	}
+	tb, err := parseClock(b)
+	if err != nil {
+		return false
+	}
+	return ta.Equal(tb)
+}
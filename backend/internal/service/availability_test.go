@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+func TestGetDoctorAvailabilitySkipsDaysOff(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.patientService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "10:00", 0)
+	f.addSchedule(t, doctor.ID, domain.WorkDayWednesday, "13:00", "14:00", 0)
+	monday := nextWeekday(time.Monday)
+
+	// Monday to Wednesday, the doctor is off on Tuesday
+	slots, err := svc.GetDoctorAvailability(ctx, int64(doctor.ID), monday, monday.AddDate(0, 0, 2), 30)
+	if err != nil {
+		t.Fatalf("GetDoctorAvailability: %v", err)
+	}
+
+	perDay := map[string]int{}
+	for _, slot := range slots {
+		perDay[slot.Date]++
+		if !slot.Available || slot.RemainingQuota != nil {
+			t.Errorf("slot %+v: want available with no quota limit", slot)
+		}
+	}
+	want := map[string]int{
+		monday.Format("2006-01-02"):                  4,
+		monday.AddDate(0, 0, 2).Format("2006-01-02"): 2,
+	}
+	if len(perDay) != len(want) {
+		t.Errorf("slots on %v, want only monday and wednesday", perDay)
+	}
+	for date, n := range want {
+		if perDay[date] != n {
+			t.Errorf("%d slots on %s, want %d", perDay[date], date, n)
+		}
+	}
+	if first := slots[0]; first.StartTime != "08:00:00" || first.EndTime != "08:30:00" {
+		t.Errorf("first slot %s-%s, want 08:00:00-08:30:00", first.StartTime, first.EndTime)
+	}
+}
+
+func TestGetDoctorAvailabilityQuota(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.patientService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "09:00", 2)
+	monday := nextWeekday(time.Monday)
+
+	// book returns the patient's user ID and the appointment ID
+	book := func(name string) (int64, int64) {
+		t.Helper()
+		userID := f.addPatient(t, name)
+		ap, err := svc.CreateAppointment(ctx, userID, int64(doctor.ID), monday, "08:00", "", nil)
+		if err != nil {
+			t.Fatalf("booking for %s: %v", name, err)
+		}
+		return userID, int64(ap.ID)
+	}
+	check := func(wantAvailable bool, wantRemaining int) {
+		t.Helper()
+		slots, err := svc.GetDoctorAvailability(ctx, int64(doctor.ID), monday, monday, 30)
+		if err != nil {
+			t.Fatalf("GetDoctorAvailability: %v", err)
+		}
+		if len(slots) != 2 {
+			t.Fatalf("%d slots, want 2", len(slots))
+		}
+		for _, slot := range slots {
+			if slot.Available != wantAvailable || slot.RemainingQuota == nil || *slot.RemainingQuota != wantRemaining {
+				t.Errorf("slot %s: available %v, remaining %v; want %v, %d", slot.StartTime, slot.Available, slot.RemainingQuota, wantAvailable, wantRemaining)
+			}
+		}
+	}
+
+	check(true, 2)
+	patient, cancelled := book("Patient One")
+	_, rejected := book("Patient Two")
+	check(false, 0)
+
+	// Cancelled and rejected appointments give their place back
+	if err := svc.CancelAppointment(ctx, patient, cancelled); err != nil {
+		t.Fatalf("CancelAppointment: %v", err)
+	}
+	if err := svc.UpdateAppointmentStatus(ctx, int64(doctor.ID), rejected, domain.AppointmentStatusRejected); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	check(true, 2)
+
+	// The next week has its own quota
+	book("Patient Three")
+	slots, err := svc.GetDoctorAvailability(ctx, int64(doctor.ID), monday.AddDate(0, 0, 7), monday.AddDate(0, 0, 7), 30)
+	if err != nil {
+		t.Fatalf("GetDoctorAvailability: %v", err)
+	}
+	if len(slots) == 0 || *slots[0].RemainingQuota != 2 {
+		t.Errorf("next week = %+v, want the full quota", slots)
+	}
+}
+
+func TestGetDoctorAvailabilityInvalidRange(t *testing.T) {
+	f := newFixture(t)
+	svc := f.patientService()
+	doctor := f.addDoctor(t, "Ana Putri")
+	monday := nextWeekday(time.Monday)
+
+	tests := []struct {
+		name        string
+		from, to    time.Time
+		slotMinutes int
+	}{
+		{"reversed", monday, monday.AddDate(0, 0, -1), 30},
+		{"longer than the maximum", monday, monday.AddDate(0, 0, MaxAvailabilityDays), 30},
+		{"slot longer than a day", monday, monday, 24*60 + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetDoctorAvailability(context.Background(), int64(doctor.ID), tt.from, tt.to, tt.slotMinutes)
+			if !errors.Is(err, ErrInvalidAvailabilityRange) {
+				t.Errorf("err = %v, want ErrInvalidAvailabilityRange", err)
+			}
+		})
+	}
+
+	// A single day and the longest allowed range are fine
+	for _, to := range []time.Time{monday, monday.AddDate(0, 0, MaxAvailabilityDays-1)} {
+		if _, err := svc.GetDoctorAvailability(context.Background(), int64(doctor.ID), monday, to, 0); err != nil {
+			t.Errorf("range up to %s: %v", to.Format("2006-01-02"), err)
+		}
+	}
+}
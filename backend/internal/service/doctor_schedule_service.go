@@ -86,6 +86,9 @@ func (s *DoctorScheduleServiceImpl) GetSchedulesByDoctorID(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	if schedules == nil {
+		schedules = []domain.DoctorSchedule{}
+	}
 
 	// Ensure Doctor is initialized for each schedule
 	for i := range schedules {
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+func TestCreateSchedule(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.doctorScheduleService()
+	doctor := f.addDoctor(t, "Ana Putri")
+
+	req := domain.DoctorScheduleRequest{
+		DoctorID:     doctor.ID,
+		WorkDay:      "monday",
+		StartTime:    "08:00:00",
+		EndTime:      "12:00:00",
+		PatientQuota: 10,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *domain.DoctorScheduleRequest)
+	}{
+		{"invalid work day", func(r *domain.DoctorScheduleRequest) { r.WorkDay = "someday" }},
+		{"unknown doctor", func(r *domain.DoctorScheduleRequest) { r.DoctorID = doctor.ID + 100 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bad := req
+			tt.modify(&bad)
+			if _, err := svc.CreateSchedule(ctx, bad); err == nil {
+				t.Error("schedule created")
+			}
+		})
+	}
+
+	created, err := svc.CreateSchedule(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateSchedule: %v", err)
+	}
+	got, err := svc.GetScheduleByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetScheduleByID: %v", err)
+	}
+	if got.WorkDay != domain.WorkDayMonday || got.StartTime != "08:00:00" || got.PatientQuota != 10 {
+		t.Errorf("stored schedule = %+v, want the requested one", got)
+	}
+	if got.Doctor == nil || got.Doctor.User == nil {
+		t.Error("schedule returned without its doctor")
+	}
+
+	if _, err := svc.CreateSchedule(ctx, req); err == nil {
+		t.Error("duplicate schedule created")
+	}
+}
+
+func TestGetSchedulesByDoctorID(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.doctorScheduleService()
+	doctor := f.addDoctor(t, "Ana Putri")
+
+	// An empty list, not null, for a doctor without schedules
+	schedules, err := svc.GetSchedulesByDoctorID(ctx, doctor.ID)
+	if err != nil {
+		t.Fatalf("GetSchedulesByDoctorID: %v", err)
+	}
+	if schedules == nil || len(schedules) != 0 {
+		t.Errorf("schedules = %#v, want an empty slice", schedules)
+	}
+
+	f.addSchedule(t, doctor.ID, domain.WorkDayTuesday, "13:00", "16:00", 0)
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 0)
+	schedules, err = svc.GetSchedulesByDoctorID(ctx, doctor.ID)
+	if err != nil {
+		t.Fatalf("GetSchedulesByDoctorID: %v", err)
+	}
+	if len(schedules) != 2 || schedules[0].WorkDay != domain.WorkDayMonday {
+		t.Errorf("schedules = %+v, want monday then tuesday", schedules)
+	}
+
+	if _, err := svc.GetSchedulesByDoctorID(ctx, doctor.ID+100); err == nil {
+		t.Error("schedules listed for an unknown doctor")
+	}
+}
+
+func TestUpdateSchedule(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.doctorScheduleService()
+	doctor := f.addDoctor(t, "Ana Putri")
+	schedule := f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 5)
+
+	req := domain.DoctorScheduleRequest{
+		DoctorID:     doctor.ID,
+		WorkDay:      "wednesday",
+		StartTime:    "09:00:00",
+		EndTime:      "11:00:00",
+		PatientQuota: 3,
+	}
+	if _, err := svc.UpdateSchedule(ctx, schedule.ID+100, req); err == nil {
+		t.Error("unknown schedule updated")
+	}
+	bad := req
+	bad.DoctorID = doctor.ID + 100
+	if _, err := svc.UpdateSchedule(ctx, schedule.ID, bad); err == nil {
+		t.Error("schedule moved to an unknown doctor")
+	}
+	bad = req
+	bad.WorkDay = "someday"
+	if _, err := svc.UpdateSchedule(ctx, schedule.ID, bad); err == nil {
+		t.Error("invalid work day accepted")
+	}
+
+	if _, err := svc.UpdateSchedule(ctx, schedule.ID, req); err != nil {
+		t.Fatalf("UpdateSchedule: %v", err)
+	}
+	got, err := svc.GetScheduleByID(ctx, schedule.ID)
+	if err != nil {
+		t.Fatalf("GetScheduleByID: %v", err)
+	}
+	if got.WorkDay != domain.WorkDayWednesday || got.StartTime != "09:00:00" || got.PatientQuota != 3 {
+		t.Errorf("stored schedule = %+v, want the update", got)
+	}
+}
+
+func TestDeleteSchedule(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.doctorScheduleService()
+	doctor := f.addDoctor(t, "Ana Putri")
+	schedule := f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 0)
+
+	if err := svc.DeleteSchedule(ctx, schedule.ID); err != nil {
+		t.Fatalf("DeleteSchedule: %v", err)
+	}
+	if _, err := svc.GetScheduleByID(ctx, schedule.ID); err == nil {
+		t.Error("deleted schedule still found")
+	}
+	if err := svc.DeleteSchedule(ctx, schedule.ID); err == nil {
+		t.Error("deleting twice succeeded")
+	}
+}
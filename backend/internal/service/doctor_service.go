@@ -2,18 +2,19 @@ package service
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"log"
 	"strings"
 
+	"github.com/JinXVIII/BE-Medical-Record/internal/audit"
 	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
 	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type DoctorService interface {
-	CreateDoctor(ctx context.Context, req domain.DoctorRequest) (domain.Doctor, error)
+	CreateDoctor(ctx context.Context, req domain.DoctorRequest) (domain.DoctorInviteResponse, error)
+	ResendInvite(ctx context.Context, doctorID int) (domain.DoctorInviteResponse, error)
 	GetDoctorByID(ctx context.Context, id int) (domain.Doctor, error)
 	GetByUserID(ctx context.Context, userID int) (domain.Doctor, error)
 	GetAllDoctors(ctx context.Context) ([]domain.Doctor, error)
@@ -26,28 +27,38 @@ type DoctorService interface {
 type DoctorServiceImpl struct {
 	DoctorRepo repository.DoctorRepository
 	UserRepo   repository.UserRepository
-	DB         *sql.DB
+	Invites    InviteService
+	Auditor    audit.Auditor
 }
 
-func NewDoctorService(doctorRepo repository.DoctorRepository, userRepo repository.UserRepository, db *sql.DB) DoctorService {
+func NewDoctorService(doctorRepo repository.DoctorRepository, userRepo repository.UserRepository, invites InviteService, auditor audit.Auditor) DoctorService {
 	return &DoctorServiceImpl{
 		DoctorRepo: doctorRepo,
 		UserRepo:   userRepo,
-		DB:         db,
+		Invites:    invites,
+		Auditor:    auditor,
 	}
 }
 
-func (s *DoctorServiceImpl) CreateDoctor(ctx context.Context, req domain.DoctorRequest) (domain.Doctor, error) {
+// CreateDoctor creates the doctor account with an unusable random password
+// and returns an invite token the doctor redeems to set their own password.
+func (s *DoctorServiceImpl) CreateDoctor(ctx context.Context, req domain.DoctorRequest) (domain.DoctorInviteResponse, error) {
 	// Validate gender
 	if !domain.IsValidGender(req.Gender) {
-		return domain.Doctor{}, errors.New("invalid gender. Valid values: male, female")
+		return domain.DoctorInviteResponse{}, errors.New("invalid gender. Valid values: male, female")
+	}
+
+	// Nobody ever learns this password, the doctor picks one when accepting the invite
+	placeholder, _, err := newOpaqueToken()
+	if err != nil {
+		return domain.DoctorInviteResponse{}, err
 	}
 
 	// Create user first with doctor role
 	user := domain.User{
 		Name:           req.Name,
 		Email:          req.Email,
-		Password:       "password",
+		Password:       placeholder,
 		Role:           domain.RoleDoctor,
 		ProfilePicture: "",
 	}
@@ -56,7 +67,7 @@ func (s *DoctorServiceImpl) CreateDoctor(ctx context.Context, req domain.DoctorR
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		log.Println("ERROR hashing password:", err)
-		return domain.Doctor{}, err
+		return domain.DoctorInviteResponse{}, err
 	}
 	user.Password = string(hashedPassword)
 
@@ -73,10 +84,32 @@ func (s *DoctorServiceImpl) CreateDoctor(ctx context.Context, req domain.DoctorR
 	// Create both user and doctor in repository with transaction
 	createdDoctor, err := s.DoctorRepo.CreateWithUser(ctx, user, doctor)
 	if err != nil {
-		return domain.Doctor{}, err
+		return domain.DoctorInviteResponse{}, err
 	}
 
-	return createdDoctor, nil
+	s.Auditor.Record(ctx, auditEvent(domain.AuditActionCreate, domain.AuditResourceDoctor, int64(createdDoctor.ID), 0))
+
+	invite, err := s.Invites.Issue(ctx, createdDoctor.UserID)
+	if err != nil {
+		return domain.DoctorInviteResponse{}, err
+	}
+
+	return domain.DoctorInviteResponse{Doctor: createdDoctor, Invite: invite}, nil
+}
+
+// ResendInvite replaces the doctor's pending invite with a new one.
+func (s *DoctorServiceImpl) ResendInvite(ctx context.Context, doctorID int) (domain.DoctorInviteResponse, error) {
+	doctor, err := s.GetDoctorByID(ctx, doctorID)
+	if err != nil {
+		return domain.DoctorInviteResponse{}, err
+	}
+
+	invite, err := s.Invites.Issue(ctx, doctor.UserID)
+	if err != nil {
+		return domain.DoctorInviteResponse{}, err
+	}
+
+	return domain.DoctorInviteResponse{Doctor: doctor, Invite: invite}, nil
 }
 
 func (s *DoctorServiceImpl) GetDoctorByID(ctx context.Context, id int) (domain.Doctor, error) {
@@ -163,6 +196,7 @@ func (s *DoctorServiceImpl) UpdateDoctorByDoctorID(ctx context.Context, id int,
 		return domain.Doctor{}, err
 	}
 
+	s.Auditor.Record(ctx, auditEvent(domain.AuditActionUpdate, domain.AuditResourceDoctor, int64(updatedDoctor.ID), 0))
 	return updatedDoctor, nil
 }
 
@@ -216,11 +250,17 @@ func (s *DoctorServiceImpl) UpdateDoctorByUserID(ctx context.Context, userID int
 		return domain.Doctor{}, err
 	}
 
+	s.Auditor.Record(ctx, auditEvent(domain.AuditActionUpdate, domain.AuditResourceDoctor, int64(updatedDoctor.ID), 0))
 	return updatedDoctor, nil
 }
 
 func (s *DoctorServiceImpl) DeleteDoctor(ctx context.Context, id int) error {
-	return s.DoctorRepo.Delete(ctx, id)
+	if err := s.DoctorRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.Auditor.Record(ctx, auditEvent(domain.AuditActionDelete, domain.AuditResourceDoctor, int64(id), 0))
+	return nil
 }
 
 func (s *DoctorServiceImpl) SearchDoctors(ctx context.Context, keyword string, specializationID int) ([]domain.Doctor, error) {
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+func TestCreateDoctor(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.doctorService()
+	existing := f.addDoctor(t, "Ana Putri")
+
+	req := domain.DoctorRequest{
+		Name:             "Citra Dewi",
+		Email:            "citra@hospital.test",
+		SpecializationID: existing.SpecializationID,
+		Gender:           "female",
+		LicenseNumber:    "LIC-0002",
+	}
+
+	bad := req
+	bad.Gender = "other"
+	if _, err := svc.CreateDoctor(ctx, bad); err == nil {
+		t.Fatal("doctor created with an invalid gender")
+	}
+
+	created, err := svc.CreateDoctor(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateDoctor: %v", err)
+	}
+	if created.Doctor.User == nil || created.Doctor.User.Role != domain.RoleDoctor {
+		t.Errorf("created doctor user = %+v, want a doctor account", created.Doctor.User)
+	}
+	if created.Invite.Token == "" {
+		t.Fatal("no invite handed out")
+	}
+	if n := f.auditCount(t, domain.AuditActionCreate, domain.AuditResourceDoctor, domain.AuditOutcomeSuccess); n != 1 {
+		t.Errorf("%d create events recorded, want 1", n)
+	}
+
+	// The doctor only gets in with the password they pick on the invite
+	if err := f.inviteService().Accept(ctx, domain.AcceptInviteRequest{Token: created.Invite.Token, Password: "rahasia1"}); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	assertPassword(t, f, created.Doctor.UserID, "rahasia1")
+
+	taken := req
+	taken.LicenseNumber = "LIC-0003"
+	if _, err := svc.CreateDoctor(ctx, taken); err == nil {
+		t.Error("doctor created with a taken email")
+	}
+	taken = req
+	taken.Email = "other@hospital.test"
+	if _, err := svc.CreateDoctor(ctx, taken); err == nil {
+		t.Error("doctor created with a taken license number")
+	}
+}
+
+func TestResendInvite(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.doctorService()
+	created, err := svc.CreateDoctor(ctx, domain.DoctorRequest{
+		Name:          "Citra Dewi",
+		Email:         "citra@hospital.test",
+		Gender:        "female",
+		LicenseNumber: "LIC-0002",
+	})
+	if err != nil {
+		t.Fatalf("CreateDoctor: %v", err)
+	}
+
+	resent, err := svc.ResendInvite(ctx, created.Doctor.ID)
+	if err != nil {
+		t.Fatalf("ResendInvite: %v", err)
+	}
+	invites := f.inviteService()
+	if err := invites.Accept(ctx, domain.AcceptInviteRequest{Token: created.Invite.Token, Password: "rahasia1"}); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("first invite after a resend: err = %v, want ErrInvalidToken", err)
+	}
+	if err := invites.Accept(ctx, domain.AcceptInviteRequest{Token: resent.Invite.Token, Password: "rahasia1"}); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+
+	if _, err := svc.ResendInvite(ctx, created.Doctor.ID); !errors.Is(err, ErrInviteAccepted) {
+		t.Errorf("resend after accepting: err = %v, want ErrInviteAccepted", err)
+	}
+	if _, err := svc.ResendInvite(ctx, created.Doctor.ID+100); err == nil {
+		t.Error("invite resent for an unknown doctor")
+	}
+}
+
+func TestUpdateDoctor(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.doctorService()
+	doctor := f.addDoctor(t, "Ana Putri")
+	other := f.addDoctor(t, "Citra Dewi")
+
+	// Empty fields keep their current value
+	updated, err := svc.UpdateDoctorByDoctorID(ctx, doctor.ID, domain.DoctorRequest{Address: "Jl. Merdeka 1"})
+	if err != nil {
+		t.Fatalf("UpdateDoctorByDoctorID: %v", err)
+	}
+	if updated.Address != "Jl. Merdeka 1" || updated.LicenseNumber != doctor.LicenseNumber || updated.User.Name != "Ana Putri" {
+		t.Errorf("updated doctor = %+v, want only the address changed", updated)
+	}
+
+	if _, err := svc.UpdateDoctorByDoctorID(ctx, doctor.ID, domain.DoctorRequest{Email: other.User.Email}); err == nil {
+		t.Error("email taken by another doctor accepted")
+	}
+	if _, err := svc.UpdateDoctorByUserID(ctx, doctor.UserID, domain.DoctorRequest{Gender: "other"}); err == nil {
+		t.Error("invalid gender accepted")
+	}
+
+	// Keeping one's own email is not a conflict
+	updated, err = svc.UpdateDoctorByUserID(ctx, doctor.UserID, domain.DoctorRequest{Name: "Ana Putri Sp.A", Email: doctor.User.Email})
+	if err != nil {
+		t.Fatalf("UpdateDoctorByUserID: %v", err)
+	}
+	if updated.User.Name != "Ana Putri Sp.A" || updated.Address != "Jl. Merdeka 1" {
+		t.Errorf("updated doctor = %+v, want the new name and the earlier address", updated)
+	}
+
+	if n := f.auditCount(t, domain.AuditActionUpdate, domain.AuditResourceDoctor, domain.AuditOutcomeSuccess); n != 2 {
+		t.Errorf("%d update events recorded, want 2", n)
+	}
+}
+
+func TestDeleteDoctor(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.doctorService()
+	doctor := f.addDoctor(t, "Ana Putri")
+
+	if err := svc.DeleteDoctor(ctx, doctor.ID); err != nil {
+		t.Fatalf("DeleteDoctor: %v", err)
+	}
+	if _, err := svc.GetDoctorByID(ctx, doctor.ID); err == nil {
+		t.Error("deleted doctor still found")
+	}
+	if err := svc.DeleteDoctor(ctx, doctor.ID); err == nil {
+		t.Error("deleting twice succeeded")
+	}
+	if n := f.auditCount(t, domain.AuditActionDelete, domain.AuditResourceDoctor, domain.AuditOutcomeSuccess); n != 1 {
+		t.Errorf("%d delete events recorded, want 1", n)
+	}
+}
+
+func TestSearchDoctors(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.doctorService()
+	ana := f.addDoctor(t, "Ana Putri")
+	f.addDoctor(t, "Citra Dewi")
+
+	tests := []struct {
+		name             string
+		keyword          string
+		specializationID int
+		want             int
+	}{
+		{"everyone", "", 0, 2},
+		{"trimmed and ignoring case", "  PUTRI ", 0, 1},
+		{"by specialization", "", ana.SpecializationID, 2},
+		{"unknown specialization", "", ana.SpecializationID + 100, 0},
+		{"no match", "budi", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doctors, err := svc.SearchDoctors(ctx, tt.keyword, tt.specializationID)
+			if err != nil {
+				t.Fatalf("SearchDoctors: %v", err)
+			}
+			if len(doctors) != tt.want {
+				t.Errorf("%d doctors found, want %d", len(doctors), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/audit"
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/mailer"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository/memory"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fixture wires the services to the in-memory repositories of one store.
+type fixture struct {
+	tx              repository.TxRunner
+	users           repository.UserRepository
+	userTokens      repository.UserTokenRepository
+	refreshTokens   repository.RefreshTokenRepository
+	specializations repository.SpecializationRepository
+	doctors         repository.DoctorRepository
+	doctorSchedules repository.DoctorScheduleRepository
+	schedules       repository.ScheduleRepository
+	patients        repository.PatientRepository
+	appointments    repository.AppointmentRepository
+	changes         repository.AppointmentChangeRepository
+	records         repository.MedicalRecordRepository
+	audits          repository.AuditRepository
+	loginEvents     repository.LoginEventRepository
+	mfa             repository.MFARepository
+
+	signer *fakeSigner
+	mail   *fakeMailer
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	store := memory.NewStore()
+	return &fixture{
+		tx:              memory.NewTxRunner(store),
+		users:           memory.NewUserRepository(store),
+		userTokens:      memory.NewUserTokenRepository(store),
+		refreshTokens:   memory.NewRefreshTokenRepository(store),
+		specializations: memory.NewSpecializationRepository(store),
+		doctors:         memory.NewDoctorRepository(store),
+		doctorSchedules: memory.NewDoctorScheduleRepository(store),
+		schedules:       memory.NewScheduleRepository(store),
+		patients:        memory.NewPatientRepository(store),
+		appointments:    memory.NewAppointmentRepository(store),
+		changes:         memory.NewAppointmentChangeRepository(store),
+		records:         memory.NewMedicalRecordRepository(store),
+		audits:          memory.NewAuditRepository(store),
+		loginEvents:     memory.NewLoginEventRepository(store),
+		mfa:             memory.NewMFARepository(store),
+		signer:          &fakeSigner{},
+		mail:            &fakeMailer{},
+	}
+}
+
+func (f *fixture) patientService() PatientService {
+	policy := NewAccessPolicy(f.doctors, f.patients, f.appointments)
+	return NewPatientService(f.tx, f.appointments, f.patients, f.schedules, f.changes, policy, audit.New(f.audits))
+}
+
+func (f *fixture) medicalRecordService() MedicalRecordService {
+	return NewMedicalRecordService(f.tx, f.records, f.appointments, f.patients, audit.New(f.audits))
+}
+
+func (f *fixture) sessionService() SessionService {
+	return NewSessionService(f.tx, f.refreshTokens, f.users, f.signer)
+}
+
+func (f *fixture) accountService() AccountService {
+	links := AccountLinks{
+		VerifyEmailURL:   "http://api.test/api/verify-email",
+		ResetPasswordURL: "http://app.test/reset-password",
+	}
+	return NewAccountService(f.tx, f.userTokens, f.users, f.sessionService(), f.mail, links)
+}
+
+func (f *fixture) inviteService() InviteService {
+	return NewInviteService(f.tx, f.userTokens, f.users)
+}
+
+func (f *fixture) doctorService() DoctorService {
+	return NewDoctorService(f.doctors, f.users, f.inviteService(), audit.New(f.audits))
+}
+
+func (f *fixture) doctorScheduleService() DoctorScheduleService {
+	return NewDoctorScheduleService(f.doctorSchedules, f.doctors)
+}
+
+func (f *fixture) userService(config MFAConfig) UserService {
+	return NewUserService(f.users, f.sessionService(), f.accountService(), f.inviteService(), NewLoginGuard(f.loginEvents), f.mfa, f.userTokens, config)
+}
+
+func (f *fixture) addUser(t *testing.T, name string, role domain.UserRole) domain.User {
+	t.Helper()
+
+	user, err := f.users.Register(context.Background(), domain.User{
+		Name:     name,
+		Email:    strings.ToLower(strings.ReplaceAll(name, " ", ".")) + "@example.com",
+		Password: "not-a-hash",
+		Role:     role,
+	})
+	if err != nil {
+		t.Fatalf("register %s: %v", name, err)
+	}
+	return user
+}
+
+// addPatient returns the user ID of a new patient with a patient profile.
+func (f *fixture) addPatient(t *testing.T, name string) int64 {
+	t.Helper()
+
+	user := f.addUser(t, name, domain.RolePatient)
+	if _, err := f.patients.CreateForUser(context.Background(), int64(user.ID)); err != nil {
+		t.Fatalf("create patient %s: %v", name, err)
+	}
+	return int64(user.ID)
+}
+
+func (f *fixture) addDoctor(t *testing.T, name string) domain.Doctor {
+	t.Helper()
+
+	ctx := context.Background()
+	specialization, err := f.specializations.FindByName(ctx, "General")
+	if err != nil {
+		specialization, err = f.specializations.Create(ctx, domain.Specialization{Name: "General"})
+		if err != nil {
+			t.Fatalf("create specialization: %v", err)
+		}
+	}
+
+	doctor, err := f.doctors.CreateWithUser(ctx,
+		domain.User{
+			Name:  name,
+			Email: strings.ToLower(strings.ReplaceAll(name, " ", ".")) + "@hospital.test",
+			Role:  domain.RoleDoctor,
+		},
+		domain.Doctor{
+			SpecializationID: specialization.ID,
+			Gender:           domain.GenderFemale,
+			LicenseNumber:    "LIC-" + name,
+			IsActive:         true,
+		},
+	)
+	if err != nil {
+		t.Fatalf("create doctor %s: %v", name, err)
+	}
+	return doctor
+}
+
+func (f *fixture) addSchedule(t *testing.T, doctorID int, day domain.WorkDay, start, end string, quota int) domain.DoctorSchedule {
+	t.Helper()
+
+	schedule, err := f.doctorSchedules.Create(context.Background(), domain.DoctorSchedule{
+		DoctorID:     doctorID,
+		WorkDay:      day,
+		StartTime:    start,
+		EndTime:      end,
+		PatientQuota: quota,
+	})
+	if err != nil {
+		t.Fatalf("create schedule: %v", err)
+	}
+	return schedule
+}
+
+// auditCount counts the recorded events for action on resourceType.
+func (f *fixture) auditCount(t *testing.T, action, resourceType, outcome string) int {
+	t.Helper()
+
+	events, err := f.audits.List(context.Background(), domain.AuditFilter{
+		Action:       action,
+		ResourceType: resourceType,
+		Limit:        1000,
+	})
+	if err != nil {
+		t.Fatalf("list audit events: %v", err)
+	}
+	count := 0
+	for _, e := range events {
+		if e.Outcome == outcome {
+			count++
+		}
+	}
+	return count
+}
+
+// nextWeekday returns the date of the next day after today falling on day,
+// as the handlers parse it from YYYY-MM-DD.
+func nextWeekday(day time.Weekday) time.Time {
+	date := truncateDate(time.Now()).AddDate(0, 0, 1)
+	for date.Weekday() != day {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+// fakeSigner hands out the jti as the access token, so tests can look the
+// session up by it.
+type fakeSigner struct{}
+
+func (fakeSigner) Sign(claims jwt.Claims) (string, error) {
+	return claims.(domain.Claims).ID, nil
+}
+
+// fakeMailer keeps the messages it is asked to send.
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// lastToken returns the ?token= of the link in the last message sent.
+func (m *fakeMailer) lastToken(t *testing.T) string {
+	t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sent) == 0 {
+		t.Fatal("no mail was sent")
+	}
+	body := m.sent[len(m.sent)-1].Body
+	_, rest, ok := strings.Cut(body, "?token=")
+	if !ok {
+		t.Fatalf("no token link in mail: %q", body)
+	}
+	token, _, _ := strings.Cut(rest, "\n")
+	return token
+}
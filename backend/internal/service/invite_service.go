@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const InviteTTL = 72 * time.Hour
+
+var (
+	ErrInvalidToken   = errors.New("token tidak valid atau sudah kedaluwarsa")
+	ErrInviteAccepted = errors.New("undangan sudah digunakan, akun sudah aktif")
+)
+
+// InviteService hands out invite tokens for accounts created by an admin and
+// lets the invited user redeem one to set their own password.
+type InviteService interface {
+	Issue(ctx context.Context, userID int) (domain.Invite, error)
+	Accept(ctx context.Context, req domain.AcceptInviteRequest) error
+}
+
+type inviteService struct {
+	tx        repository.TxRunner
+	tokenRepo repository.UserTokenRepository
+	userRepo  repository.UserRepository
+}
+
+func NewInviteService(tx repository.TxRunner, tr repository.UserTokenRepository, ur repository.UserRepository) InviteService {
+	return &inviteService{
+		tx:        tx,
+		tokenRepo: tr,
+		userRepo:  ur,
+	}
+}
+
+// Issue creates a fresh invite for the user and revokes any earlier one that
+// was not redeemed yet.
+func (s *inviteService) Issue(ctx context.Context, userID int) (domain.Invite, error) {
+	used, err := s.tokenRepo.HasUsed(ctx, userID, domain.TokenPurposeInvite)
+	if err != nil {
+		return domain.Invite{}, err
+	}
+	if used {
+		return domain.Invite{}, ErrInviteAccepted
+	}
+
+	if err := s.tokenRepo.RevokeUnused(ctx, userID, domain.TokenPurposeInvite); err != nil {
+		return domain.Invite{}, err
+	}
+
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return domain.Invite{}, err
+	}
+	token := &domain.UserToken{
+		UserID:    userID,
+		Purpose:   domain.TokenPurposeInvite,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(InviteTTL),
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return domain.Invite{}, err
+	}
+
+	return domain.Invite{Token: raw, ExpiresAt: token.ExpiresAt}, nil
+}
+
+// Accept sets the user's password and burns the invite in one transaction.
+func (s *inviteService) Accept(ctx context.Context, req domain.AcceptInviteRequest) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		token, err := s.tokenRepo.GetByHashForUpdateTx(ctx, tx, domain.TokenPurposeInvite, hashToken(req.Token))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+		if token.UsedAt != nil || !time.Now().Before(token.ExpiresAt) {
+			return ErrInvalidToken
+		}
+
+		txCtx := context.WithValue(ctx, "tx", tx)
+		if err := s.userRepo.UpdatePassword(txCtx, token.UserID, string(hashedPassword)); err != nil {
+			return err
+		}
+		return s.tokenRepo.MarkUsedTx(ctx, tx, token.ID)
+	})
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+// Failed logins are free up to the threshold; after that every further
+// failure doubles the wait before the next attempt, up to MaxLoginLockout.
+const (
+	AccountFreeAttempts = 3
+	AccountFailureTTL   = time.Hour
+	IPFreeAttempts      = 20
+	IPFailureTTL        = 15 * time.Minute
+	LoginBackoffBase    = time.Second
+	MaxLoginLockout     = 15 * time.Minute
+
+	DefaultLoginEventLimit = 100
+	MaxLoginEventLimit     = 500
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrLoginLocked        = errors.New("too many failed login attempts")
+)
+
+// LoginLockedError is returned while an account or IP is backing off.
+// errors.Is(err, ErrLoginLocked) reports true for it.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, try again in %d seconds", ErrLoginLocked, int(e.RetryAfter.Round(time.Second)/time.Second))
+}
+
+func (e *LoginLockedError) Is(target error) bool {
+	return target == ErrLoginLocked
+}
+
+// LoginGuard throttles login attempts per account and per IP and keeps the
+// login_events log admins can query.
+type LoginGuard interface {
+	Reserve(ctx context.Context, email, ip string) (release func(), err error)
+	Record(ctx context.Context, e domain.LoginEvent)
+	Events(ctx context.Context, f domain.LoginEventFilter) ([]domain.LoginEvent, error)
+}
+
+type loginGuard struct {
+	events   repository.LoginEventRepository
+	now      func() time.Time
+	accounts accountLocks
+}
+
+func NewLoginGuard(er repository.LoginEventRepository) LoginGuard {
+	return &loginGuard{events: er, now: time.Now}
+}
+
+// Reserve returns a *LoginLockedError when the email or the IP has to wait
+// before trying again. Unknown emails are throttled the same way as real
+// ones so the response does not reveal which exist.
+//
+// Otherwise the caller holds the account until it calls release, which it
+// must do after recording the outcome. Attempts on one account therefore run
+// one after another and each sees the failures of those before it, so a
+// burst of parallel guesses cannot all pass at the same failure count.
+//
+// The hold lives in this process only. With several instances behind a load
+// balancer each one serializes its own attempts, so up to one guess per
+// instance can slip past the count at the same time; the backoff still
+// applies once their failures are recorded.
+func (g *loginGuard) Reserve(ctx context.Context, email, ip string) (func(), error) {
+	release := g.accounts.lock(normalizeEmail(email))
+	if err := g.check(ctx, email, ip); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+func (g *loginGuard) check(ctx context.Context, email, ip string) error {
+	now := g.now()
+
+	count, last, err := g.events.FailuresForEmail(ctx, normalizeEmail(email), now.Add(-AccountFailureTTL))
+	if err != nil {
+		return err
+	}
+	wait := retryAfter(now, last, loginBackoff(count, AccountFreeAttempts))
+
+	count, last, err = g.events.FailuresForIP(ctx, ip, now.Add(-IPFailureTTL))
+	if err != nil {
+		return err
+	}
+	wait = max(wait, retryAfter(now, last, loginBackoff(count, IPFreeAttempts)))
+
+	if wait > 0 {
+		return &LoginLockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Record stores the attempt. A failure to write the log must not turn a
+// login into an error, so it is only logged.
+func (g *loginGuard) Record(ctx context.Context, e domain.LoginEvent) {
+	e.Email = normalizeEmail(e.Email)
+	e.CreatedAt = g.now()
+	if len(e.UserAgent) > 255 {
+		e.UserAgent = e.UserAgent[:255]
+	}
+	if err := g.events.Create(ctx, &e); err != nil {
+		log.Println("ERROR: failed to record login event:", err)
+	}
+}
+
+func (g *loginGuard) Events(ctx context.Context, f domain.LoginEventFilter) ([]domain.LoginEvent, error) {
+	if f.Limit <= 0 {
+		f.Limit = DefaultLoginEventLimit
+	}
+	f.Limit = min(f.Limit, MaxLoginEventLimit)
+	f.Email = normalizeEmail(f.Email)
+	return g.events.List(ctx, f)
+}
+
+// loginBackoff is how long to wait after the latest of failures failed attempts.
+func loginBackoff(failures, free int) time.Duration {
+	if failures < free {
+		return 0
+	}
+	exp := failures - free
+	if exp >= 20 {
+		return MaxLoginLockout
+	}
+	return min(LoginBackoffBase<<exp, MaxLoginLockout)
+}
+
+func retryAfter(now, last time.Time, backoff time.Duration) time.Duration {
+	if backoff == 0 || last.IsZero() {
+		return 0
+	}
+	return max(last.Add(backoff).Sub(now), 0)
+}
+
+// accountLocks hands out one mutex per email, dropping it again once nobody
+// holds or waits for it. It is not shared between processes.
+type accountLocks struct {
+	mu    sync.Mutex
+	locks map[string]*accountLock
+}
+
+type accountLock struct {
+	sync.Mutex
+	refs int
+}
+
+func (l *accountLocks) lock(email string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*accountLock)
+	}
+	lock := l.locks[email]
+	if lock == nil {
+		lock = &accountLock{}
+		l.locks[email] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		l.mu.Lock()
+		if lock.refs--; lock.refs == 0 {
+			delete(l.locks, email)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/audit"
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+var (
+	ErrRecordExists       = errors.New("rekam medis untuk appointment ini sudah ada")
+	ErrRecordNotWriteable = errors.New("rekam medis hanya bisa ditulis untuk appointment berstatus Confirmed atau CheckedIn")
+)
+
+type MedicalRecordService interface {
+	CreateRecord(ctx context.Context, doctorID, appointmentID int64, req domain.MedicalRecordRequest) (*domain.MedicalRecord, error)
+	UpdateRecord(ctx context.Context, doctorID, appointmentID int64, req domain.MedicalRecordRequest) (*domain.MedicalRecord, error)
+	GetRecordForDoctor(ctx context.Context, doctorID, appointmentID int64) (*domain.MedicalRecord, error)
+	GetPatientRecords(ctx context.Context, userID int64, filter domain.MedicalRecordFilter) ([]domain.MedicalRecord, error)
+}
+
+type medicalRecordService struct {
+	tx              repository.TxRunner
+	recordRepo      repository.MedicalRecordRepository
+	appointmentRepo repository.AppointmentRepository
+	patientRepo     repository.PatientRepository
+	auditor         audit.Auditor
+}
+
+func NewMedicalRecordService(
+	tx repository.TxRunner,
+	mr repository.MedicalRecordRepository,
+	ar repository.AppointmentRepository,
+	pr repository.PatientRepository,
+	auditor audit.Auditor,
+) MedicalRecordService {
+	return &medicalRecordService{
+		tx:              tx,
+		recordRepo:      mr,
+		appointmentRepo: ar,
+		patientRepo:     pr,
+		auditor:         auditor,
+	}
+}
+
+// CreateRecord writes the examination result for a Confirmed or CheckedIn appointment and
+// marks the appointment Completed in the same transaction.
+func (s *medicalRecordService) CreateRecord(
+	ctx context.Context,
+	doctorID int64,
+	appointmentID int64,
+	req domain.MedicalRecordRequest,
+) (record *domain.MedicalRecord, err error) {
+	var ap *domain.Appointment
+	err = s.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		ap, err = s.appointmentRepo.GetByIDForUpdateTx(ctx, tx, appointmentID)
+		if err != nil {
+			return err
+		}
+		if ap.DoctorID != int(doctorID) {
+			return ErrNotAllowed
+		}
+		if ap.Status == domain.AppointmentStatusCompleted {
+			return ErrRecordExists
+		}
+		if !domain.CanTransitionAppointment(ap.Status, domain.AppointmentStatusCompleted) {
+			return ErrRecordNotWriteable
+		}
+
+		record = &domain.MedicalRecord{
+			AppointmentID:   ap.ID,
+			Diagnosis:       req.Diagnosis,
+			Prescription:    req.Prescription,
+			DoctorNotes:     req.DoctorNotes,
+			ExaminationDate: time.Now(),
+		}
+		if err = s.recordRepo.CreateTx(ctx, tx, record); err != nil {
+			return err
+		}
+		return s.appointmentRepo.UpdateStatusTx(ctx, tx, appointmentID, domain.AppointmentStatusCompleted)
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotAllowed) {
+			s.recordDenied(ctx, domain.AuditActionCreate, ap)
+		}
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionCreate, domain.AuditResourceMedicalRecord, int64(record.ID), int64(ap.PatientID)))
+	return record, nil
+}
+
+// UpdateRecord amends the record of an appointment that was already completed.
+func (s *medicalRecordService) UpdateRecord(
+	ctx context.Context,
+	doctorID int64,
+	appointmentID int64,
+	req domain.MedicalRecordRequest,
+) (record *domain.MedicalRecord, err error) {
+	record, err = s.recordForDoctor(ctx, domain.AuditActionUpdate, doctorID, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	record.Diagnosis = req.Diagnosis
+	record.Prescription = req.Prescription
+	record.DoctorNotes = req.DoctorNotes
+	err = s.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		return s.recordRepo.UpdateTx(ctx, tx, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionUpdate, domain.AuditResourceMedicalRecord, int64(record.ID), int64(record.Appointment.PatientID)))
+	return record, nil
+}
+
+func (s *medicalRecordService) GetRecordForDoctor(ctx context.Context, doctorID, appointmentID int64) (*domain.MedicalRecord, error) {
+	record, err := s.recordForDoctor(ctx, domain.AuditActionView, doctorID, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionView, domain.AuditResourceMedicalRecord, int64(record.ID), int64(record.Appointment.PatientID)))
+	return record, nil
+}
+
+// recordForDoctor loads the record of an appointment assigned to the doctor.
+// Refusals are audited under action.
+func (s *medicalRecordService) recordForDoctor(ctx context.Context, action string, doctorID, appointmentID int64) (*domain.MedicalRecord, error) {
+	ap, err := s.appointmentRepo.GetByID(ctx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if ap.DoctorID != int(doctorID) {
+		s.recordDenied(ctx, action, ap)
+		return nil, ErrNotAllowed
+	}
+
+	record, err := s.recordRepo.GetByAppointmentID(ctx, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	record.Appointment = ap
+	return record, nil
+}
+
+// recordDenied audits a doctor reaching for the record of an appointment
+// assigned to someone else, naming the record when there is one.
+func (s *medicalRecordService) recordDenied(ctx context.Context, action string, ap *domain.Appointment) {
+	var recordID int64
+	if record, err := s.recordRepo.GetByAppointmentID(ctx, int64(ap.ID)); err == nil {
+		recordID = int64(record.ID)
+	}
+	s.auditor.Record(ctx, deniedEvent(action, domain.AuditResourceMedicalRecord, recordID, int64(ap.PatientID)))
+}
+
+// GetPatientRecords returns the medical history of the patient behind userID
+// in chronological order.
+func (s *medicalRecordService) GetPatientRecords(
+	ctx context.Context,
+	userID int64,
+	filter domain.MedicalRecordFilter,
+) ([]domain.MedicalRecord, error) {
+	patient, err := s.patientRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		// No patient row yet means nothing was ever recorded
+		if errors.Is(err, sql.ErrNoRows) {
+			return []domain.MedicalRecord{}, nil
+		}
+		return nil, err
+	}
+
+	records, err := s.recordRepo.GetByPatient(ctx, int64(patient.ID), filter)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []domain.MedicalRecord{}
+	}
+
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionList, domain.AuditResourceMedicalRecord, 0, int64(patient.ID)))
+	return records, nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+func TestCreateRecord(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	appointments := f.patientService()
+	svc := f.medicalRecordService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	stranger := f.addDoctor(t, "Other Doctor")
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 0)
+	patient := f.addPatient(t, "Budi Santoso")
+
+	ap, err := appointments.CreateAppointment(ctx, patient, int64(doctor.ID), nextWeekday(time.Monday), "08:00", "", nil)
+	if err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+	req := domain.MedicalRecordRequest{AppointmentID: ap.ID, Diagnosis: "Influenza", Prescription: "Paracetamol"}
+
+	if _, err := svc.CreateRecord(ctx, int64(doctor.ID), int64(ap.ID), req); !errors.Is(err, ErrRecordNotWriteable) {
+		t.Fatalf("pending appointment: err = %v, want ErrRecordNotWriteable", err)
+	}
+	if err := appointments.UpdateAppointmentStatus(ctx, int64(doctor.ID), int64(ap.ID), domain.AppointmentStatusConfirmed); err != nil {
+		t.Fatalf("UpdateAppointmentStatus: %v", err)
+	}
+	if _, err := svc.CreateRecord(ctx, int64(stranger.ID), int64(ap.ID), req); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("other doctor: err = %v, want ErrNotAllowed", err)
+	}
+	if n := f.auditCount(t, domain.AuditActionCreate, domain.AuditResourceMedicalRecord, domain.AuditOutcomeDenied); n != 1 {
+		t.Errorf("%d denied record writes audited, want 1", n)
+	}
+
+	record, err := svc.CreateRecord(ctx, int64(doctor.ID), int64(ap.ID), req)
+	if err != nil {
+		t.Fatalf("CreateRecord: %v", err)
+	}
+	if record.ID == 0 || record.AppointmentID != ap.ID {
+		t.Errorf("record = %+v, want one stored for appointment %d", record, ap.ID)
+	}
+
+	got, err := f.appointments.GetByID(ctx, int64(ap.ID))
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != domain.AppointmentStatusCompleted {
+		t.Errorf("status = %s, writing the record must complete the appointment", got.Status)
+	}
+
+	if _, err := svc.CreateRecord(ctx, int64(doctor.ID), int64(ap.ID), req); !errors.Is(err, ErrRecordExists) {
+		t.Errorf("second record: err = %v, want ErrRecordExists", err)
+	}
+}
+
+func TestUpdateRecord(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	appointments := f.patientService()
+	svc := f.medicalRecordService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	stranger := f.addDoctor(t, "Other Doctor")
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 0)
+	patient := f.addPatient(t, "Budi Santoso")
+
+	ap, err := appointments.CreateAppointment(ctx, patient, int64(doctor.ID), nextWeekday(time.Monday), "08:00", "", nil)
+	if err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+	if err := appointments.UpdateAppointmentStatus(ctx, int64(doctor.ID), int64(ap.ID), domain.AppointmentStatusConfirmed); err != nil {
+		t.Fatalf("UpdateAppointmentStatus: %v", err)
+	}
+	if _, err := svc.CreateRecord(ctx, int64(doctor.ID), int64(ap.ID), domain.MedicalRecordRequest{AppointmentID: ap.ID, Diagnosis: "Influenza"}); err != nil {
+		t.Fatalf("CreateRecord: %v", err)
+	}
+
+	amend := domain.MedicalRecordRequest{AppointmentID: ap.ID, Diagnosis: "Dengue", DoctorNotes: "Cek trombosit"}
+	if _, err := svc.UpdateRecord(ctx, int64(stranger.ID), int64(ap.ID), amend); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("other doctor: err = %v, want ErrNotAllowed", err)
+	}
+	if _, err := svc.GetRecordForDoctor(ctx, int64(stranger.ID), int64(ap.ID)); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("other doctor reading: err = %v, want ErrNotAllowed", err)
+	}
+	denied, err := f.audits.List(ctx, domain.AuditFilter{ResourceType: domain.AuditResourceMedicalRecord, PatientID: int64(ap.PatientID), Limit: 10})
+	if err != nil {
+		t.Fatalf("list audit events: %v", err)
+	}
+	actions := map[string]bool{}
+	for _, e := range denied {
+		if e.Outcome == domain.AuditOutcomeDenied {
+			actions[e.Action] = true
+			if e.ResourceID == "" {
+				t.Errorf("denied %s does not name the record", e.Action)
+			}
+		}
+	}
+	if !actions[domain.AuditActionUpdate] || !actions[domain.AuditActionView] {
+		t.Errorf("denied record actions audited = %v, want update and view", actions)
+	}
+	if _, err := svc.UpdateRecord(ctx, int64(doctor.ID), int64(ap.ID), amend); err != nil {
+		t.Fatalf("UpdateRecord: %v", err)
+	}
+
+	record, err := svc.GetRecordForDoctor(ctx, int64(doctor.ID), int64(ap.ID))
+	if err != nil {
+		t.Fatalf("GetRecordForDoctor: %v", err)
+	}
+	if record.Diagnosis != "Dengue" || record.DoctorNotes != "Cek trombosit" {
+		t.Errorf("record = %+v, want the amended one", record)
+	}
+	if n := f.auditCount(t, domain.AuditActionUpdate, domain.AuditResourceMedicalRecord, domain.AuditOutcomeSuccess); n != 1 {
+		t.Errorf("%d record updates audited, want 1", n)
+	}
+}
+
+func TestGetPatientRecords(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	appointments := f.patientService()
+	svc := f.medicalRecordService()
+
+	ana := f.addDoctor(t, "Ana Putri")
+	rudi := f.addDoctor(t, "Rudi Hartono")
+	f.addSchedule(t, ana.ID, domain.WorkDayMonday, "08:00", "12:00", 0)
+	f.addSchedule(t, rudi.ID, domain.WorkDayTuesday, "08:00", "12:00", 0)
+	patient := f.addPatient(t, "Budi Santoso")
+
+	for _, visit := range []struct {
+		doctor domain.Doctor
+		date   time.Time
+	}{
+		{rudi, nextWeekday(time.Tuesday).AddDate(0, 0, 7)},
+		{ana, nextWeekday(time.Monday)},
+	} {
+		ap, err := appointments.CreateAppointment(ctx, patient, int64(visit.doctor.ID), visit.date, "09:00", "", nil)
+		if err != nil {
+			t.Fatalf("CreateAppointment: %v", err)
+		}
+		if err := appointments.UpdateAppointmentStatus(ctx, int64(visit.doctor.ID), int64(ap.ID), domain.AppointmentStatusConfirmed); err != nil {
+			t.Fatalf("UpdateAppointmentStatus: %v", err)
+		}
+		if _, err := svc.CreateRecord(ctx, int64(visit.doctor.ID), int64(ap.ID), domain.MedicalRecordRequest{AppointmentID: ap.ID, Diagnosis: "Checkup"}); err != nil {
+			t.Fatalf("CreateRecord: %v", err)
+		}
+	}
+
+	records, err := svc.GetPatientRecords(ctx, patient, domain.MedicalRecordFilter{})
+	if err != nil {
+		t.Fatalf("GetPatientRecords: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	first := records[0].Appointment
+	if first == nil || first.Doctor == nil || first.Doctor.User.Name != "Ana Putri" {
+		t.Fatalf("first record should be Ana Putri's visit, got %+v", first)
+	}
+	if first.Doctor.Specialization == nil || first.Doctor.Specialization.Name != "General" {
+		t.Errorf("record lacks the doctor's specialization: %+v", first.Doctor.Specialization)
+	}
+
+	records, err = svc.GetPatientRecords(ctx, patient, domain.MedicalRecordFilter{DoctorID: rudi.ID})
+	if err != nil {
+		t.Fatalf("GetPatientRecords: %v", err)
+	}
+	if len(records) != 1 || records[0].Appointment.DoctorID != rudi.ID {
+		t.Errorf("doctor filter returned %+v", records)
+	}
+
+	// A user who never became a patient simply has no history
+	nobody := f.addUser(t, "New Comer", domain.RolePatient)
+	records, err = svc.GetPatientRecords(ctx, int64(nobody.ID), domain.MedicalRecordFilter{})
+	if err != nil || len(records) != 0 {
+		t.Errorf("GetPatientRecords = %v, %v; want an empty history", records, err)
+	}
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/totp"
+)
+
+const (
+	MFAChallengeTTL   = 5 * time.Minute
+	RecoveryCodeCount = 10
+)
+
+var (
+	ErrInvalidMFACode    = errors.New("kode autentikasi tidak valid")
+	ErrMFANotEnrolled    = errors.New("MFA belum didaftarkan")
+	ErrMFAAlreadyEnabled = errors.New("MFA sudah aktif")
+	ErrMFAEnforced       = errors.New("MFA wajib untuk role ini dan tidak bisa dimatikan")
+)
+
+// MFAConfig says which roles must use a second factor and how the account is
+// labelled in authenticator apps.
+type MFAConfig struct {
+	Issuer        string
+	RequiredRoles []domain.UserRole
+}
+
+func (c MFAConfig) required(role domain.UserRole) bool {
+	for _, r := range c.RequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// mfaChallenge returns the partial login response when user needs a second
+// factor, or nil when the password alone is enough.
+func (service *UserServiceImpl) mfaChallenge(ctx context.Context, user domain.User) (*domain.LoginResponse, error) {
+	enrollment, err := service.mfaEnrollment(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	enabled := enrollment != nil && enrollment.EnabledAt != nil
+	if !enabled && !service.Config.required(user.Role) {
+		return nil, nil
+	}
+
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := service.Tokens.Create(ctx, &domain.UserToken{
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposeMFAChallenge,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(MFAChallengeTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &domain.LoginResponse{
+		User:                  publicUser(user),
+		MFARequired:           true,
+		MFAEnrollmentRequired: !enabled,
+		MFAToken:              raw,
+	}, nil
+}
+
+// LoginMFA finishes a login with a TOTP or recovery code. Users of a role that
+// requires MFA but who have not enrolled yet first call
+// EnrollMFAWithChallenge; their first code then also enables MFA and the
+// response carries their recovery codes.
+func (service *UserServiceImpl) LoginMFA(ctx context.Context, req domain.MFALoginRequest, client domain.ClientInfo) (domain.LoginResponse, error) {
+	challenge, user, err := service.challengeUser(ctx, req.MFAToken)
+	if err != nil {
+		return domain.LoginResponse{}, err
+	}
+
+	event := domain.LoginEvent{
+		UserID:    &user.ID,
+		Email:     user.Email,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	}
+	release, err := service.Guard.Reserve(ctx, user.Email, client.IP)
+	if err != nil {
+		if errors.Is(err, ErrLoginLocked) {
+			event.Reason = domain.LoginReasonLocked
+			service.Guard.Record(ctx, event)
+		}
+		return domain.LoginResponse{}, err
+	}
+	defer release()
+
+	enrollment, err := service.mfaEnrollment(ctx, user.ID)
+	if err != nil {
+		return domain.LoginResponse{}, err
+	}
+	if enrollment == nil {
+		return domain.LoginResponse{}, ErrMFANotEnrolled
+	}
+
+	var recoveryCodes []string
+	if enrollment.EnabledAt == nil {
+		// First code from a freshly enrolled authenticator
+		if step, ok := totp.Validate(enrollment.Secret, req.Code, time.Now()); ok {
+			recoveryCodes, err = service.enableMFA(ctx, user.ID, step)
+		} else {
+			err = ErrInvalidMFACode
+		}
+	} else {
+		err = service.verifySecondFactor(ctx, enrollment, req.Code, req.RecoveryCode)
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			event.Reason = domain.LoginReasonWrongMFACode
+			service.Guard.Record(ctx, event)
+		}
+		return domain.LoginResponse{}, err
+	}
+
+	if err := service.Tokens.MarkUsed(ctx, challenge.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.LoginResponse{}, ErrInvalidToken
+		}
+		return domain.LoginResponse{}, err
+	}
+
+	event.Success = true
+	service.Guard.Record(ctx, event)
+
+	response, err := service.completeLogin(ctx, user)
+	if err != nil {
+		return domain.LoginResponse{}, err
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// EnrollMFAWithChallenge starts enrollment for a user who was told at login
+// that their role requires MFA, before they have an access token.
+func (service *UserServiceImpl) EnrollMFAWithChallenge(ctx context.Context, mfaToken string) (domain.MFAEnrollment, error) {
+	_, user, err := service.challengeUser(ctx, mfaToken)
+	if err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+	return service.enroll(ctx, user)
+}
+
+// EnrollMFA creates a new pending secret. Calling it again before confirming
+// replaces the secret.
+func (service *UserServiceImpl) EnrollMFA(ctx context.Context, userID int) (domain.MFAEnrollment, error) {
+	user, err := service.Repo.FindByID(ctx, userID)
+	if err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+	return service.enroll(ctx, user)
+}
+
+// ConfirmMFA enables MFA once the user enters a code from the new secret.
+func (service *UserServiceImpl) ConfirmMFA(ctx context.Context, userID int, code string) (domain.MFARecoveryCodes, error) {
+	enrollment, err := service.mfaEnrollment(ctx, userID)
+	if err != nil {
+		return domain.MFARecoveryCodes{}, err
+	}
+	if enrollment == nil {
+		return domain.MFARecoveryCodes{}, ErrMFANotEnrolled
+	}
+	if enrollment.EnabledAt != nil {
+		return domain.MFARecoveryCodes{}, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(enrollment.Secret, code, time.Now())
+	if !ok {
+		return domain.MFARecoveryCodes{}, ErrInvalidMFACode
+	}
+	codes, err := service.enableMFA(ctx, userID, step)
+	if err != nil {
+		return domain.MFARecoveryCodes{}, err
+	}
+	return domain.MFARecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA removes the second factor after checking a current code or a
+// recovery code. Roles that require MFA cannot turn it off.
+func (service *UserServiceImpl) DisableMFA(ctx context.Context, userID int, code string) error {
+	user, err := service.Repo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if service.Config.required(user.Role) {
+		return ErrMFAEnforced
+	}
+
+	enrollment, err := service.mfaEnrollment(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if enrollment == nil || enrollment.EnabledAt == nil {
+		return ErrMFANotEnrolled
+	}
+
+	// A code that looks like a recovery code is checked as one
+	if err := service.verifySecondFactor(ctx, enrollment, code, code); err != nil {
+		return err
+	}
+	return service.MFA.Delete(ctx, userID)
+}
+
+func (service *UserServiceImpl) enroll(ctx context.Context, user domain.User) (domain.MFAEnrollment, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+	if err := service.MFA.SavePending(ctx, user.ID, secret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.MFAEnrollment{}, ErrMFAAlreadyEnabled
+		}
+		return domain.MFAEnrollment{}, err
+	}
+
+	return domain.MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(service.Config.Issuer, user.Email, secret),
+	}, nil
+}
+
+func (service *UserServiceImpl) enableMFA(ctx context.Context, userID int, step int64) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := service.MFA.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	if err := service.MFA.Enable(ctx, userID, step); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verifySecondFactor accepts a TOTP code that was not used before, or an
+// unused recovery code.
+func (service *UserServiceImpl) verifySecondFactor(ctx context.Context, enrollment *domain.UserMFA, code, recoveryCode string) error {
+	if code != "" {
+		if step, ok := totp.Validate(enrollment.Secret, code, time.Now()); ok {
+			fresh, err := service.MFA.UseStep(ctx, enrollment.UserID, step)
+			if err != nil {
+				return err
+			}
+			if fresh {
+				return nil
+			}
+		}
+	}
+
+	if recoveryCode != "" {
+		used, err := service.MFA.UseRecoveryCode(ctx, enrollment.UserID, hashToken(normalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			return err
+		}
+		if used {
+			return nil
+		}
+	}
+
+	return ErrInvalidMFACode
+}
+
+// challengeUser resolves an unexpired, unused challenge token to its user.
+func (service *UserServiceImpl) challengeUser(ctx context.Context, mfaToken string) (*domain.UserToken, domain.User, error) {
+	challenge, err := service.Tokens.GetByHash(ctx, domain.TokenPurposeMFAChallenge, hashToken(mfaToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.User{}, ErrInvalidToken
+		}
+		return nil, domain.User{}, err
+	}
+	if challenge.UsedAt != nil || !time.Now().Before(challenge.ExpiresAt) {
+		return nil, domain.User{}, ErrInvalidToken
+	}
+
+	user, err := service.Repo.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, domain.User{}, err
+	}
+	return challenge, user, nil
+}
+
+// mfaEnrollment returns nil when the user never started enrolling.
+func (service *UserServiceImpl) mfaEnrollment(ctx context.Context, userID int) (*domain.UserMFA, error) {
+	enrollment, err := service.MFA.Get(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return enrollment, err
+}
+
+// newRecoveryCodes returns codes formatted as xxxxx-xxxxx and their hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(b))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashToken(raw)
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/pkg/totp"
+)
+
+var doctorsNeedMFA = MFAConfig{Issuer: "Test", RequiredRoles: []domain.UserRole{domain.RoleDoctor}}
+
+func TestLoginMFARejectsReplayedCode(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.userService(doctorsNeedMFA)
+	user, secret, _ := enrollDoctor(t, f, svc)
+
+	// Enrolling used the current step, the next one is still fresh
+	now := totp.Step(time.Now())
+	code, err := totp.Code(secret, now+1)
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+	res, err := svc.LoginMFA(ctx, domain.MFALoginRequest{MFAToken: passwordLogin(t, svc, user), Code: code}, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("LoginMFA: %v", err)
+	}
+	if res.TokenPair == nil {
+		t.Error("login did not start a session")
+	}
+
+	replay := domain.MFALoginRequest{MFAToken: passwordLogin(t, svc, user), Code: code}
+	if _, err := svc.LoginMFA(ctx, replay, domain.ClientInfo{}); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("replayed code: err = %v, want ErrInvalidMFACode", err)
+	}
+
+	// Nor does a step older than the last one used get in
+	replay.Code, _ = totp.Code(secret, now)
+	if _, err := svc.LoginMFA(ctx, replay, domain.ClientInfo{}); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("older step: err = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestLoginMFARecoveryCodeWorksOnce(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.userService(doctorsNeedMFA)
+	user, _, codes := enrollDoctor(t, f, svc)
+
+	req := domain.MFALoginRequest{MFAToken: passwordLogin(t, svc, user), RecoveryCode: codes[0]}
+	if _, err := svc.LoginMFA(ctx, req, domain.ClientInfo{}); err != nil {
+		t.Fatalf("LoginMFA with a recovery code: %v", err)
+	}
+
+	req.MFAToken = passwordLogin(t, svc, user)
+	if _, err := svc.LoginMFA(ctx, req, domain.ClientInfo{}); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("used recovery code: err = %v, want ErrInvalidMFACode", err)
+	}
+
+	// Codes are accepted however the user types them
+	req.RecoveryCode = " " + strings.ToUpper(strings.ReplaceAll(codes[1], "-", "")) + " "
+	if _, err := svc.LoginMFA(ctx, req, domain.ClientInfo{}); err != nil {
+		t.Errorf("LoginMFA with another recovery code: %v", err)
+	}
+}
+
+func TestLoginMFALocksAfterWrongCodes(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.userService(doctorsNeedMFA)
+	user, secret, _ := enrollDoctor(t, f, svc)
+
+	req := domain.MFALoginRequest{MFAToken: passwordLogin(t, svc, user), Code: wrongCode(t, secret)}
+	for i := range AccountFreeAttempts {
+		if _, err := svc.LoginMFA(ctx, req, domain.ClientInfo{}); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("wrong code %d: err = %v, want ErrInvalidMFACode", i+1, err)
+		}
+	}
+
+	req.Code, _ = totp.Code(secret, totp.Step(time.Now())+1)
+	_, err := svc.LoginMFA(ctx, req, domain.ClientInfo{})
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("right code after %d wrong ones: err = %v, want a LoginLockedError", AccountFreeAttempts, err)
+	}
+	if n := loginReasons(t, f, user.Email)[domain.LoginReasonWrongMFACode]; n != AccountFreeAttempts {
+		t.Errorf("%d wrong codes recorded, want %d", n, AccountFreeAttempts)
+	}
+}
+
+// enrollDoctor creates a doctor and goes through the forced enrollment at the
+// first login. It returns the TOTP secret and the recovery codes.
+func enrollDoctor(t *testing.T, f *fixture, svc UserService) (domain.User, string, []string) {
+	t.Helper()
+
+	ctx := context.Background()
+	user := f.addUser(t, "Ana Putri", domain.RoleDoctor)
+	setPassword(t, f, user.ID, "rahasia1")
+
+	res, err := svc.Login(ctx, domain.LoginRequest{Email: user.Email, Password: "rahasia1"}, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if !res.MFARequired || !res.MFAEnrollmentRequired || res.TokenPair != nil {
+		t.Fatalf("first login of a doctor = %+v, want an enrollment challenge", res)
+	}
+
+	enrollment, err := svc.EnrollMFAWithChallenge(ctx, res.MFAToken)
+	if err != nil {
+		t.Fatalf("EnrollMFAWithChallenge: %v", err)
+	}
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+	done, err := svc.LoginMFA(ctx, domain.MFALoginRequest{MFAToken: res.MFAToken, Code: code}, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("LoginMFA enrolling: %v", err)
+	}
+	if len(done.RecoveryCodes) != RecoveryCodeCount {
+		t.Fatalf("%d recovery codes handed out, want %d", len(done.RecoveryCodes), RecoveryCodeCount)
+	}
+	return user, enrollment.Secret, done.RecoveryCodes
+}
+
+// passwordLogin logs in with the password and returns the MFA challenge token.
+func passwordLogin(t *testing.T, svc UserService, user domain.User) string {
+	t.Helper()
+
+	res, err := svc.Login(context.Background(), domain.LoginRequest{Email: user.Email, Password: "rahasia1"}, domain.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if !res.MFARequired || res.MFAEnrollmentRequired {
+		t.Fatalf("login = %+v, want a code challenge", res)
+	}
+	return res.MFAToken
+}
+
+// wrongCode returns a code that no step in the skew window accepts.
+func wrongCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	for _, candidate := range []string{"000000", "111111", "222222", "333333"} {
+		if _, ok := totp.Validate(secret, candidate, time.Now()); !ok {
+			return candidate
+		}
+	}
+	t.Fatal("no wrong code found")
+	return ""
+}
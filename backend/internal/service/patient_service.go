@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/JinXVIII/BE-Medical-Record/internal/audit"
 	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
 	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
 )
@@ -14,32 +15,62 @@ import (
 var (
 	ErrNotAllowed    = errors.New("aksi tidak diizinkan")
 	ErrInvalidStatus = errors.New("status appointment tidak valid")
+
+	ErrInvalidTransition = errors.New("perubahan status appointment tidak diizinkan")
+	ErrCompleteByRecord  = errors.New("appointment diselesaikan dengan menulis rekam medis")
+
+	ErrDoctorOffDay        = errors.New("dokter tidak praktik pada hari tersebut")
+	ErrSlotOutsideSchedule = errors.New("jam yang dipilih berada di luar jadwal praktik dokter")
+	ErrQuotaFull           = errors.New("kuota pasien untuk jadwal ini sudah penuh")
+
+	ErrNotReschedulable = errors.New("hanya appointment berstatus Pending atau Confirmed yang bisa dijadwalkan ulang")
+	ErrSameSlot         = errors.New("jadwal baru sama dengan jadwal saat ini")
+
+	ErrInvalidDateOfBirth = errors.New("tanggal lahir tidak valid atau berada di masa depan")
 )
 
 type PatientService interface {
 	CreateAppointment(ctx context.Context, userID int64, doctorID int64, appointmentDate time.Time, startTimeSlot string, complaint string, scheduleID *int64) (*domain.Appointment, error)
 	CancelAppointment(ctx context.Context, userID, appointmentID int64) error
 	GetAppointmentHistory(ctx context.Context, userID int64) ([]domain.Appointment, error)
-	GetAppointmentDetail(ctx context.Context, id int64) (*domain.Appointment, error)
+	GetAppointmentDetail(ctx context.Context, actor domain.Actor, id int64) (*domain.Appointment, error)
 	GetDoctorAppointments(ctx context.Context, doctorID int64) ([]domain.Appointment, error)
 	UpdateAppointmentStatus(ctx context.Context, doctorID, appointmentID int64, status domain.AppointmentStatus) error
+	GetDoctorAvailability(ctx context.Context, doctorID int64, from, to time.Time, slotMinutes int) ([]domain.AvailabilitySlot, error)
+	RescheduleByPatient(ctx context.Context, userID, appointmentID int64, appointmentDate time.Time, startTimeSlot, reason string) (*domain.Appointment, error)
+	RescheduleByDoctor(ctx context.Context, userID, doctorID, appointmentID int64, appointmentDate time.Time, startTimeSlot, reason string) (*domain.Appointment, error)
+	GetProfile(ctx context.Context, userID int64) (*domain.Patient, error)
+	UpdateProfile(ctx context.Context, userID int64, req domain.PatientUpdateRequest) (*domain.Patient, error)
+	GetPatientProfile(ctx context.Context, actor domain.Actor, patientID int64) (*domain.Patient, error)
 }
 
 type patientService struct {
-	db              *sql.DB
+	tx              repository.TxRunner
 	appointmentRepo repository.AppointmentRepository
 	patientRepo     repository.PatientRepository
+	scheduleRepo    repository.ScheduleRepository
+	changeRepo      repository.AppointmentChangeRepository
+	policy          AccessPolicy
+	auditor         audit.Auditor
 }
 
 func NewPatientService(
-	db *sql.DB,
+	tx repository.TxRunner,
 	ar repository.AppointmentRepository,
 	pr repository.PatientRepository,
+	sr repository.ScheduleRepository,
+	cr repository.AppointmentChangeRepository,
+	policy AccessPolicy,
+	auditor audit.Auditor,
 ) PatientService {
 	return &patientService{
-		db:              db,
+		tx:              tx,
 		appointmentRepo: ar,
 		patientRepo:     pr,
+		scheduleRepo:    sr,
+		changeRepo:      cr,
+		policy:          policy,
+		auditor:         auditor,
 	}
 }
 
@@ -68,39 +99,75 @@ func (s *patientService) CreateAppointment(
 		return nil, err
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	err = s.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		schedule, err := s.reserveSlotTx(ctx, tx, doctorID, appointmentDate, startTimeSlot, nil)
+		if err != nil {
+			return err
+		}
+		if scheduleID != nil && *scheduleID != int64(schedule.ID) {
+			return ErrSlotOutsideSchedule
+		}
+
+		schedulePtr := &schedule.ID
+		patientEntityID := patient.ID
+		ap = &domain.Appointment{
+			PatientID:       patientEntityID,
+			DoctorID:        int(doctorID),
+			ScheduleID:      schedulePtr,
+			AppointmentDate: appointmentDate,
+			StartTimeSlot:   startTimeSlot,
+			Complaint:       complaint,
+			Status:          domain.AppointmentStatusPending,
+		}
+		return s.appointmentRepo.CreateTx(ctx, tx, ap)
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err != nil {
-			_ = tx.Rollback()
-		}
-	}()
 
-	var schedulePtr *int
-	if scheduleID != nil {
-		v := int(*scheduleID)
-		schedulePtr = &v
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionCreate, domain.AuditResourceAppointment, int64(ap.ID), int64(ap.PatientID)))
+	return ap, nil
+}
+
+// reserveSlotTx finds the schedule that covers the requested slot and locks it
+// for the rest of tx, so concurrent bookings on the same schedule are counted
+// one after another and cannot overbook the patient quota. moving is the
+// appointment being rescheduled, if any; it does not count against the quota
+// of the place it is leaving.
+func (s *patientService) reserveSlotTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	doctorID int64,
+	date time.Time,
+	startTimeSlot string,
+	moving *domain.Appointment,
+) (*domain.DoctorSchedule, error) {
+	schedules, err := s.scheduleRepo.GetByDoctorAndDayTx(ctx, tx, doctorID, workDayOf(date))
+	if err != nil {
+		return nil, err
 	}
-	patientEntityID := patient.ID
-	ap = &domain.Appointment{
-		PatientID:       patientEntityID,
-		DoctorID:        int(doctorID),
-		ScheduleID:      schedulePtr,
-		AppointmentDate: appointmentDate,
-		StartTimeSlot:   startTimeSlot,
-		Complaint:       complaint,
-		Status:          domain.AppointmentStatusPending,
+
+	match, err := matchSchedule(schedules, startTimeSlot)
+	if err != nil {
+		return nil, err
 	}
 
-	if err = s.appointmentRepo.CreateTx(ctx, tx, ap); err != nil {
+	schedule, err := s.scheduleRepo.GetByIDForUpdate(ctx, tx, int64(match.ID))
+	if err != nil {
 		return nil, err
 	}
-	if err = tx.Commit(); err != nil {
+
+	booked, err := s.appointmentRepo.CountActiveByScheduleTx(ctx, tx, int64(schedule.ID), date)
+	if err != nil {
 		return nil, err
 	}
-	return ap, nil
+	if moving != nil && moving.ScheduleID != nil && *moving.ScheduleID == schedule.ID &&
+		truncateDate(moving.AppointmentDate).Equal(truncateDate(date)) {
+		booked--
+	}
+
+	// Re-check against the locked row so the quota we compare with is current
+	return checkSlot([]domain.DoctorSchedule{*schedule}, startTimeSlot, scheduleLoad{schedule.ID: booked})
 }
 
 func (s *patientService) CancelAppointment(ctx context.Context, userID, appointmentID int64) (err error) {
@@ -116,29 +183,15 @@ func (s *patientService) CancelAppointment(ctx context.Context, userID, appointm
 	if ap.PatientID != patient.ID {
 		return ErrNotAllowed
 	}
-	status := strings.TrimSpace(string(ap.Status))
-	isPending := strings.EqualFold(status, string(domain.AppointmentStatusPending))
-	isConfirmed := strings.EqualFold(status, string(domain.AppointmentStatusConfirmed))
-	if !isPending && !isConfirmed {
-		return ErrInvalidStatus
-	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	err = s.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		return s.transitionTx(ctx, tx, appointmentID, domain.AppointmentStatusCancelled)
+	})
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err != nil {
-			_ = tx.Rollback()
-		}
-	}()
 
-	if err = s.appointmentRepo.UpdateStatusTx(ctx, tx, appointmentID, domain.AppointmentStatusRejected); err != nil {
-		return err
-	}
-	if err = tx.Commit(); err != nil {
-		return err
-	}
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionCancel, domain.AuditResourceAppointment, appointmentID, int64(ap.PatientID)))
 	return nil
 }
 
@@ -147,21 +200,71 @@ func (s *patientService) GetAppointmentHistory(ctx context.Context, userID int64
 	if err != nil {
 		return nil, err
 	}
-	return s.appointmentRepo.GetByPatient(ctx, int64(patient.ID))
+
+	appointments, err := s.appointmentRepo.GetByPatient(ctx, int64(patient.ID))
+	if err != nil {
+		return nil, err
+	}
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionList, domain.AuditResourceAppointment, 0, int64(patient.ID)))
+	return appointments, nil
 }
 
-func (s *patientService) GetAppointmentDetail(ctx context.Context, id int64) (*domain.Appointment, error) {
-	return s.appointmentRepo.GetByID(ctx, id)
+// GetAppointmentDetail returns sql.ErrNoRows both when the appointment does
+// not exist and when the actor may not see it.
+func (s *patientService) GetAppointmentDetail(ctx context.Context, actor domain.Actor, id int64) (*domain.Appointment, error) {
+	ap, err := s.appointmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.policy.CanViewAppointment(ctx, actor, ap)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		s.auditor.Record(ctx, deniedEvent(domain.AuditActionView, domain.AuditResourceAppointment, id, int64(ap.PatientID)))
+		return nil, sql.ErrNoRows
+	}
+
+	ap.Changes, err = s.changeRepo.GetByAppointment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionView, domain.AuditResourceAppointment, id, int64(ap.PatientID)))
+	return ap, nil
 }
 
 func (s *patientService) GetDoctorAppointments(ctx context.Context, doctorID int64) ([]domain.Appointment, error) {
-	return s.appointmentRepo.GetByDoctor(ctx, doctorID)
+	appointments, err := s.appointmentRepo.GetByDoctor(ctx, doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// One event per patient in the list, so the trail of each patient shows
+	// the doctor saw their appointments. They are written in one batch, and
+	// an empty list reveals nothing to record.
+	seen := make(map[int]bool)
+	var events []domain.AuditEvent
+	for _, ap := range appointments {
+		if !seen[ap.PatientID] {
+			seen[ap.PatientID] = true
+			events = append(events, auditEvent(domain.AuditActionList, domain.AuditResourceAppointment, 0, int64(ap.PatientID)))
+		}
+	}
+	s.auditor.RecordAll(ctx, events)
+	return appointments, nil
 }
 
-func (s *patientService) UpdateAppointmentStatus(ctx context.Context, doctorID, appointmentID int64, status domain.AppointmentStatus) error {
+func (s *patientService) UpdateAppointmentStatus(ctx context.Context, doctorID, appointmentID int64, status domain.AppointmentStatus) (err error) {
 	if !domain.IsValidAppointmentStatus(string(status)) {
 		return ErrInvalidStatus
 	}
+	// Completed means the examination result exists, so only CreateRecord
+	// may set it
+	if status == domain.AppointmentStatusCompleted {
+		return ErrCompleteByRecord
+	}
 
 	ap, err := s.appointmentRepo.GetByID(ctx, appointmentID)
 	if err != nil {
@@ -171,18 +274,184 @@ func (s *patientService) UpdateAppointmentStatus(ctx context.Context, doctorID,
 		return ErrNotAllowed
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	err = s.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		return s.transitionTx(ctx, tx, appointmentID, status)
+	})
 	if err != nil {
 		return err
 	}
-	defer func() {
+
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionStatus, domain.AuditResourceAppointment, appointmentID, int64(ap.PatientID)))
+	return nil
+}
+
+// transitionTx moves a locked appointment to the next status when the
+// lifecycle in domain allows it.
+func (s *patientService) transitionTx(ctx context.Context, tx *sql.Tx, appointmentID int64, status domain.AppointmentStatus) error {
+	current, err := s.appointmentRepo.GetByIDForUpdateTx(ctx, tx, appointmentID)
+	if err != nil {
+		return err
+	}
+	if !domain.CanTransitionAppointment(current.Status, status) {
+		return ErrInvalidTransition
+	}
+	return s.appointmentRepo.UpdateStatusTx(ctx, tx, appointmentID, status)
+}
+
+// RescheduleByPatient moves one of the patient's own appointments to a new
+// date and slot.
+func (s *patientService) RescheduleByPatient(
+	ctx context.Context,
+	userID int64,
+	appointmentID int64,
+	appointmentDate time.Time,
+	startTimeSlot string,
+	reason string,
+) (*domain.Appointment, error) {
+	patient, err := s.ensurePatient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	change := domain.AppointmentChange{
+		ActorUserID: int(userID),
+		ActorRole:   domain.RolePatient,
+		Reason:      reason,
+	}
+	owns := func(ap *domain.Appointment) bool { return ap.PatientID == patient.ID }
+	return s.reschedule(ctx, appointmentID, appointmentDate, startTimeSlot, change, owns)
+}
+
+// RescheduleByDoctor moves an appointment assigned to the doctor to a new
+// date and slot.
+func (s *patientService) RescheduleByDoctor(
+	ctx context.Context,
+	userID int64,
+	doctorID int64,
+	appointmentID int64,
+	appointmentDate time.Time,
+	startTimeSlot string,
+	reason string,
+) (*domain.Appointment, error) {
+	change := domain.AppointmentChange{
+		ActorUserID: int(userID),
+		ActorRole:   domain.RoleDoctor,
+		Reason:      reason,
+	}
+	owns := func(ap *domain.Appointment) bool { return ap.DoctorID == int(doctorID) }
+	return s.reschedule(ctx, appointmentID, appointmentDate, startTimeSlot, change, owns)
+}
+
+// reschedule re-runs the booking rule for the new slot, moves the appointment
+// and records the change in one transaction. The status is kept as it is.
+func (s *patientService) reschedule(
+	ctx context.Context,
+	appointmentID int64,
+	appointmentDate time.Time,
+	startTimeSlot string,
+	change domain.AppointmentChange,
+	owns func(*domain.Appointment) bool,
+) (ap *domain.Appointment, err error) {
+	err = s.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		ap, err = s.appointmentRepo.GetByIDForUpdateTx(ctx, tx, appointmentID)
 		if err != nil {
-			_ = tx.Rollback()
+			return err
+		}
+		if !owns(ap) {
+			return ErrNotAllowed
+		}
+		if ap.Status != domain.AppointmentStatusPending && ap.Status != domain.AppointmentStatusConfirmed {
+			return ErrNotReschedulable
+		}
+		if truncateDate(ap.AppointmentDate).Equal(truncateDate(appointmentDate)) && sameClock(ap.StartTimeSlot, startTimeSlot) {
+			return ErrSameSlot
 		}
-	}()
 
-	if err = s.appointmentRepo.UpdateStatusTx(ctx, tx, appointmentID, status); err != nil {
-		return err
+		schedule, err := s.reserveSlotTx(ctx, tx, int64(ap.DoctorID), appointmentDate, startTimeSlot, ap)
+		if err != nil {
+			return err
+		}
+
+		change.AppointmentID = ap.ID
+		change.OldDate = ap.AppointmentDate
+		change.OldStartTimeSlot = ap.StartTimeSlot
+		change.NewDate = appointmentDate
+		change.NewStartTimeSlot = startTimeSlot
+
+		ap.ScheduleID = &schedule.ID
+		ap.AppointmentDate = appointmentDate
+		ap.StartTimeSlot = startTimeSlot
+		if err = s.appointmentRepo.RescheduleTx(ctx, tx, ap); err != nil {
+			return err
+		}
+		return s.changeRepo.CreateTx(ctx, tx, &change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionReschedule, domain.AuditResourceAppointment, appointmentID, int64(ap.PatientID)))
+	return ap, nil
+}
+
+// GetProfile returns the demographics of the logged in patient, creating the
+// empty patient row on first use.
+func (s *patientService) GetProfile(ctx context.Context, userID int64) (*domain.Patient, error) {
+	patient, err := s.ensurePatient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.patientRepo.GetByID(ctx, int64(patient.ID))
+	if err != nil {
+		return nil, err
+	}
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionView, domain.AuditResourcePatient, int64(patient.ID), int64(patient.ID)))
+	return profile, nil
+}
+
+func (s *patientService) UpdateProfile(ctx context.Context, userID int64, req domain.PatientUpdateRequest) (*domain.Patient, error) {
+	patient, err := s.ensurePatient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	patient.DateOfBirth = nil
+	if req.DateOfBirth != "" {
+		dob, err := time.Parse("2006-01-02", req.DateOfBirth)
+		if err != nil || dob.After(truncateDate(time.Now())) {
+			return nil, ErrInvalidDateOfBirth
+		}
+		patient.DateOfBirth = &dob
+	}
+	patient.Phone = strings.TrimSpace(req.Phone)
+	patient.Address = strings.TrimSpace(req.Address)
+	patient.BloodType = domain.BloodType(req.BloodType)
+
+	if err := s.patientRepo.Update(ctx, patient); err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionUpdate, domain.AuditResourcePatient, int64(patient.ID), int64(patient.ID)))
+	return s.patientRepo.GetByID(ctx, int64(patient.ID))
+}
+
+// GetPatientProfile lets doctors and admins see a patient's demographics. Like
+// GetAppointmentDetail it answers sql.ErrNoRows when the actor has no access.
+func (s *patientService) GetPatientProfile(ctx context.Context, actor domain.Actor, patientID int64) (*domain.Patient, error) {
+	allowed, err := s.policy.CanViewPatient(ctx, actor, int(patientID))
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		s.auditor.Record(ctx, deniedEvent(domain.AuditActionView, domain.AuditResourcePatient, patientID, patientID))
+		return nil, sql.ErrNoRows
+	}
+
+	patient, err := s.patientRepo.GetByID(ctx, patientID)
+	if err != nil {
+		return nil, err
 	}
-	return tx.Commit()
+	s.auditor.Record(ctx, auditEvent(domain.AuditActionView, domain.AuditResourcePatient, patientID, patientID))
+	return patient, nil
 }
@@ -0,0 +1,433 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+func TestCreateAppointment(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.patientService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	schedule := f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 5)
+	patient := f.addPatient(t, "Budi Santoso")
+	monday := nextWeekday(time.Monday)
+
+	ap, err := svc.CreateAppointment(ctx, patient, int64(doctor.ID), monday, "09:30", "Demam", nil)
+	if err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+	if ap.ID == 0 || ap.Status != domain.AppointmentStatusPending {
+		t.Errorf("got appointment %d in status %s, want a stored Pending one", ap.ID, ap.Status)
+	}
+	if ap.ScheduleID == nil || *ap.ScheduleID != schedule.ID {
+		t.Errorf("ScheduleID = %v, want %d", ap.ScheduleID, schedule.ID)
+	}
+
+	history, err := svc.GetAppointmentHistory(ctx, patient)
+	if err != nil {
+		t.Fatalf("GetAppointmentHistory: %v", err)
+	}
+	if len(history) != 1 || history[0].ID != ap.ID {
+		t.Fatalf("history = %+v, want the new appointment", history)
+	}
+	if history[0].Doctor == nil || history[0].Doctor.User.Name != "Ana Putri" {
+		t.Errorf("history lacks the doctor's name: %+v", history[0].Doctor)
+	}
+	if n := f.auditCount(t, domain.AuditActionCreate, domain.AuditResourceAppointment, domain.AuditOutcomeSuccess); n != 1 {
+		t.Errorf("%d create events audited, want 1", n)
+	}
+}
+
+func TestCreateAppointmentRejectsSlot(t *testing.T) {
+	f := newFixture(t)
+	svc := f.patientService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 5)
+	other := f.addSchedule(t, doctor.ID, domain.WorkDayTuesday, "08:00", "12:00", 5)
+	patient := f.addPatient(t, "Budi Santoso")
+	otherID := int64(other.ID)
+
+	tests := []struct {
+		name       string
+		date       time.Time
+		slot       string
+		scheduleID *int64
+		want       error
+	}{
+		{"day off", nextWeekday(time.Wednesday), "09:00", nil, ErrDoctorOffDay},
+		{"before opening", nextWeekday(time.Monday), "07:30", nil, ErrSlotOutsideSchedule},
+		{"at closing", nextWeekday(time.Monday), "12:00", nil, ErrSlotOutsideSchedule},
+		{"not a time", nextWeekday(time.Monday), "nine", nil, ErrSlotOutsideSchedule},
+		{"other schedule", nextWeekday(time.Monday), "09:00", &otherID, ErrSlotOutsideSchedule},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateAppointment(context.Background(), patient, int64(doctor.ID), tt.date, tt.slot, "", tt.scheduleID)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	history, err := svc.GetAppointmentHistory(context.Background(), patient)
+	if err != nil {
+		t.Fatalf("GetAppointmentHistory: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("rejected bookings were stored: %+v", history)
+	}
+}
+
+func TestCreateAppointmentQuota(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.patientService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 2)
+	monday := nextWeekday(time.Monday)
+
+	first := f.addPatient(t, "Patient One")
+	ap, err := svc.CreateAppointment(ctx, first, int64(doctor.ID), monday, "08:00", "", nil)
+	if err != nil {
+		t.Fatalf("first booking: %v", err)
+	}
+	if _, err := svc.CreateAppointment(ctx, f.addPatient(t, "Patient Two"), int64(doctor.ID), monday, "08:30", "", nil); err != nil {
+		t.Fatalf("second booking: %v", err)
+	}
+
+	third := f.addPatient(t, "Patient Three")
+	if _, err := svc.CreateAppointment(ctx, third, int64(doctor.ID), monday, "09:00", "", nil); !errors.Is(err, ErrQuotaFull) {
+		t.Fatalf("third booking err = %v, want ErrQuotaFull", err)
+	}
+
+	// The same schedule a week later has its own quota
+	if _, err := svc.CreateAppointment(ctx, third, int64(doctor.ID), monday.AddDate(0, 0, 7), "09:00", "", nil); err != nil {
+		t.Fatalf("booking next week: %v", err)
+	}
+
+	// A cancelled appointment gives its place back
+	if err := svc.CancelAppointment(ctx, first, int64(ap.ID)); err != nil {
+		t.Fatalf("CancelAppointment: %v", err)
+	}
+	if _, err := svc.CreateAppointment(ctx, third, int64(doctor.ID), monday, "09:00", "", nil); err != nil {
+		t.Fatalf("booking after cancel: %v", err)
+	}
+}
+
+func TestCreateAppointmentConcurrentQuota(t *testing.T) {
+	const quota, bookings = 3, 12
+
+	f := newFixture(t)
+	svc := f.patientService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", quota)
+	monday := nextWeekday(time.Monday)
+
+	patients := make([]int64, bookings)
+	for i := range patients {
+		patients[i] = f.addPatient(t, "Patient "+string(rune('A'+i)))
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		ok   int
+		full int
+	)
+	for _, patient := range patients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.CreateAppointment(context.Background(), patient, int64(doctor.ID), monday, "10:00", "", nil)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				ok++
+			case errors.Is(err, ErrQuotaFull):
+				full++
+			default:
+				t.Errorf("CreateAppointment: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ok != quota || full != bookings-quota {
+		t.Errorf("%d booked and %d refused, want %d and %d", ok, full, quota, bookings-quota)
+	}
+}
+
+func TestUpdateAppointmentStatus(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.patientService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	stranger := f.addDoctor(t, "Other Doctor")
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 0)
+	patient := f.addPatient(t, "Budi Santoso")
+
+	ap, err := svc.CreateAppointment(ctx, patient, int64(doctor.ID), nextWeekday(time.Monday), "08:00", "", nil)
+	if err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+
+	steps := []struct {
+		name     string
+		doctorID int
+		status   domain.AppointmentStatus
+		want     error
+	}{
+		{"unknown status", doctor.ID, "Lost", ErrInvalidStatus},
+		{"not the assigned doctor", stranger.ID, domain.AppointmentStatusConfirmed, ErrNotAllowed},
+		{"skipping confirmation", doctor.ID, domain.AppointmentStatusCheckedIn, ErrInvalidTransition},
+		{"confirm", doctor.ID, domain.AppointmentStatusConfirmed, nil},
+		{"back to pending", doctor.ID, domain.AppointmentStatusPending, ErrInvalidTransition},
+		{"check in", doctor.ID, domain.AppointmentStatusCheckedIn, nil},
+		{"complete without a record", doctor.ID, domain.AppointmentStatusCompleted, ErrCompleteByRecord},
+		{"cancel after check in", doctor.ID, domain.AppointmentStatusCancelled, ErrInvalidTransition},
+	}
+	for _, step := range steps {
+		err := svc.UpdateAppointmentStatus(ctx, int64(step.doctorID), int64(ap.ID), step.status)
+		if !errors.Is(err, step.want) {
+			t.Fatalf("%s: err = %v, want %v", step.name, err, step.want)
+		}
+	}
+
+	got, err := f.appointments.GetByID(ctx, int64(ap.ID))
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Status != domain.AppointmentStatusCheckedIn {
+		t.Errorf("status = %s, want CheckedIn", got.Status)
+	}
+	if n := f.auditCount(t, domain.AuditActionStatus, domain.AuditResourceAppointment, domain.AuditOutcomeSuccess); n != 2 {
+		t.Errorf("%d status changes audited, want 2", n)
+	}
+}
+
+func TestCancelAppointmentOfAnotherPatient(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.patientService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 0)
+	owner := f.addPatient(t, "Budi Santoso")
+	other := f.addPatient(t, "Citra Lestari")
+
+	ap, err := svc.CreateAppointment(ctx, owner, int64(doctor.ID), nextWeekday(time.Monday), "08:00", "", nil)
+	if err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+	if err := svc.CancelAppointment(ctx, other, int64(ap.ID)); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("err = %v, want ErrNotAllowed", err)
+	}
+	if err := svc.CancelAppointment(ctx, owner, int64(ap.ID)); err != nil {
+		t.Fatalf("CancelAppointment: %v", err)
+	}
+	if err := svc.CancelAppointment(ctx, owner, int64(ap.ID)); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("cancelling twice: err = %v, want ErrInvalidTransition", err)
+	}
+}
+
+func TestRescheduleAppointment(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.patientService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	monday := f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 1)
+	tuesday := f.addSchedule(t, doctor.ID, domain.WorkDayTuesday, "08:00", "12:00", 1)
+	patient := f.addPatient(t, "Budi Santoso")
+	other := f.addPatient(t, "Citra Lestari")
+	mondayDate, tuesdayDate := nextWeekday(time.Monday), nextWeekday(time.Tuesday)
+
+	ap, err := svc.CreateAppointment(ctx, patient, int64(doctor.ID), mondayDate, "08:00", "", nil)
+	if err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+	if _, err := svc.CreateAppointment(ctx, other, int64(doctor.ID), tuesdayDate, "08:00", "", nil); err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+
+	if _, err := svc.RescheduleByPatient(ctx, patient, int64(ap.ID), mondayDate, "08:00:00", ""); !errors.Is(err, ErrSameSlot) {
+		t.Errorf("same slot: err = %v, want ErrSameSlot", err)
+	}
+	if _, err := svc.RescheduleByPatient(ctx, other, int64(ap.ID), mondayDate, "10:00", ""); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("someone else's appointment: err = %v, want ErrNotAllowed", err)
+	}
+	if _, err := svc.RescheduleByPatient(ctx, patient, int64(ap.ID), tuesdayDate, "10:00", ""); !errors.Is(err, ErrQuotaFull) {
+		t.Errorf("into a full schedule: err = %v, want ErrQuotaFull", err)
+	}
+
+	// The appointment's own place does not count against moving it within
+	// the same schedule and day
+	moved, err := svc.RescheduleByPatient(ctx, patient, int64(ap.ID), mondayDate, "10:00", "traffic")
+	if err != nil {
+		t.Fatalf("RescheduleByPatient: %v", err)
+	}
+	if *moved.ScheduleID != monday.ID || moved.StartTimeSlot != "10:00" {
+		t.Errorf("moved to schedule %d at %s, want %d at 10:00", *moved.ScheduleID, moved.StartTimeSlot, monday.ID)
+	}
+
+	// A doctor moves it to next week's Tuesday, where there is room
+	moved, err = svc.RescheduleByDoctor(ctx, int64(doctor.UserID), int64(doctor.ID), int64(ap.ID), tuesdayDate.AddDate(0, 0, 7), "11:00", "")
+	if err != nil {
+		t.Fatalf("RescheduleByDoctor: %v", err)
+	}
+	if *moved.ScheduleID != tuesday.ID {
+		t.Errorf("moved to schedule %d, want %d", *moved.ScheduleID, tuesday.ID)
+	}
+
+	detail, err := svc.GetAppointmentDetail(ctx, domain.Actor{UserID: patient, Role: domain.RolePatient}, int64(ap.ID))
+	if err != nil {
+		t.Fatalf("GetAppointmentDetail: %v", err)
+	}
+	if len(detail.Changes) != 2 {
+		t.Fatalf("%d changes recorded, want 2", len(detail.Changes))
+	}
+	first := detail.Changes[0]
+	if first.ActorRole != domain.RolePatient || first.Reason != "traffic" || first.OldStartTimeSlot != "08:00:00" {
+		t.Errorf("first change = %+v", first)
+	}
+	if detail.Status != domain.AppointmentStatusPending {
+		t.Errorf("status = %s, rescheduling must keep it", detail.Status)
+	}
+}
+
+func TestGetAppointmentDetailHidesOtherPatients(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.patientService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 0)
+	owner := f.addPatient(t, "Budi Santoso")
+	other := f.addPatient(t, "Citra Lestari")
+
+	ap, err := svc.CreateAppointment(ctx, owner, int64(doctor.ID), nextWeekday(time.Monday), "08:00", "", nil)
+	if err != nil {
+		t.Fatalf("CreateAppointment: %v", err)
+	}
+
+	actors := []struct {
+		name  string
+		actor domain.Actor
+		want  error
+	}{
+		{"owner", domain.Actor{UserID: owner, Role: domain.RolePatient}, nil},
+		{"assigned doctor", domain.Actor{UserID: int64(doctor.UserID), Role: domain.RoleDoctor}, nil},
+		{"admin", domain.Actor{UserID: 999, Role: domain.RoleAdmin}, nil},
+		{"other patient", domain.Actor{UserID: other, Role: domain.RolePatient}, sql.ErrNoRows},
+	}
+	for _, tt := range actors {
+		if _, err := svc.GetAppointmentDetail(ctx, tt.actor, int64(ap.ID)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if n := f.auditCount(t, domain.AuditActionView, domain.AuditResourceAppointment, domain.AuditOutcomeDenied); n != 1 {
+		t.Errorf("%d denied views audited, want 1", n)
+	}
+}
+
+func TestGetDoctorAppointmentsAuditsEachPatient(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.patientService()
+
+	doctor := f.addDoctor(t, "Ana Putri")
+	f.addSchedule(t, doctor.ID, domain.WorkDayMonday, "08:00", "12:00", 0)
+	monday := nextWeekday(time.Monday)
+
+	// An empty list has no patient to write an event for
+	if _, err := svc.GetDoctorAppointments(ctx, int64(doctor.ID)); err != nil {
+		t.Fatalf("GetDoctorAppointments: %v", err)
+	}
+	if n := f.auditCount(t, domain.AuditActionList, domain.AuditResourceAppointment, domain.AuditOutcomeSuccess); n != 0 {
+		t.Errorf("%d list events for an empty list, want none", n)
+	}
+
+	var patientIDs []int64
+	for _, name := range []string{"Budi Santoso", "Citra Lestari"} {
+		userID := f.addPatient(t, name)
+		// Two appointments of one patient still make one event
+		for _, slot := range []string{"08:00", "09:00"} {
+			ap, err := svc.CreateAppointment(ctx, userID, int64(doctor.ID), monday, slot, "", nil)
+			if err != nil {
+				t.Fatalf("CreateAppointment: %v", err)
+			}
+			if slot == "08:00" {
+				patientIDs = append(patientIDs, int64(ap.PatientID))
+			}
+		}
+	}
+
+	if _, err := svc.GetDoctorAppointments(ctx, int64(doctor.ID)); err != nil {
+		t.Fatalf("GetDoctorAppointments: %v", err)
+	}
+	if n := f.auditCount(t, domain.AuditActionList, domain.AuditResourceAppointment, domain.AuditOutcomeSuccess); n != len(patientIDs) {
+		t.Errorf("%d list events, want one per patient", n)
+	}
+	for _, id := range patientIDs {
+		events, err := f.audits.List(ctx, domain.AuditFilter{PatientID: id, Action: domain.AuditActionList, Limit: 10})
+		if err != nil {
+			t.Fatalf("list audit events: %v", err)
+		}
+		if len(events) != 1 {
+			t.Errorf("patient %d: %d list events, want 1", id, len(events))
+		}
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.patientService()
+	patient := f.addPatient(t, "Budi Santoso")
+
+	full := domain.PatientUpdateRequest{
+		DateOfBirth: "1990-01-15",
+		Phone:       " 0812-0000-0101 ",
+		Address:     "Jl. Merdeka 1",
+		BloodType:   "A+",
+	}
+	profile, err := svc.UpdateProfile(ctx, patient, full)
+	if err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	if profile.DateOfBirth == nil || profile.DateOfBirth.Format("2006-01-02") != "1990-01-15" ||
+		profile.Phone != "0812-0000-0101" || profile.BloodType != domain.BloodTypeAPlus {
+		t.Errorf("profile = %+v, want the submitted demographics", profile)
+	}
+
+	for _, dob := range []string{"15-01-1990", time.Now().AddDate(0, 0, 1).Format("2006-01-02")} {
+		bad := full
+		bad.DateOfBirth = dob
+		if _, err := svc.UpdateProfile(ctx, patient, bad); !errors.Is(err, ErrInvalidDateOfBirth) {
+			t.Errorf("date of birth %s: err = %v, want ErrInvalidDateOfBirth", dob, err)
+		}
+	}
+
+	// The request replaces the profile, fields left out are cleared
+	profile, err = svc.UpdateProfile(ctx, patient, domain.PatientUpdateRequest{Address: "Jl. Sudirman 2"})
+	if err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	if profile.DateOfBirth != nil || profile.Phone != "" || profile.BloodType != "" || profile.Address != "Jl. Sudirman 2" {
+		t.Errorf("profile = %+v, want only the address left", profile)
+	}
+}
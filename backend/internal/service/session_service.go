@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var ErrRefreshTokenReused = errors.New("refresh token sudah pernah dipakai, sesi dihentikan")
+
+// TokenSigner signs access token claims, see auth.KeySet.
+type TokenSigner interface {
+	Sign(claims jwt.Claims) (string, error)
+}
+
+// SessionService issues access/refresh token pairs and tracks which sessions
+// are still alive.
+type SessionService interface {
+	Issue(ctx context.Context, user domain.User) (domain.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (domain.TokenPair, error)
+	Logout(ctx context.Context, userID int, accessJTI string, all bool) error
+	IsRevoked(ctx context.Context, accessJTI string) (bool, error)
+}
+
+type sessionService struct {
+	tx          repository.TxRunner
+	refreshRepo repository.RefreshTokenRepository
+	userRepo    repository.UserRepository
+	signer      TokenSigner
+}
+
+func NewSessionService(
+	tx repository.TxRunner,
+	rr repository.RefreshTokenRepository,
+	ur repository.UserRepository,
+	signer TokenSigner,
+) SessionService {
+	return &sessionService{
+		tx:          tx,
+		refreshRepo: rr,
+		userRepo:    ur,
+		signer:      signer,
+	}
+}
+
+// Issue starts a new session for the user.
+func (s *sessionService) Issue(ctx context.Context, user domain.User) (pair domain.TokenPair, err error) {
+	familyID, err := newID()
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+
+	err = s.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		pair, err = s.issueTx(ctx, tx, user, familyID)
+		return err
+	})
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	return pair, nil
+}
+
+// Refresh rotates a refresh token. Presenting a token that was already
+// rotated means it leaked, so the whole session family is revoked.
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string) (pair domain.TokenPair, err error) {
+	// A reused token is only known inside the transaction, the family is
+	// revoked after it rolled back
+	var reusedFamily string
+	err = s.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		current, err := s.refreshRepo.GetByHashForUpdateTx(ctx, tx, hashToken(refreshToken))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+		if current.RevokedAt != nil || !time.Now().Before(current.ExpiresAt) {
+			return ErrInvalidToken
+		}
+		if current.RotatedAt != nil {
+			reusedFamily = current.FamilyID
+			return ErrRefreshTokenReused
+		}
+
+		user, err := s.userRepo.FindByID(context.WithValue(ctx, "tx", tx), current.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err = s.refreshRepo.MarkRotatedTx(ctx, tx, current.ID); err != nil {
+			return err
+		}
+		pair, err = s.issueTx(ctx, tx, user, current.FamilyID)
+		return err
+	})
+	if reusedFamily != "" {
+		if revokeErr := s.refreshRepo.RevokeFamily(ctx, reusedFamily); revokeErr != nil {
+			return domain.TokenPair{}, revokeErr
+		}
+	}
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	return pair, nil
+}
+
+// Logout revokes the session the access token belongs to, or every session
+// of the user when all is set.
+func (s *sessionService) Logout(ctx context.Context, userID int, accessJTI string, all bool) error {
+	if all {
+		return s.refreshRepo.RevokeAllForUser(ctx, userID)
+	}
+
+	current, err := s.refreshRepo.GetByAccessJTI(ctx, accessJTI)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	if current.UserID != userID {
+		return ErrInvalidToken
+	}
+	return s.refreshRepo.RevokeFamily(ctx, current.FamilyID)
+}
+
+// IsRevoked reports whether an access token may no longer be used. Unknown
+// jtis count as revoked, every access token is issued with a session row.
+func (s *sessionService) IsRevoked(ctx context.Context, accessJTI string) (bool, error) {
+	if accessJTI == "" {
+		return true, nil
+	}
+
+	current, err := s.refreshRepo.GetByAccessJTI(ctx, accessJTI)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return true, nil
+		}
+		return false, err
+	}
+	return current.RevokedAt != nil, nil
+}
+
+func (s *sessionService) issueTx(ctx context.Context, tx *sql.Tx, user domain.User, familyID string) (domain.TokenPair, error) {
+	jti, err := newID()
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+
+	now := time.Now()
+	accessExpiresAt := now.Add(AccessTokenTTL)
+	accessToken, err := s.signAccessToken(user, jti, now, accessExpiresAt)
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	refresh := &domain.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		AccessJTI: jti,
+		ExpiresAt: now.Add(RefreshTokenTTL),
+	}
+	if err := s.refreshRepo.CreateTx(ctx, tx, refresh); err != nil {
+		return domain.TokenPair{}, err
+	}
+
+	return domain.TokenPair{
+		AccessToken:      accessToken,
+		ExpiresAt:        accessExpiresAt,
+		RefreshToken:     raw,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
+}
+
+func (s *sessionService) signAccessToken(user domain.User, jti string, issuedAt, expiresAt time.Time) (string, error) {
+	claims := domain.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   strconv.Itoa(user.ID),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	return s.signer.Sign(claims)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+)
+
+func TestRefreshRotatesToken(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.sessionService()
+	user := f.addUser(t, "Budi Santoso", domain.RolePatient)
+
+	first, err := svc.Issue(ctx, user)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Fatal("Refresh must hand out new tokens")
+	}
+
+	// Rotating does not end the session, both access tokens stay usable
+	for _, jti := range []string{first.AccessToken, second.AccessToken} {
+		if revoked, err := svc.IsRevoked(ctx, jti); err != nil || revoked {
+			t.Errorf("IsRevoked(%s) = %v, %v; want false", jti, revoked, err)
+		}
+	}
+
+	if _, err := svc.Refresh(ctx, "not-a-token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("unknown token: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.sessionService()
+	user := f.addUser(t, "Budi Santoso", domain.RolePatient)
+
+	first, err := svc.Issue(ctx, user)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	other, err := svc.Issue(ctx, user)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	if _, err := svc.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reused token: err = %v, want ErrRefreshTokenReused", err)
+	}
+	if revoked, err := svc.IsRevoked(ctx, second.AccessToken); err != nil || !revoked {
+		t.Errorf("session of the reused token: IsRevoked = %v, %v; want true", revoked, err)
+	}
+	if _, err := svc.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("refreshing the revoked session: err = %v, want ErrInvalidToken", err)
+	}
+	if revoked, err := svc.IsRevoked(ctx, other.AccessToken); err != nil || revoked {
+		t.Errorf("unrelated session: IsRevoked = %v, %v; want false", revoked, err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.sessionService()
+	user := f.addUser(t, "Budi Santoso", domain.RolePatient)
+	intruder := f.addUser(t, "Citra Lestari", domain.RolePatient)
+
+	phone, err := svc.Issue(ctx, user)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	laptop, err := svc.Issue(ctx, user)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	tablet, err := svc.Issue(ctx, user)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	if err := svc.Logout(ctx, intruder.ID, phone.AccessToken, false); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("someone else's session: err = %v, want ErrInvalidToken", err)
+	}
+	if err := svc.Logout(ctx, user.ID, phone.AccessToken, false); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if revoked, _ := svc.IsRevoked(ctx, phone.AccessToken); !revoked {
+		t.Error("logged out session is still usable")
+	}
+	if revoked, _ := svc.IsRevoked(ctx, laptop.AccessToken); revoked {
+		t.Error("logging out one session ended another")
+	}
+
+	if err := svc.Logout(ctx, user.ID, laptop.AccessToken, true); err != nil {
+		t.Fatalf("Logout all: %v", err)
+	}
+	for _, pair := range []domain.TokenPair{laptop, tablet} {
+		if revoked, _ := svc.IsRevoked(ctx, pair.AccessToken); !revoked {
+			t.Errorf("session %s survived logging out everywhere", pair.AccessToken)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+)
+
+type SpecializationService interface {
+	GetAllSpecializations(ctx context.Context) ([]domain.Specialization, error)
+	GetSpecializationByID(ctx context.Context, id int) (domain.Specialization, error)
+	CreateSpecialization(ctx context.Context, req domain.SpecializationRequest) (domain.Specialization, error)
+	UpdateSpecialization(ctx context.Context, id int, req domain.SpecializationRequest) (domain.Specialization, error)
+	DeleteSpecialization(ctx context.Context, id int) error
+}
+
+type SpecializationServiceImpl struct {
+	SpecializationRepo repository.SpecializationRepository
+}
+
+func NewSpecializationService(specializationRepo repository.SpecializationRepository) SpecializationService {
+	return &SpecializationServiceImpl{
+		SpecializationRepo: specializationRepo,
+	}
+}
+
+func (s *SpecializationServiceImpl) GetAllSpecializations(ctx context.Context) ([]domain.Specialization, error) {
+	return s.SpecializationRepo.GetAll(ctx)
+}
+
+func (s *SpecializationServiceImpl) GetSpecializationByID(ctx context.Context, id int) (domain.Specialization, error) {
+	return s.SpecializationRepo.GetByID(ctx, id)
+}
+
+func (s *SpecializationServiceImpl) CreateSpecialization(ctx context.Context, req domain.SpecializationRequest) (domain.Specialization, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.ensureNameAvailable(ctx, name, 0); err != nil {
+		return domain.Specialization{}, err
+	}
+
+	return s.SpecializationRepo.Create(ctx, domain.Specialization{Name: name})
+}
+
+func (s *SpecializationServiceImpl) UpdateSpecialization(ctx context.Context, id int, req domain.SpecializationRequest) (domain.Specialization, error) {
+	specialization, err := s.SpecializationRepo.GetByID(ctx, id)
+	if err != nil {
+		return specialization, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.ensureNameAvailable(ctx, name, id); err != nil {
+		return domain.Specialization{}, err
+	}
+
+	specialization.Name = name
+	return s.SpecializationRepo.Update(ctx, specialization)
+}
+
+// DeleteSpecialization refuses while doctors still reference the
+// specialization, the doctors FK is ON DELETE RESTRICT.
+func (s *SpecializationServiceImpl) DeleteSpecialization(ctx context.Context, id int) error {
+	if _, err := s.SpecializationRepo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	count, err := s.SpecializationRepo.CountDoctors(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("specialization is still used by %d doctor(s), reassign them first", count)
+	}
+
+	return s.SpecializationRepo.Delete(ctx, id)
+}
+
+func (s *SpecializationServiceImpl) ensureNameAvailable(ctx context.Context, name string, selfID int) error {
+	if name == "" {
+		return errors.New("invalid name: specialization name is required")
+	}
+
+	existing, err := s.SpecializationRepo.FindByName(ctx, name)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != selfID {
+		return fmt.Errorf("specialization %q already exists", name)
+	}
+	return nil
+}
@@ -4,80 +4,220 @@ import (
 	"context"
 	"errors"
 	"log"
-	"os"
-	"time"
+	"strings"
 
 	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
 	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
 	Register(ctx context.Context, user domain.RegisterRequest) (domain.User, error)
-	Login(ctx context.Context, credentials domain.LoginRequest) (domain.LoginResponse, error)
-	GenerateToken(user domain.User) (string, error)
+	CreateUser(ctx context.Context, req domain.CreateUserRequest) (domain.UserInviteResponse, error)
+	CreateAdmin(ctx context.Context, name, email, password string) (domain.User, error)
+	Login(ctx context.Context, credentials domain.LoginRequest, client domain.ClientInfo) (domain.LoginResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string) (domain.TokenPair, error)
+	Logout(ctx context.Context, userID int, accessJTI string, all bool) error
+
+	// Second factor, see mfa.go
+	LoginMFA(ctx context.Context, req domain.MFALoginRequest, client domain.ClientInfo) (domain.LoginResponse, error)
+	EnrollMFAWithChallenge(ctx context.Context, mfaToken string) (domain.MFAEnrollment, error)
+	EnrollMFA(ctx context.Context, userID int) (domain.MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, userID int, code string) (domain.MFARecoveryCodes, error)
+	DisableMFA(ctx context.Context, userID int, code string) error
 }
 
 type UserServiceImpl struct {
-	Repo      repository.UserRepository
-	jwtSecret string
+	Repo     repository.UserRepository
+	Sessions SessionService
+	Accounts AccountService
+	Invites  InviteService
+	Guard    LoginGuard
+	MFA      repository.MFARepository
+	Tokens   repository.UserTokenRepository
+	Config   MFAConfig
 }
 
-func NewUserService(repo repository.UserRepository, jwtSecret string) UserService {
+func NewUserService(
+	repo repository.UserRepository,
+	sessions SessionService,
+	accounts AccountService,
+	invites InviteService,
+	guard LoginGuard,
+	mfa repository.MFARepository,
+	tokens repository.UserTokenRepository,
+	config MFAConfig,
+) UserService {
 	return &UserServiceImpl{
-		Repo:      repo,
-		jwtSecret: jwtSecret,
+		Repo:     repo,
+		Sessions: sessions,
+		Accounts: accounts,
+		Invites:  invites,
+		Guard:    guard,
+		MFA:      mfa,
+		Tokens:   tokens,
+		Config:   config,
 	}
 }
 
+// dummyPasswordHash is compared against when the email is unknown, so a login
+// for a missing account takes as long as one with a wrong password.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+
 func (service *UserServiceImpl) Register(ctx context.Context, req domain.RegisterRequest) (domain.User, error) {
+	// Public sign up only creates patients
+	if req.Role != "" && req.Role != domain.RolePatient {
+		log.Println("ERROR: self-registration with role:", req.Role)
+		return domain.User{}, errors.New("invalid role. Registration is only open to patients")
+	}
+
 	user := domain.User{
 		Name:           req.Name,
 		Email:          req.Email,
 		Password:       req.Password,
-		Role:           req.Role,
+		Role:           domain.RolePatient,
 		ProfilePicture: req.ProfilePicture,
 	}
 
-	// Set default role jika kosong
-	if user.Role == "" {
-		user.Role = domain.RolePatient
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("ERROR:", err)
+		return user, err
+	}
+	user.Password = string(hashedPassword)
+	createdUser, err := service.Repo.Register(ctx, user)
+	if err != nil {
+		return createdUser, err
 	}
 
-	// Validasi role
-	if !domain.IsValidRole(string(user.Role)) {
-		log.Println("ERROR: invalid role:", user.Role)
-		return user, errors.New("invalid role. Valid roles: admin, doctor, patient")
+	// The account is usable right away, a failed verification mail is only logged.
+	if err := service.Accounts.SendVerification(ctx, createdUser); err != nil {
+		log.Println("ERROR: failed to send verification email:", err)
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	return createdUser, nil
+}
+
+// CreateUser creates an account with an unusable random password and returns
+// an invite the user redeems to pick their own.
+func (service *UserServiceImpl) CreateUser(ctx context.Context, req domain.CreateUserRequest) (domain.UserInviteResponse, error) {
+	if req.Role != domain.RoleAdmin && req.Role != domain.RolePatient {
+		return domain.UserInviteResponse{}, errors.New("invalid role. Valid roles: admin, patient")
+	}
+
+	// Nobody ever learns this password, the user picks one when accepting the invite
+	placeholder, _, err := newOpaqueToken()
+	if err != nil {
+		return domain.UserInviteResponse{}, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(placeholder), bcrypt.DefaultCost)
 	if err != nil {
 		log.Println("ERROR:", err)
-		return user, err
+		return domain.UserInviteResponse{}, err
 	}
-	user.Password = string(hashedPassword)
-	return service.Repo.Register(ctx, user)
+
+	createdUser, err := service.Repo.Register(ctx, domain.User{
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: string(hashedPassword),
+		Role:     req.Role,
+	})
+	if err != nil {
+		return domain.UserInviteResponse{}, err
+	}
+	createdUser.Password = ""
+
+	invite, err := service.Invites.Issue(ctx, createdUser.ID)
+	if err != nil {
+		return domain.UserInviteResponse{}, err
+	}
+
+	return domain.UserInviteResponse{User: createdUser, Invite: invite}, nil
+}
+
+// CreateAdmin bootstraps an admin account with a known password. It is only
+// reachable from the create-admin command, never over HTTP.
+func (service *UserServiceImpl) CreateAdmin(ctx context.Context, name, email, password string) (domain.User, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	createdUser, err := service.Repo.Register(ctx, domain.User{
+		Name:     name,
+		Email:    email,
+		Password: string(hashedPassword),
+		Role:     domain.RoleAdmin,
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	createdUser.Password = ""
+	return createdUser, nil
 }
 
-func (service *UserServiceImpl) Login(ctx context.Context, credentials domain.LoginRequest) (domain.LoginResponse, error) {
+// Login checks the credentials and starts a session. Unknown emails and wrong
+// passwords both return ErrInvalidCredentials; repeated failures per account
+// or per IP return a *LoginLockedError until the backoff has passed.
+func (service *UserServiceImpl) Login(ctx context.Context, credentials domain.LoginRequest, client domain.ClientInfo) (domain.LoginResponse, error) {
 	if credentials.Email == "" || credentials.Password == "" {
 		return domain.LoginResponse{}, errors.New("email and password are required")
 	}
 
+	event := domain.LoginEvent{
+		Email:     credentials.Email,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	}
+
+	release, err := service.Guard.Reserve(ctx, credentials.Email, client.IP)
+	if err != nil {
+		if errors.Is(err, ErrLoginLocked) {
+			event.Reason = domain.LoginReasonLocked
+			service.Guard.Record(ctx, event)
+		}
+		return domain.LoginResponse{}, err
+	}
+	defer release()
+
 	user, err := service.Repo.FindByEmail(ctx, credentials.Email)
 	if err != nil {
-		log.Println("ERROR: user not found:", err)
-		return domain.LoginResponse{}, errors.New("invalid email or password")
+		if !strings.Contains(err.Error(), "not found") {
+			return domain.LoginResponse{}, err
+		}
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(credentials.Password))
+		event.Reason = domain.LoginReasonUnknownEmail
+		service.Guard.Record(ctx, event)
+		return domain.LoginResponse{}, ErrInvalidCredentials
 	}
+	event.UserID = &user.ID
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(credentials.Password))
 	if err != nil {
-		log.Println("ERROR: password mismatch:", err)
-		return domain.LoginResponse{}, errors.New("password wrong")
+		event.Reason = domain.LoginReasonWrongPassword
+		service.Guard.Record(ctx, event)
+		return domain.LoginResponse{}, ErrInvalidCredentials
+	}
+
+	// The attempt is only recorded as a success once the second factor is
+	// in, otherwise a correct password would reset the backoff on MFA codes
+	challenge, err := service.mfaChallenge(ctx, user)
+	if err != nil {
+		return domain.LoginResponse{}, err
+	}
+	if challenge != nil {
+		return *challenge, nil
 	}
 
-	token, err := service.GenerateToken(user)
+	event.Success = true
+	service.Guard.Record(ctx, event)
+
+	return service.completeLogin(ctx, user)
+}
+
+// completeLogin starts the session once every factor has been checked.
+func (service *UserServiceImpl) completeLogin(ctx context.Context, user domain.User) (domain.LoginResponse, error) {
+	tokens, err := service.Sessions.Issue(ctx, user)
 	if err != nil {
 		log.Println("ERROR: failed to generate token:", err)
 		return domain.LoginResponse{}, errors.New("failed to generate authentication token")
@@ -87,36 +227,29 @@ func (service *UserServiceImpl) Login(ctx context.Context, credentials domain.Lo
 	log.Printf("User %s logged in successfully", user.Email)
 
 	response := domain.LoginResponse{
-		User: domain.User{
-			ID:        user.ID,
-			Email:     user.Email,
-			Name:      user.Name,
-			Role:      user.Role,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-		},
-		Token:     token,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+		User:      publicUser(user),
+		TokenPair: &tokens,
 	}
 
 	return response, nil
 }
 
-func (service *UserServiceImpl) GenerateToken(user domain.User) (string, error) {
-	// Get JWT secret from environment
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		return "", errors.New("JWT secret not configured")
-	}
-
-	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"role":    user.Role,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+func publicUser(user domain.User) domain.User {
+	return domain.User{
+		ID:        user.ID,
+		Email:     user.Email,
+		Name:      user.Name,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
 	}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+// RefreshToken trades a refresh token for a new access/refresh pair.
+func (service *UserServiceImpl) RefreshToken(ctx context.Context, refreshToken string) (domain.TokenPair, error) {
+	return service.Sessions.Refresh(ctx, refreshToken)
+}
 
-	return token.SignedString([]byte(jwtSecret))
+func (service *UserServiceImpl) Logout(ctx context.Context, userID int, accessJTI string, all bool) error {
+	return service.Sessions.Logout(ctx, userID, accessJTI, all)
 }
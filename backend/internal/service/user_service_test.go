@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.userService(MFAConfig{})
+	user := f.addUser(t, "Budi Santoso", domain.RolePatient)
+	setPassword(t, f, user.ID, "rahasia1")
+
+	// Unknown emails and wrong passwords must look the same to the caller
+	for _, req := range []domain.LoginRequest{
+		{Email: user.Email, Password: "salah"},
+		{Email: "nobody@example.com", Password: "rahasia1"},
+	} {
+		if _, err := svc.Login(ctx, req, domain.ClientInfo{}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Login(%s, %s): err = %v, want ErrInvalidCredentials", req.Email, req.Password, err)
+		}
+	}
+	if _, err := svc.Login(ctx, domain.LoginRequest{Email: user.Email}, domain.ClientInfo{}); err == nil {
+		t.Error("login without a password succeeded")
+	}
+
+	res, err := svc.Login(ctx, domain.LoginRequest{Email: user.Email, Password: "rahasia1"}, domain.ClientInfo{IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if res.TokenPair == nil || res.MFARequired {
+		t.Errorf("login = %+v, want a session without MFA", res)
+	}
+	if res.User.Password != "" {
+		t.Error("login response carries the password hash")
+	}
+
+	reasons := loginReasons(t, f, user.Email)
+	if reasons[""] != 1 || reasons[domain.LoginReasonWrongPassword] != 1 {
+		t.Errorf("login events = %v, want one success and one wrong password", reasons)
+	}
+	if n := loginReasons(t, f, "nobody@example.com")[domain.LoginReasonUnknownEmail]; n != 1 {
+		t.Errorf("%d unknown email events, want 1", n)
+	}
+}
+
+func TestLoginGuardBackoff(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	now := time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC)
+	guard := &loginGuard{events: f.loginEvents, now: func() time.Time { return now }}
+
+	fail := func(email, ip string) {
+		t.Helper()
+		release, err := guard.Reserve(ctx, email, ip)
+		if err != nil {
+			t.Fatalf("Reserve after %s: %v", now.Format(time.TimeOnly), err)
+		}
+		guard.Record(ctx, domain.LoginEvent{Email: email, IP: ip, Reason: domain.LoginReasonWrongPassword})
+		release()
+	}
+	retryAfter := func(email, ip string) time.Duration {
+		t.Helper()
+		release, err := guard.Reserve(ctx, email, ip)
+		if err == nil {
+			release()
+			return 0
+		}
+		var locked *LoginLockedError
+		if !errors.As(err, &locked) {
+			t.Fatalf("Reserve: err = %v, want a LoginLockedError", err)
+		}
+		return locked.RetryAfter
+	}
+
+	// Unknown emails back off the same way, and case does not matter
+	email := "Nobody@Example.com"
+	for range AccountFreeAttempts - 1 {
+		fail(email, "10.0.0.1")
+	}
+	if wait := retryAfter(email, "10.0.0.2"); wait != 0 {
+		t.Fatalf("locked after %d failures for %v", AccountFreeAttempts-1, wait)
+	}
+
+	// Each failure past the free ones doubles the wait, from any IP
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		fail("nobody@example.com", fmt.Sprintf("10.0.1.%d", i))
+		if wait := retryAfter(email, "10.0.2.1"); wait != want {
+			t.Fatalf("after %d failures: retry after %v, want %v", AccountFreeAttempts+i, wait, want)
+		}
+		now = now.Add(want)
+		if wait := retryAfter(email, "10.0.2.1"); wait != 0 {
+			t.Fatalf("still locked for %v once the backoff passed", wait)
+		}
+	}
+
+	// Failures older than the TTL are forgotten
+	now = now.Add(AccountFailureTTL)
+	fail(email, "10.0.3.1")
+	if wait := retryAfter(email, "10.0.3.1"); wait != 0 {
+		t.Errorf("locked for %v by failures older than %v", wait, AccountFailureTTL)
+	}
+
+	// One IP spraying many accounts is throttled on its own count
+	for i := range IPFreeAttempts {
+		fail(fmt.Sprintf("user%d@example.com", i), "10.0.9.9")
+	}
+	if wait := retryAfter("fresh@example.com", "10.0.9.9"); wait != LoginBackoffBase {
+		t.Errorf("IP after %d failures: retry after %v, want %v", IPFreeAttempts, wait, LoginBackoffBase)
+	}
+	if wait := retryAfter("fresh@example.com", "10.0.9.10"); wait != 0 {
+		t.Errorf("another IP is locked for %v", wait)
+	}
+}
+
+func TestLoginParallelGuesses(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	svc := f.userService(MFAConfig{})
+	user := f.addUser(t, "Budi Santoso", domain.RolePatient)
+	setPassword(t, f, user.ID, "rahasia1")
+
+	// Every guess but the free ones must be turned away before bcrypt runs
+	const guesses = 10
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range guesses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			client := domain.ClientInfo{IP: fmt.Sprintf("10.0.0.%d", i)}
+			svc.Login(ctx, domain.LoginRequest{Email: user.Email, Password: "tebakan"}, client)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	reasons := loginReasons(t, f, user.Email)
+	if n := reasons[domain.LoginReasonWrongPassword]; n != AccountFreeAttempts {
+		t.Errorf("%d guesses were checked against the password, want %d", n, AccountFreeAttempts)
+	}
+	if n := reasons[domain.LoginReasonLocked]; n != guesses-AccountFreeAttempts {
+		t.Errorf("%d guesses were locked out, want %d", n, guesses-AccountFreeAttempts)
+	}
+
+	_, err := svc.Login(ctx, domain.LoginRequest{Email: user.Email, Password: "rahasia1"}, domain.ClientInfo{IP: "10.0.1.1"})
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) || locked.RetryAfter <= 0 {
+		t.Errorf("right password during the backoff: err = %v, want a LoginLockedError", err)
+	}
+}
+
+func TestLoginGuardHoldsAccount(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	now := time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	guard := &loginGuard{events: f.loginEvents, now: clock}
+
+	email := "budi@example.com"
+	for range AccountFreeAttempts - 1 {
+		guard.Record(ctx, domain.LoginEvent{Email: email, Reason: domain.LoginReasonWrongPassword})
+	}
+
+	// The last free attempt holds the account
+	release, err := guard.Reserve(ctx, email, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	waiting := make(chan error, 1)
+	go func() {
+		next, err := guard.Reserve(ctx, email, "10.0.0.2")
+		if err == nil {
+			next()
+		}
+		waiting <- err
+	}()
+	select {
+	case err := <-waiting:
+		t.Fatalf("second attempt did not wait for the first: err = %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Another instance has its own locks, so it is not held up; this is the
+	// single-instance limit documented on Reserve
+	other := &loginGuard{events: f.loginEvents, now: clock}
+	otherRelease, err := other.Reserve(ctx, email, "10.0.0.3")
+	if err != nil {
+		t.Fatalf("Reserve on another instance: %v", err)
+	}
+	otherRelease()
+
+	guard.Record(ctx, domain.LoginEvent{Email: email, Reason: domain.LoginReasonWrongPassword})
+	release()
+	if err := <-waiting; !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("waiting attempt: err = %v, want ErrLoginLocked once the failure is recorded", err)
+	}
+}
+
+// setPassword gives a fixture user a real bcrypt hash. It uses the default
+// cost so a comparison is slow enough for parallel logins to overlap.
+func setPassword(t *testing.T, f *fixture, userID int, password string) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	if err := f.users.UpdatePassword(context.Background(), userID, string(hash)); err != nil {
+		t.Fatalf("UpdatePassword: %v", err)
+	}
+}
+
+// loginReasons counts the login events of email by reason, successes under "".
+func loginReasons(t *testing.T, f *fixture, email string) map[string]int {
+	t.Helper()
+
+	events, err := f.loginEvents.List(context.Background(), domain.LoginEventFilter{Email: email, Limit: 1000})
+	if err != nil {
+		t.Fatalf("list login events: %v", err)
+	}
+	reasons := make(map[string]int)
+	for _, e := range events {
+		reasons[e.Reason]++
+	}
+	return reasons
+}
@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"os" // Tambahkan ini untuk membaca "kabel" Railway
 	"time"
@@ -9,20 +11,33 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 )
 
+// GetConnection opens the database named by DB_URL, whose scheme picks MySQL,
+// PostgreSQL or SQLite (see ParseDatabaseURL). Without it MYSQL_URL, a plain MySQL
+// DSN, and finally the local XAMPP database are used.
 func GetConnection() (*sql.DB, error) {
-	// 1. Ambil "kabel" koneksi dari dashboard Railway
-	dsn := os.Getenv("MYSQL_URL")
+	driverName, dsn := "mysql", ""
 
-	// 2. Jika kabel kosong (berarti kamu lagi jalanin di laptop sendiri)
-	if dsn == "" {
-		dsn = "root:@tcp(localhost:3306)/medical_record_db?parseTime=true"
-		log.Println("Database: Menggunakan koneksi lokal (XAMPP)")
+	if url := os.Getenv("DB_URL"); url != "" {
+		var err error
+		if driverName, dsn, err = ParseDatabaseURL(url); err != nil {
+			return nil, err
+		}
+		log.Println("Database: Menggunakan DB_URL dengan driver", driverName)
 	} else {
-		log.Println("Database: Berhasil membaca alamat dari Railway")
+		// 1. Ambil "kabel" koneksi dari dashboard Railway
+		dsn = os.Getenv("MYSQL_URL")
+
+		// 2. Jika kabel kosong (berarti kamu lagi jalanin di laptop sendiri)
+		if dsn == "" {
+			dsn = "root:@tcp(localhost:3306)/medical_record_db?parseTime=true"
+			log.Println("Database: Menggunakan koneksi lokal (XAMPP)")
+		} else {
+			log.Println("Database: Berhasil membaca alamat dari Railway")
+		}
 	}
 
 	// 3. Gunakan variabel dsn (bukan tulisan manual lagi)
-	db, err := sql.Open("mysql", dsn)
+	db, err := sql.Open(driverName, dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -32,16 +47,37 @@ func GetConnection() (*sql.DB, error) {
 	db.SetConnMaxIdleTime(5 * time.Minute)
 	db.SetConnMaxLifetime(60 * time.Minute)
 
+	if DialectOf(db) == SQLite {
+		// An in-memory database is gone once its last connection closes
+		db.SetConnMaxIdleTime(0)
+		db.SetConnMaxLifetime(0)
+	}
+
 	return db, nil
 }
 
-func InitializeDatabase(db *sql.DB) error {
-	if err := RunMigrations(db); err != nil {
+// InitializeDatabase checks that the schema is up to date. Migrations
+// normally run through the migrate subcommand; with autoMigrate set pending
+// ones are applied here instead.
+func InitializeDatabase(db *sql.DB, autoMigrate bool) error {
+	ctx := context.Background()
+	migrator, err := NewMigrator(db)
+	if err != nil {
 		return err
 	}
 
-	if err := SeedAllData(db); err != nil {
-		log.Println("WARNING: Failed to seed data:", err)
+	if autoMigrate {
+		if _, err := migrator.Up(ctx); err != nil {
+			return err
+		}
+	} else {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d migration belum diterapkan (mulai dari %s), jalankan `migrate up`", len(pending), pending[0])
+		}
 	}
 
 	return nil
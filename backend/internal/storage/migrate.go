@@ -0,0 +1,410 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migrations live in migrations/<dialect>/ as NNNN_name.up.sql and
+// NNNN_name.down.sql; every dialect has the same versions. A file may hold
+// several statements, each ending with a semicolon at the end of a line.
+//
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// migrationLock names the advisory lock that keeps replicas booting at the
+// same time from migrating concurrently.
+const migrationLock = "medical_record.schema_migrations"
+
+// MigrationLockTimeout is how long a migration waits for another one to finish.
+const MigrationLockTimeout = time.Minute
+
+// ErrDropsData is returned by Down when one of the migrations to roll back
+// drops tables, and so the data in them, without dropData being set.
+var ErrDropsData = errors.New("rollback menghapus tabel beserta datanya")
+
+type Migration struct {
+	Version int64
+	Name    string
+	up      string
+	down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies the embedded migrations and records them in
+// schema_migrations.
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	dialect := DialectOf(db)
+	migrations, err := loadMigrations(migrationFiles, path.Join("migrations", dialect.migrationsDir()))
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+// preconditions run before the migration of the same version and stop Up
+// with an explanation when the existing data would make its script fail.
+// On MySQL the statements before the failing one would already be committed.
+var preconditions = map[int64]func(ctx context.Context, conn *sql.Conn) error{
+	3: checkNoDuplicateRecords,
+}
+
+// checkNoDuplicateRecords makes sure the unique key of 0003 can be added: no
+// appointment may have more than one medical record.
+func checkNoDuplicateRecords(ctx context.Context, conn *sql.Conn) error {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT appointment_id
+		FROM medical_records
+		GROUP BY appointment_id
+		HAVING COUNT(*) > 1
+		ORDER BY appointment_id
+		LIMIT 10
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		return fmt.Errorf("appointment %s punya lebih dari satu rekam medis; gabungkan atau hapus duplikatnya di medical_records, lalu jalankan migrasi lagi", strings.Join(ids, ", "))
+	}
+	return nil
+}
+
+// Up applies every pending migration in version order and returns the ones
+// it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if check := preconditions[mig.Version]; check != nil {
+				if err := check(ctx, conn); err != nil {
+					return fmt.Errorf("migration %s: %w", mig, err)
+				}
+			}
+			err := m.apply(ctx, conn, mig, mig.up,
+				"INSERT INTO schema_migrations (version, name) VALUES (?, ?)", mig.Version, mig.Name)
+			if err != nil {
+				return err
+			}
+			log.Printf("Migration %s applied", mig)
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the last steps applied migrations, newest first. Unless
+// dropData is set it refuses, before touching anything, when one of them
+// drops tables: rolling back the baseline would take the audit trail with it.
+func (m *Migrator) Down(ctx context.Context, steps int, dropData bool) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("jumlah langkah harus positif")
+	}
+
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int64, 0, len(applied))
+		for v := range applied {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		var rollback []Migration
+		for _, v := range versions[:min(steps, len(versions))] {
+			mig, ok := m.find(v)
+			if !ok {
+				return fmt.Errorf("migration %04d sudah diterapkan tetapi tidak dikenal oleh versi aplikasi ini", v)
+			}
+			if mig.dropsTables() && !dropData {
+				return fmt.Errorf("%w (migration %s)", ErrDropsData, mig)
+			}
+			rollback = append(rollback, mig)
+		}
+
+		for _, mig := range rollback {
+			err := m.apply(ctx, conn, mig, mig.down,
+				"DELETE FROM schema_migrations WHERE version = ?", mig.Version)
+			if err != nil {
+				return err
+			}
+			log.Printf("Migration %s rolled back", mig)
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status lists every known migration and when it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := m.ensureMigrationsTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := MigrationStatus{Migration: mig}
+		if at, ok := applied[mig.Version]; ok {
+			s.AppliedAt = &at
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+// Pending returns the migrations that have not been applied yet.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, s := range status {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// dropsTables reports whether rolling mig back drops a table.
+func (mig Migration) dropsTables() bool {
+	for _, stmt := range splitStatements(mig.down) {
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(stmt)), "DROP TABLE") {
+			return true
+		}
+	}
+	return false
+}
+
+func (mig Migration) String() string {
+	return fmt.Sprintf("%04d_%s", mig.Version, mig.Name)
+}
+
+// apply runs one migration script and records it in schema_migrations.
+// MySQL commits DDL implicitly, so a script that fails halfway can leave part
+// of its changes behind; keep each migration small. PostgreSQL rolls the
+// whole script back.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration, script, record string, args ...any) (err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, stmt := range splitStatements(script) {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migration %s: %w", mig, err)
+		}
+	}
+	if _, err = tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// locked runs fn on a connection holding the migration lock. Advisory locks
+// belong to the session, so everything has to run on that one connection.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.dialect.lock(ctx, conn, migrationLock, MigrationLockTimeout); err != nil {
+		return err
+	}
+	defer func() {
+		// Release even when ctx is cancelled, the pooled connection would keep the lock otherwise
+		if err := m.dialect.unlock(context.WithoutCancel(ctx), conn, migrationLock); err != nil {
+			log.Println("ERROR releasing migration lock:", err)
+		}
+	}()
+
+	if err := m.ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig, true
+		}
+	}
+	return Migration{}, false
+}
+
+func (m *Migrator) ensureMigrationsTable(ctx context.Context, conn *sql.Conn) error {
+	if _, err := conn.ExecContext(ctx, m.dialect.createMigrationsTable()); err != nil {
+		log.Println("ERROR creating schema_migrations table:", err)
+		return err
+	}
+	return nil
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// loadMigrations pairs the up and down scripts in dir and sorts them by
+// version.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		file := entry.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(file, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("migration %s: nama harus NNNN_nama.up.sql atau NNNN_nama.down.sql", file)
+		}
+		number, name, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(number, 10, 64)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: nomor versi tidak valid", file)
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, exists := byVersion[version]
+		if !exists {
+			mig = &Migration{Version: version, Name: name}
+			byVersion[version] = mig
+		} else if mig.Name != name {
+			return nil, fmt.Errorf("migration %04d dipakai oleh %s dan %s", version, mig.Name, name)
+		}
+		if direction == "up" {
+			mig.up = string(data)
+		} else {
+			mig.down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.up == "" || mig.down == "" {
+			return nil, fmt.Errorf("migration %s: file up dan down harus ada", mig)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitStatements breaks a script into statements and drops -- comment
+// lines. The MySQL driver refuses multi-statement Exec calls, so each one is
+// sent on its own. Semicolons inside a $$ quoted function body do not end the
+// statement.
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		inBody     bool
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.Count(line, "$$")%2 == 1 {
+			inBody = !inBody
+		}
+		if !inBody && strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		statements = append(statements, stmt)
+	}
+	return statements
+}
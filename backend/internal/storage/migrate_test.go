@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDialectsShareVersions(t *testing.T) {
+	var want []string
+	for _, dir := range []string{"mysql", "postgres", "sqlite"} {
+		migrations, err := loadMigrations(migrationFiles, path.Join("migrations", dir))
+		if err != nil {
+			t.Fatalf("load %s: %v", dir, err)
+		}
+		var got []string
+		for _, mig := range migrations {
+			got = append(got, mig.String())
+		}
+		if want == nil {
+			want = got
+			continue
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s migrations = %v, want %v like mysql", dir, got, want)
+		}
+	}
+}
+
+func TestDownRefusesToDropData(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("DB_URL", "sqlite://:memory:")
+	db, err := GetConnection()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	// 0003 drops an index and 0002 only changes MySQL, rolling them back
+	// here drops no table
+	if done, err := migrator.Down(ctx, 2, false); err != nil || len(done) != 2 {
+		t.Fatalf("Down 0003 and 0002 = %v, %v; want them rolled back", done, err)
+	}
+	if _, err := migrator.Down(ctx, 1, false); !errors.Is(err, ErrDropsData) {
+		t.Fatalf("Down 0001: err = %v, want ErrDropsData", err)
+	}
+	if _, err := db.ExecContext(ctx, "SELECT COUNT(*) FROM audit_events"); err != nil {
+		t.Fatalf("audit_events is gone after the refused rollback: %v", err)
+	}
+
+	if _, err := migrator.Down(ctx, 1, true); err != nil {
+		t.Fatalf("Down 0001 with dropData: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "SELECT COUNT(*) FROM users"); err == nil {
+		t.Error("users survived rolling back the baseline")
+	}
+}
+
+func TestUpRefusesDuplicateRecords(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("DB_URL", "sqlite://:memory:")
+	db, err := GetConnection()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if _, err := migrator.Down(ctx, 1, false); err != nil {
+		t.Fatalf("Down 0003: %v", err)
+	}
+
+	// Two records for appointment 1, as the boot-time schema allowed
+	for _, stmt := range []string{
+		"INSERT INTO users (name, email, password, role) VALUES ('Budi', 'budi@example.com', 'x', 'patient')",
+		"INSERT INTO users (name, email, password, role) VALUES ('Ana', 'ana@example.com', 'x', 'doctor')",
+		"INSERT INTO specializations (name) VALUES ('Umum')",
+		"INSERT INTO patients (user_id) VALUES (1)",
+		"INSERT INTO doctors (user_id, specialization_id, gender) VALUES (2, 1, 'female')",
+		"INSERT INTO appointments (patient_id, doctor_id, appointment_date, start_time_slot) VALUES (1, 1, '2026-03-09', '08:00:00')",
+		"INSERT INTO medical_records (appointment_id, diagnosis) VALUES (1, 'Influenza')",
+		"INSERT INTO medical_records (appointment_id, diagnosis) VALUES (1, 'Influenza, amended')",
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	_, err = migrator.Up(ctx)
+	if err == nil || !strings.Contains(err.Error(), "0003_one_record_per_appointment") || !strings.Contains(err.Error(), "appointment 1 ") {
+		t.Fatalf("Up with duplicates: err = %v, want it to name the migration and appointment 1", err)
+	}
+	if pending, err := migrator.Pending(ctx); err != nil || len(pending) != 1 {
+		t.Fatalf("Pending = %v, %v; want 0003 still pending", pending, err)
+	}
+
+	if _, err := db.ExecContext(ctx, "DELETE FROM medical_records WHERE id = 1"); err != nil {
+		t.Fatalf("remove duplicate: %v", err)
+	}
+	if done, err := migrator.Up(ctx); err != nil || len(done) != 1 {
+		t.Fatalf("Up after the cleanup = %v, %v; want 0003 applied", done, err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO medical_records (appointment_id) VALUES (1)"); err == nil {
+		t.Error("second record for an appointment inserted after 0003")
+	}
+}
+
+func TestDropsTables(t *testing.T) {
+	for _, dir := range []string{"mysql", "postgres", "sqlite"} {
+		migrations, err := loadMigrations(migrationFiles, path.Join("migrations", dir))
+		if err != nil {
+			t.Fatalf("load %s: %v", dir, err)
+		}
+		if !migrations[0].dropsTables() {
+			t.Errorf("%s %s: dropsTables = false, rolling back the baseline drops everything", dir, migrations[0])
+		}
+	}
+
+	if (Migration{down: "-- Nothing to do\n"}).dropsTables() {
+		t.Error("a comment-only script drops tables")
+	}
+	if (Migration{down: "ALTER TABLE users DROP COLUMN email_verified_at;"}).dropsTables() {
+		t.Error("dropping a column counted as dropping a table")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "one per semicolon",
+			script: "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+			want:   []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name:   "spanning lines",
+			script: "CREATE TABLE a (\n    id INT\n);\n",
+			want:   []string{"CREATE TABLE a (\n    id INT\n)"},
+		},
+		{
+			name:   "comments and blank lines dropped",
+			script: "-- Baseline\n\nDROP TABLE a;\n  -- indented\nDROP TABLE b;\n",
+			want:   []string{"DROP TABLE a", "DROP TABLE b"},
+		},
+		{
+			name:   "comment only",
+			script: "-- Nothing to do\n",
+			want:   nil,
+		},
+		{
+			name:   "missing final semicolon",
+			script: "DROP TABLE a;\nDROP TABLE b",
+			want:   []string{"DROP TABLE a", "DROP TABLE b"},
+		},
+		{
+			name: "function body kept whole",
+			script: "CREATE FUNCTION touch() RETURNS trigger AS $$\nBEGIN\n    NEW.updated_at = NOW();\n    RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\n" +
+				"CREATE TABLE a (id INT);\n",
+			want: []string{
+				"CREATE FUNCTION touch() RETURNS trigger AS $$\nBEGIN\n    NEW.updated_at = NOW();\n    RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql",
+				"CREATE TABLE a (id INT)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !slices.Equal(got, tt.want) {
+				t.Errorf("splitStatements = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS medical_records;
+DROP TABLE IF EXISTS appointments;
+DROP TABLE IF EXISTS doctor_schedules;
+DROP TABLE IF EXISTS patients;
+DROP TABLE IF EXISTS doctors;
+DROP TABLE IF EXISTS specializations;
+DROP TABLE IF EXISTS users;
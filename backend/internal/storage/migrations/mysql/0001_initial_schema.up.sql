@@ -0,0 +1,88 @@
+-- Baseline schema, exactly the tables the server created at boot before
+-- versioned migrations. IF NOT EXISTS lets those databases adopt this version
+-- without changes; 0002 then brings them up to date.
+
+CREATE TABLE IF NOT EXISTS users (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	email VARCHAR(255) UNIQUE NOT NULL,
+	password VARCHAR(255) NOT NULL,
+	role ENUM('admin', 'doctor', 'patient') DEFAULT 'patient',
+	profile_picture VARCHAR(255),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS specializations (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL UNIQUE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS doctors (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	user_id INT NOT NULL,
+	specialization_id INT NOT NULL,
+	gender ENUM('male', 'female') NOT NULL,
+	address TEXT,
+	license_number VARCHAR(255) UNIQUE,
+	is_active BOOLEAN DEFAULT TRUE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (specialization_id) REFERENCES specializations(id) ON DELETE RESTRICT
+);
+
+CREATE TABLE IF NOT EXISTS patients (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	user_id INT NOT NULL UNIQUE,
+	date_of_birth DATE,
+	phone VARCHAR(20),
+	address TEXT,
+	blood_type ENUM('A+', 'A-', 'B+', 'B-', 'AB+', 'AB-', 'O+', 'O-'),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS doctor_schedules (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	doctor_id INT NOT NULL,
+	work_day ENUM('monday', 'tuesday', 'wednesday', 'thursday', 'friday', 'saturday', 'sunday') NOT NULL,
+	start_time TIME NOT NULL,
+	end_time TIME NOT NULL,
+	patient_quota INT DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (doctor_id) REFERENCES doctors(id) ON DELETE CASCADE,
+	UNIQUE KEY unique_schedule (doctor_id, work_day, start_time, end_time)
+);
+
+CREATE TABLE IF NOT EXISTS appointments (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	patient_id INT NOT NULL,
+	doctor_id INT NOT NULL,
+	schedule_id INT NULL,
+	appointment_date DATE NOT NULL,
+	start_time_slot TIME NOT NULL,
+	complaint TEXT,
+	status ENUM('Pending', 'Confirmed', 'Rejected', 'Completed') DEFAULT 'Pending',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (patient_id) REFERENCES patients(id) ON DELETE CASCADE,
+	FOREIGN KEY (doctor_id) REFERENCES doctors(id) ON DELETE CASCADE,
+	FOREIGN KEY (schedule_id) REFERENCES doctor_schedules(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS medical_records (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	appointment_id INT NOT NULL,
+	diagnosis TEXT,
+	prescription TEXT,
+	doctor_notes TEXT,
+	examination_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (appointment_id) REFERENCES appointments(id) ON DELETE CASCADE
+);
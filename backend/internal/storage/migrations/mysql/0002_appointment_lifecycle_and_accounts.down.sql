@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
+DROP TABLE IF EXISTS login_events;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS user_tokens;
+DROP TABLE IF EXISTS appointment_changes;
+
+-- Appointments in one of the new statuses fall back to the closest old one
+UPDATE appointments SET status = 'Rejected' WHERE status IN ('Cancelled', 'NoShow');
+UPDATE appointments SET status = 'Confirmed' WHERE status = 'CheckedIn';
+ALTER TABLE appointments MODIFY COLUMN status ENUM('Pending', 'Confirmed', 'Rejected', 'Completed') DEFAULT 'Pending';
+
+ALTER TABLE users DROP COLUMN email_verified_at;
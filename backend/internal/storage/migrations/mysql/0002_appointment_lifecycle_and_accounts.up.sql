@@ -0,0 +1,107 @@
+-- Everything added on top of the boot-time schema: email verification,
+-- the full appointment lifecycle and the tables for reschedules, tokens,
+-- sessions, login throttling, MFA and the audit trail. One record per
+-- appointment follows in 0003.
+
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP NULL DEFAULT NULL AFTER profile_picture;
+
+ALTER TABLE appointments MODIFY COLUMN status ENUM('Pending', 'Confirmed', 'Rejected', 'Completed', 'Cancelled', 'NoShow', 'CheckedIn') DEFAULT 'Pending';
+
+CREATE TABLE appointment_changes (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	appointment_id INT NOT NULL,
+	old_appointment_date DATE NOT NULL,
+	old_start_time_slot TIME,
+	new_appointment_date DATE NOT NULL,
+	new_start_time_slot TIME,
+	actor_user_id INT,
+	actor_role ENUM('admin', 'doctor', 'patient') NOT NULL,
+	reason TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (appointment_id) REFERENCES appointments(id) ON DELETE CASCADE,
+	FOREIGN KEY (actor_user_id) REFERENCES users(id) ON DELETE SET NULL,
+	INDEX idx_appointment_changes_appointment (appointment_id)
+);
+
+CREATE TABLE user_tokens (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	user_id INT NOT NULL,
+	purpose VARCHAR(32) NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	used_at TIMESTAMP NULL DEFAULT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	UNIQUE KEY unique_token_hash (token_hash),
+	INDEX idx_user_tokens_user_purpose (user_id, purpose)
+);
+
+CREATE TABLE refresh_tokens (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	user_id INT NOT NULL,
+	family_id CHAR(32) NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	access_jti CHAR(32) NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	rotated_at TIMESTAMP NULL DEFAULT NULL,
+	revoked_at TIMESTAMP NULL DEFAULT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	UNIQUE KEY unique_refresh_token_hash (token_hash),
+	UNIQUE KEY unique_refresh_access_jti (access_jti),
+	INDEX idx_refresh_tokens_family (family_id),
+	INDEX idx_refresh_tokens_user (user_id)
+);
+
+CREATE TABLE login_events (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	user_id INT NULL,
+	email VARCHAR(255) NOT NULL,
+	ip VARCHAR(45) NOT NULL,
+	user_agent VARCHAR(255) NOT NULL DEFAULT '',
+	success BOOLEAN NOT NULL,
+	reason VARCHAR(32) NOT NULL DEFAULT '',
+	created_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+	INDEX idx_login_events_email (email, created_at),
+	INDEX idx_login_events_ip (ip, created_at)
+);
+
+CREATE TABLE user_mfa (
+	user_id INT PRIMARY KEY,
+	secret VARCHAR(64) NOT NULL,
+	enabled_at TIMESTAMP NULL DEFAULT NULL,
+	last_used_step BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE mfa_recovery_codes (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	user_id INT NOT NULL,
+	code_hash CHAR(64) NOT NULL,
+	used_at TIMESTAMP NULL DEFAULT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	UNIQUE KEY unique_recovery_code (user_id, code_hash)
+);
+
+-- No foreign keys on purpose, the trail has to outlive deleted users and
+-- patients.
+CREATE TABLE audit_events (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	actor_user_id INT NULL,
+	actor_role VARCHAR(16) NOT NULL DEFAULT '',
+	action VARCHAR(32) NOT NULL,
+	outcome VARCHAR(16) NOT NULL,
+	resource_type VARCHAR(32) NOT NULL,
+	resource_id VARCHAR(64) NOT NULL DEFAULT '',
+	patient_id INT NULL,
+	ip VARCHAR(45) NOT NULL DEFAULT '',
+	request_id VARCHAR(128) NOT NULL DEFAULT '',
+	created_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+	INDEX idx_audit_events_created (created_at),
+	INDEX idx_audit_events_actor (actor_user_id, created_at),
+	INDEX idx_audit_events_patient (patient_id, created_at),
+	INDEX idx_audit_events_resource (resource_type, resource_id)
+);
@@ -0,0 +1,3 @@
+-- The index backs the foreign key until the unique key is gone
+ALTER TABLE medical_records ADD INDEX idx_medical_records_appointment (appointment_id);
+ALTER TABLE medical_records DROP INDEX unique_appointment_record;
@@ -0,0 +1,5 @@
+-- One medical record per appointment. The migrator refuses to run this while
+-- medical_records holds duplicates (see checkNoDuplicateRecords), so the
+-- ALTER cannot fail halfway on existing data.
+
+ALTER TABLE medical_records ADD UNIQUE KEY unique_appointment_record (appointment_id);
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
+DROP TABLE IF EXISTS login_events;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS user_tokens;
+DROP TABLE IF EXISTS appointment_changes;
+DROP TABLE IF EXISTS medical_records;
+DROP TABLE IF EXISTS appointments;
+DROP TABLE IF EXISTS doctor_schedules;
+DROP TABLE IF EXISTS patients;
+DROP TABLE IF EXISTS doctors;
+DROP TABLE IF EXISTS specializations;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,197 @@
+-- Baseline schema, the SQLite twin of mysql/0001 and 0002 together. ENUM
+-- columns become VARCHAR with a CHECK constraint. Timestamps are stored as
+-- UTC text with millisecond precision, the same format the sqlite driver
+-- writes (see sqliteTimeFormat), so they compare correctly as strings. ON
+-- UPDATE CURRENT_TIMESTAMP becomes an AFTER UPDATE trigger that only fires
+-- when the statement left updated_at alone.
+
+CREATE TABLE users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(255) NOT NULL,
+	email VARCHAR(255) UNIQUE NOT NULL,
+	password VARCHAR(255) NOT NULL,
+	role VARCHAR(16) DEFAULT 'patient' CHECK (role IN ('admin', 'doctor', 'patient')),
+	profile_picture VARCHAR(255),
+	email_verified_at TIMESTAMP NULL DEFAULT NULL,
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE TRIGGER users_updated_at AFTER UPDATE ON users
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+	BEGIN UPDATE users SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id; END;
+
+CREATE TABLE specializations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(255) NOT NULL UNIQUE,
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE TRIGGER specializations_updated_at AFTER UPDATE ON specializations
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+	BEGIN UPDATE specializations SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id; END;
+
+CREATE TABLE doctors (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	specialization_id INT NOT NULL REFERENCES specializations(id) ON DELETE RESTRICT,
+	gender VARCHAR(8) NOT NULL CHECK (gender IN ('male', 'female')),
+	address TEXT,
+	license_number VARCHAR(255) UNIQUE,
+	is_active BOOLEAN DEFAULT TRUE,
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE TRIGGER doctors_updated_at AFTER UPDATE ON doctors
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+	BEGIN UPDATE doctors SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id; END;
+
+CREATE TABLE patients (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+	date_of_birth DATE,
+	phone VARCHAR(20),
+	address TEXT,
+	blood_type VARCHAR(3) CHECK (blood_type IN ('A+', 'A-', 'B+', 'B-', 'AB+', 'AB-', 'O+', 'O-')),
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE TRIGGER patients_updated_at AFTER UPDATE ON patients
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+	BEGIN UPDATE patients SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id; END;
+
+CREATE TABLE doctor_schedules (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	doctor_id INT NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+	work_day VARCHAR(9) NOT NULL CHECK (work_day IN ('monday', 'tuesday', 'wednesday', 'thursday', 'friday', 'saturday', 'sunday')),
+	start_time TIME NOT NULL,
+	end_time TIME NOT NULL,
+	patient_quota INT DEFAULT 0,
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	CONSTRAINT unique_schedule UNIQUE (doctor_id, work_day, start_time, end_time)
+);
+CREATE TRIGGER doctor_schedules_updated_at AFTER UPDATE ON doctor_schedules
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+	BEGIN UPDATE doctor_schedules SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id; END;
+
+CREATE TABLE appointments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	patient_id INT NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+	doctor_id INT NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+	schedule_id INT NULL REFERENCES doctor_schedules(id) ON DELETE CASCADE,
+	appointment_date DATE NOT NULL,
+	start_time_slot TIME NOT NULL,
+	complaint TEXT,
+	status VARCHAR(16) DEFAULT 'Pending' CHECK (status IN ('Pending', 'Confirmed', 'Rejected', 'Completed', 'Cancelled', 'NoShow', 'CheckedIn')),
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE TRIGGER appointments_updated_at AFTER UPDATE ON appointments
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+	BEGIN UPDATE appointments SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id; END;
+
+CREATE TABLE medical_records (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	appointment_id INT NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+	diagnosis TEXT,
+	prescription TEXT,
+	doctor_notes TEXT,
+	examination_date TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE TRIGGER medical_records_updated_at AFTER UPDATE ON medical_records
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+	BEGIN UPDATE medical_records SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id; END;
+
+CREATE TABLE appointment_changes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	appointment_id INT NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+	old_appointment_date DATE NOT NULL,
+	old_start_time_slot TIME,
+	new_appointment_date DATE NOT NULL,
+	new_start_time_slot TIME,
+	actor_user_id INT REFERENCES users(id) ON DELETE SET NULL,
+	actor_role VARCHAR(16) NOT NULL CHECK (actor_role IN ('admin', 'doctor', 'patient')),
+	reason TEXT,
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE INDEX idx_appointment_changes_appointment ON appointment_changes (appointment_id);
+
+CREATE TABLE user_tokens (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	purpose VARCHAR(32) NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	used_at TIMESTAMP NULL DEFAULT NULL,
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	CONSTRAINT unique_token_hash UNIQUE (token_hash)
+);
+CREATE INDEX idx_user_tokens_user_purpose ON user_tokens (user_id, purpose);
+
+CREATE TABLE refresh_tokens (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	family_id CHAR(32) NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	access_jti CHAR(32) NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	rotated_at TIMESTAMP NULL DEFAULT NULL,
+	revoked_at TIMESTAMP NULL DEFAULT NULL,
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	CONSTRAINT unique_refresh_token_hash UNIQUE (token_hash),
+	CONSTRAINT unique_refresh_access_jti UNIQUE (access_jti)
+);
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens (family_id);
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens (user_id);
+
+CREATE TABLE login_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INT NULL REFERENCES users(id) ON DELETE SET NULL,
+	email VARCHAR(255) NOT NULL,
+	ip VARCHAR(45) NOT NULL,
+	user_agent VARCHAR(255) NOT NULL DEFAULT '',
+	success BOOLEAN NOT NULL,
+	reason VARCHAR(32) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE INDEX idx_login_events_email ON login_events (email, created_at);
+CREATE INDEX idx_login_events_ip ON login_events (ip, created_at);
+
+CREATE TABLE user_mfa (
+	user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	secret VARCHAR(64) NOT NULL,
+	enabled_at TIMESTAMP NULL DEFAULT NULL,
+	last_used_step BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE mfa_recovery_codes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	code_hash CHAR(64) NOT NULL,
+	used_at TIMESTAMP NULL DEFAULT NULL,
+	created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	CONSTRAINT unique_recovery_code UNIQUE (user_id, code_hash)
+);
+
+-- No foreign keys on purpose, the trail has to outlive deleted users and
+-- patients.
+CREATE TABLE audit_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	actor_user_id INT NULL,
+	actor_role VARCHAR(16) NOT NULL DEFAULT '',
+	action VARCHAR(32) NOT NULL,
+	outcome VARCHAR(16) NOT NULL,
+	resource_type VARCHAR(32) NOT NULL,
+	resource_id VARCHAR(64) NOT NULL DEFAULT '',
+	patient_id INT NULL,
+	ip VARCHAR(45) NOT NULL DEFAULT '',
+	request_id VARCHAR(128) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+CREATE INDEX idx_audit_events_created ON audit_events (created_at);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor_user_id, created_at);
+CREATE INDEX idx_audit_events_patient ON audit_events (patient_id, created_at);
+CREATE INDEX idx_audit_events_resource ON audit_events (resource_type, resource_id);
@@ -0,0 +1,3 @@
+-- Nothing to do. On MySQL this version catches up databases created by the
+-- boot-time migrations; SQLite databases never had those and start out
+-- with the full schema in 0001.
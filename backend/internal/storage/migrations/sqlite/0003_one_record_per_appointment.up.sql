@@ -0,0 +1,3 @@
+-- One medical record per appointment, see mysql/0003.
+
+CREATE UNIQUE INDEX unique_appointment_record ON medical_records (appointment_id);
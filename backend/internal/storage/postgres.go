@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// postgresDriverName is registered with database/sql for postgres:// URLs.
+const postgresDriverName = "pgx-medical-record"
+
+func init() {
+	sql.Register(postgresDriverName, postgresDriver{base: stdlib.GetDefaultDriver()})
+}
+
+// Tables without an id column, inserts into them are not given RETURNING id.
+var tablesWithoutID = map[string]bool{
+	"user_mfa":          true,
+	"schema_migrations": true,
+}
+
+var (
+	insertPattern       = regexp.MustCompile(`(?is)^\s*INSERT\s+(IGNORE\s+)?INTO\s+([a-z_]+)`)
+	insertIgnorePattern = regexp.MustCompile(`(?i)\bINSERT\s+IGNORE\s+INTO\b`)
+	likePattern         = regexp.MustCompile(`(?i)\bLIKE\b`)
+	returningPattern    = regexp.MustCompile(`(?i)\bRETURNING\b`)
+)
+
+// rebindPostgres turns a MySQL flavoured statement into PostgreSQL:
+//
+//   - ? placeholders become $1, $2, ...
+//   - INSERT IGNORE becomes INSERT ... ON CONFLICT DO NOTHING
+//   - LIKE becomes ILIKE, MySQL compares case-insensitively by default
+//
+// returnsID is true for inserts that need RETURNING id to emulate
+// LastInsertId. Text inside quotes is left alone, see mapUnquoted.
+func rebindPostgres(query string) (rewritten string, returnsID bool) {
+	n := 0
+	rewritten = mapUnquoted(query, func(code string) string {
+		code = likePattern.ReplaceAllString(code, "ILIKE")
+		code = strings.ReplaceAll(code, "`", `"`)
+
+		var b strings.Builder
+		for {
+			i := strings.IndexByte(code, '?')
+			if i < 0 {
+				break
+			}
+			n++
+			b.WriteString(code[:i])
+			b.WriteString("$" + strconv.Itoa(n))
+			code = code[i+1:]
+		}
+		b.WriteString(code)
+		return b.String()
+	})
+
+	m := insertPattern.FindStringSubmatch(rewritten)
+	if m == nil {
+		return rewritten, false
+	}
+	if m[1] != "" {
+		rewritten = insertIgnorePattern.ReplaceAllString(rewritten, "INSERT INTO")
+		rewritten = strings.TrimRight(strings.TrimSpace(rewritten), ";") + " ON CONFLICT DO NOTHING"
+	}
+	if tablesWithoutID[strings.ToLower(m[2])] || returningPattern.MatchString(rewritten) {
+		return rewritten, false
+	}
+	return strings.TrimRight(strings.TrimSpace(rewritten), ";") + " RETURNING id", true
+}
+
+// postgresDriver wraps the pgx driver and rewrites every statement with
+// rebindPostgres, so the repositories run unchanged on PostgreSQL.
+type postgresDriver struct {
+	base driver.Driver
+}
+
+func (d postgresDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.base.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresConn{Conn: conn}, nil
+}
+
+// postgresConn forwards to the pgx connection, which implements all of the
+// optional driver interfaces used below.
+type postgresConn struct {
+	driver.Conn
+}
+
+func (c *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *postgresConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query, _ = rebindPostgres(query)
+	return c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+}
+
+func (c *postgresConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c *postgresConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query, returnsID := rebindPostgres(query)
+	if !returnsID {
+		return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+	}
+
+	rows, err := c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Like MySQL, report the id of the first row of a multi-row insert
+	var (
+		res  insertResult
+		dest = make([]driver.Value, 1)
+	)
+	for {
+		err := rows.Next(dest)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if res.rows == 0 {
+			id, ok := dest[0].(int64)
+			if !ok {
+				return nil, errors.New("RETURNING id did not return an integer")
+			}
+			res.id = id
+		}
+		res.rows++
+	}
+	return res, nil
+}
+
+func (c *postgresConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	query, _ = rebindPostgres(query)
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func (c *postgresConn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
+
+func (c *postgresConn) CheckNamedValue(v *driver.NamedValue) error {
+	return c.Conn.(driver.NamedValueChecker).CheckNamedValue(v)
+}
+
+func (c *postgresConn) ResetSession(ctx context.Context) error {
+	return c.Conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+func (c *postgresConn) IsValid() bool {
+	v, ok := c.Conn.(driver.Validator)
+	return !ok || v.IsValid()
+}
+
+type insertResult struct {
+	id   int64
+	rows int64
+}
+
+func (r insertResult) LastInsertId() (int64, error) { return r.id, nil }
+func (r insertResult) RowsAffected() (int64, error) { return r.rows, nil }
+
+// mapUnquoted passes the parts of query outside string literals and quoted
+// identifiers through fn, so rewrites never touch a '?' or keyword that is
+// part of a value.
+func mapUnquoted(query string, fn func(code string) string) string {
+	var (
+		b      strings.Builder
+		quoted byte
+		start  int
+	)
+	b.Grow(len(query) + 16)
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quoted != 0:
+			if c == quoted {
+				quoted = 0
+				b.WriteString(query[start : i+1])
+				start = i + 1
+			}
+		case c == '\'' || c == '"':
+			b.WriteString(fn(query[start:i]))
+			start = i
+			quoted = c
+		}
+	}
+	if quoted != 0 {
+		b.WriteString(query[start:])
+	} else {
+		b.WriteString(fn(query[start:]))
+	}
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRebindPostgres(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		want      string
+		returnsID bool
+	}{
+		{
+			name:  "placeholders numbered in order",
+			query: "SELECT id FROM users WHERE email = ? AND role = ? LIMIT ?",
+			want:  "SELECT id FROM users WHERE email = $1 AND role = $2 LIMIT $3",
+		},
+		{
+			name:  "question mark in a string left alone",
+			query: "SELECT id FROM users WHERE name = 'who?' AND email = ?",
+			want:  "SELECT id FROM users WHERE name = 'who?' AND email = $1",
+		},
+		{
+			name:  "question mark in a quoted identifier left alone",
+			query: `SELECT "a?b" FROM users WHERE id = ?`,
+			want:  `SELECT "a?b" FROM users WHERE id = $1`,
+		},
+		{
+			name:  "backticks become double quotes",
+			query: "SELECT `status` FROM appointments WHERE id = ?",
+			want:  `SELECT "status" FROM appointments WHERE id = $1`,
+		},
+		{
+			name:  "LIKE ignores case",
+			query: "SELECT id FROM users WHERE name LIKE ? OR email like ?",
+			want:  "SELECT id FROM users WHERE name ILIKE $1 OR email ILIKE $2",
+		},
+		{
+			name:  "LIKE inside a string left alone",
+			query: "SELECT id FROM users WHERE name = 'LIKE' AND email LIKE ?",
+			want:  "SELECT id FROM users WHERE name = 'LIKE' AND email ILIKE $1",
+		},
+		{
+			name:  "column containing like left alone",
+			query: "SELECT likes FROM posts WHERE id = ?",
+			want:  "SELECT likes FROM posts WHERE id = $1",
+		},
+		{
+			name:      "insert returns the id",
+			query:     "INSERT INTO users (name, email) VALUES (?, ?)",
+			want:      "INSERT INTO users (name, email) VALUES ($1, $2) RETURNING id",
+			returnsID: true,
+		},
+		{
+			name:      "trailing semicolon dropped before RETURNING",
+			query:     "INSERT INTO users (name) VALUES (?);",
+			want:      "INSERT INTO users (name) VALUES ($1) RETURNING id",
+			returnsID: true,
+		},
+		{
+			// An ignored row returns nothing, LastInsertId is then 0
+			name:      "INSERT IGNORE",
+			query:     "INSERT IGNORE INTO mfa_recovery_codes (user_id, code_hash) VALUES (?, ?)",
+			want:      "INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2) ON CONFLICT DO NOTHING RETURNING id",
+			returnsID: true,
+		},
+		{
+			name:  "user_mfa has no id",
+			query: "INSERT INTO user_mfa (user_id, secret) VALUES (?, ?)",
+			want:  "INSERT INTO user_mfa (user_id, secret) VALUES ($1, $2)",
+		},
+		{
+			name:  "schema_migrations has no id",
+			query: "INSERT INTO schema_migrations (version, name) VALUES (?, ?)",
+			want:  "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
+		},
+		{
+			name:  "INSERT IGNORE without an id",
+			query: "INSERT IGNORE INTO user_mfa (user_id, secret) VALUES (?, ?)",
+			want:  "INSERT INTO user_mfa (user_id, secret) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		},
+		{
+			name:      "INSERT ... SELECT",
+			query:     "INSERT INTO doctor_schedules (doctor_id, day_of_week) SELECT ?, day_of_week FROM doctor_schedules WHERE doctor_id = ?",
+			want:      "INSERT INTO doctor_schedules (doctor_id, day_of_week) SELECT $1, day_of_week FROM doctor_schedules WHERE doctor_id = $2 RETURNING id",
+			returnsID: true,
+		},
+		{
+			name:  "existing RETURNING kept",
+			query: "INSERT INTO users (name) VALUES (?) RETURNING id, created_at",
+			want:  "INSERT INTO users (name) VALUES ($1) RETURNING id, created_at",
+		},
+		{
+			name:  "leading whitespace and lower case",
+			query: "\n\tinsert ignore into user_mfa (user_id) values (?)",
+			want:  "INSERT INTO user_mfa (user_id) values ($1) ON CONFLICT DO NOTHING",
+		},
+		{
+			name:  "update untouched apart from placeholders",
+			query: "UPDATE users SET name = ? WHERE id = ?",
+			want:  "UPDATE users SET name = $1 WHERE id = $2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, returnsID := rebindPostgres(tt.query)
+			if got != tt.want || returnsID != tt.returnsID {
+				t.Errorf("rebindPostgres(%q)\n got %q, %v\nwant %q, %v", tt.query, got, returnsID, tt.want, tt.returnsID)
+			}
+		})
+	}
+}
+
+func TestMapUnquoted(t *testing.T) {
+	upper := func(code string) string { return strings.ToUpper(code) }
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"no quotes", "select ?", "SELECT ?"},
+		{"single quotes", "a 'b' c", "A 'b' C"},
+		{"double quotes", `a "b" c`, `A "b" C`},
+		{"other quote inside", `a 'b"c' d "e'f" g`, `A 'b"c' D "e'f" G`},
+		{"doubled quote escape", "a 'it''s' b", "A 'it''s' B"},
+		{"adjacent literals", "'a''b'", "'a''b'"},
+		{"unterminated literal kept", "a 'b c", "A 'b c"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mapUnquoted(tt.query, upper); got != tt.want {
+				t.Errorf("mapUnquoted(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Generated accounts are recognisable by their email prefix and share one
+// password, hashing thousands of them would take minutes.
+const (
+	loadTestEmailPrefix = "loadtest."
+	loadTestPassword    = "loadtest123"
+	loadTestBatchSize   = 500
+)
+
+var (
+	loadTestWorkDays   = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+	loadTestBloodTypes = []string{"A+", "A-", "B+", "B-", "AB+", "AB-", "O+", "O-"}
+)
+
+type loadTestSchedule struct {
+	id        int64
+	doctorID  int64
+	workDay   time.Weekday
+	startHour int
+	hours     int
+	quota     int
+}
+
+// SeedLoadTest generates opts.Doctors doctors with two to five weekly
+// schedules each, opts.Patients patients and opts.Appointments appointments
+// spread over the last 90 and the next 60 days, all in one transaction. It
+// refuses to run twice on the same database.
+func SeedLoadTest(ctx context.Context, db *sql.DB, opts SeedOptions) (err error) {
+	if opts.Doctors <= 0 || opts.Patients <= 0 || opts.Appointments < 0 {
+		return fmt.Errorf("jumlah dokter dan pasien harus positif")
+	}
+
+	var existing int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE email LIKE ?", loadTestEmailPrefix+"%").Scan(&existing); err != nil {
+		return err
+	}
+	if existing > 0 {
+		return fmt.Errorf("dataset load-test sudah ada (%d akun %s*)", existing, loadTestEmailPrefix)
+	}
+
+	specializations, err := queryIDs(ctx, db, "SELECT id FROM specializations ORDER BY id")
+	if err != nil {
+		return err
+	}
+	if len(specializations) == 0 {
+		return fmt.Errorf("tabel specializations kosong")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(loadTestPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+
+	// Users, looked up again by email because multi-row inserts do not
+	// report every id
+	users := make([][]any, 0, opts.Doctors+opts.Patients)
+	for i := 1; i <= opts.Doctors; i++ {
+		users = append(users, []any{fmt.Sprintf("Dr. Load Test %05d", i), loadTestEmail("doctor", i), string(hash), "doctor"})
+	}
+	for i := 1; i <= opts.Patients; i++ {
+		users = append(users, []any{fmt.Sprintf("Load Test Patient %05d", i), loadTestEmail("patient", i), string(hash), "patient"})
+	}
+	if err = insertBatch(ctx, tx, "INSERT INTO users (name, email, password, role)", users); err != nil {
+		return err
+	}
+
+	doctorUsers, err := queryIDs(ctx, tx, "SELECT id FROM users WHERE email LIKE ? ORDER BY email", loadTestEmailPrefix+"doctor.%")
+	if err != nil {
+		return err
+	}
+	patientUsers, err := queryIDs(ctx, tx, "SELECT id FROM users WHERE email LIKE ? ORDER BY email", loadTestEmailPrefix+"patient.%")
+	if err != nil {
+		return err
+	}
+
+	// Doctors
+	doctorRows := make([][]any, 0, len(doctorUsers))
+	for i, userID := range doctorUsers {
+		doctorRows = append(doctorRows, []any{
+			userID,
+			specializations[rng.IntN(len(specializations))],
+			[]string{"male", "female"}[rng.IntN(2)],
+			fmt.Sprintf("%d Load Test St", rng.IntN(999)+1),
+			fmt.Sprintf("LT-%05d", i+1),
+		})
+	}
+	if err = insertBatch(ctx, tx, "INSERT INTO doctors (user_id, specialization_id, gender, address, license_number)", doctorRows); err != nil {
+		return err
+	}
+	doctors, err := queryIDs(ctx, tx, "SELECT id FROM doctors WHERE license_number LIKE 'LT-%' ORDER BY license_number")
+	if err != nil {
+		return err
+	}
+
+	// Schedules, on distinct week days per doctor
+	var schedules []loadTestSchedule
+	for _, doctorID := range doctors {
+		for _, day := range rng.Perm(len(loadTestWorkDays))[:2+rng.IntN(4)] {
+			schedules = append(schedules, loadTestSchedule{
+				doctorID:  doctorID,
+				workDay:   time.Weekday((day + 1) % 7), // loadTestWorkDays starts on monday
+				startHour: 7 + rng.IntN(8),
+				hours:     3 + rng.IntN(2),
+				quota:     8 + rng.IntN(9),
+			})
+		}
+	}
+	scheduleRows := make([][]any, 0, len(schedules))
+	for _, s := range schedules {
+		scheduleRows = append(scheduleRows, []any{
+			s.doctorID,
+			loadTestWorkDays[(int(s.workDay)+6)%7],
+			fmt.Sprintf("%02d:00:00", s.startHour),
+			fmt.Sprintf("%02d:00:00", s.startHour+s.hours),
+			s.quota,
+		})
+	}
+	if err = insertBatch(ctx, tx, "INSERT INTO doctor_schedules (doctor_id, work_day, start_time, end_time, patient_quota)", scheduleRows); err != nil {
+		return err
+	}
+	// Every doctor has at most one schedule per day, so doctor and day find the id
+	scheduleIDs, err := loadTestScheduleIDs(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for i := range schedules {
+		schedules[i].id = scheduleIDs[fmt.Sprintf("%d/%s", schedules[i].doctorID, loadTestWorkDays[(int(schedules[i].workDay)+6)%7])]
+	}
+
+	// Patients
+	patientRows := make([][]any, 0, len(patientUsers))
+	for i, userID := range patientUsers {
+		born := time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, rng.IntN(65*365))
+		patientRows = append(patientRows, []any{
+			userID,
+			born.Format("2006-01-02"),
+			fmt.Sprintf("0812%08d", i+1),
+			fmt.Sprintf("%d Load Test Ave", rng.IntN(999)+1),
+			loadTestBloodTypes[rng.IntN(len(loadTestBloodTypes))],
+		})
+	}
+	if err = insertBatch(ctx, tx, "INSERT INTO patients (user_id, date_of_birth, phone, address, blood_type)", patientRows); err != nil {
+		return err
+	}
+	patients, err := queryIDs(ctx, tx, `
+		SELECT p.id FROM patients p JOIN users u ON u.id = p.user_id
+		WHERE u.email LIKE ? ORDER BY u.email`, loadTestEmailPrefix+"patient.%")
+	if err != nil {
+		return err
+	}
+
+	// Appointments, never more per schedule and day than its quota
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	booked := map[string]int{}
+	appointmentRows := make([][]any, 0, opts.Appointments)
+	for attempts := 0; len(appointmentRows) < opts.Appointments && attempts < opts.Appointments*10; attempts++ {
+		s := schedules[rng.IntN(len(schedules))]
+		day := today.AddDate(0, 0, rng.IntN(150)-90)
+		day = day.AddDate(0, 0, (int(s.workDay)-int(day.Weekday())+7)%7)
+
+		key := fmt.Sprintf("%d/%s", s.id, day.Format("2006-01-02"))
+		if booked[key] >= s.quota {
+			continue
+		}
+		slot := booked[key] % (s.hours * 2)
+		booked[key]++
+
+		appointmentRows = append(appointmentRows, []any{
+			patients[rng.IntN(len(patients))],
+			s.doctorID,
+			s.id,
+			day.Format("2006-01-02"),
+			fmt.Sprintf("%02d:%02d:00", s.startHour+slot/2, slot%2*30),
+			"Load test complaint",
+			loadTestStatus(rng, day.Before(today)),
+		})
+	}
+	if err = insertBatch(ctx, tx, "INSERT INTO appointments (patient_id, doctor_id, schedule_id, appointment_date, start_time_slot, complaint, status)", appointmentRows); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	log.Printf("Load test data seeded: %d doctors, %d schedules, %d patients, %d appointments (seed %d, password %s)",
+		len(doctors), len(schedules), len(patients), len(appointmentRows), opts.Seed, loadTestPassword)
+	return nil
+}
+
+func loadTestEmail(kind string, n int) string {
+	return fmt.Sprintf("%s%s.%05d@example.test", loadTestEmailPrefix, kind, n)
+}
+
+// loadTestStatus picks a status that fits the appointment date.
+func loadTestStatus(rng *rand.Rand, past bool) string {
+	if past {
+		return []string{"Completed", "Completed", "Completed", "NoShow", "Cancelled"}[rng.IntN(5)]
+	}
+	return []string{"Pending", "Confirmed", "Confirmed", "Cancelled"}[rng.IntN(4)]
+}
+
+func loadTestScheduleIDs(ctx context.Context, tx *sql.Tx) (map[string]int64, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT s.id, s.doctor_id, s.work_day FROM doctor_schedules s
+		JOIN doctors d ON d.id = s.doctor_id
+		WHERE d.license_number LIKE 'LT-%'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := map[string]int64{}
+	for rows.Next() {
+		var (
+			id, doctorID int64
+			workDay      string
+		)
+		if err := rows.Scan(&id, &doctorID, &workDay); err != nil {
+			return nil, err
+		}
+		ids[fmt.Sprintf("%d/%s", doctorID, workDay)] = id
+	}
+	return ids, rows.Err()
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func queryIDs(ctx context.Context, q queryer, query string, args ...any) ([]int64, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// insertBatch inserts rows with multi-row INSERT statements of at most
+// loadTestBatchSize rows. insert is the statement up to VALUES.
+func insertBatch(ctx context.Context, tx *sql.Tx, insert string, rows [][]any) error {
+	for start := 0; start < len(rows); start += loadTestBatchSize {
+		batch := rows[start:min(start+loadTestBatchSize, len(rows))]
+
+		placeholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(batch[0])), ", ") + ")"
+		values := make([]string, len(batch))
+		args := make([]any, 0, len(batch)*len(batch[0]))
+		for i, row := range batch {
+			values[i] = placeholder
+			args = append(args, row...)
+		}
+
+		if _, err := tx.ExecContext(ctx, insert+" VALUES "+strings.Join(values, ", "), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,32 +3,78 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"os"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
-func SeedAllData(db *sql.DB) error {
-	if err := SeedDefaultUsers(db); err != nil {
-		log.Println("WARNING: Failed to seed default users:", err)
-	}
+// Seed datasets, each one includes the ones before it:
+//
+//	minimal    reference data every installation needs (specializations)
+//	demo       a few doctors, patients and schedules with well-known passwords
+//	load-test  thousands of generated doctors, schedules, patients and appointments
+const (
+	DatasetMinimal  = "minimal"
+	DatasetDemo     = "demo"
+	DatasetLoadTest = "load-test"
+)
 
-	if err := SeedDefaultSpecializations(db); err != nil {
-		log.Println("WARNING: Failed to seed default specializations:", err)
-	}
+var SeedDatasets = []string{DatasetMinimal, DatasetDemo, DatasetLoadTest}
 
-	if err := SeedDefaultDoctors(db); err != nil {
-		log.Println("WARNING: Failed to seed default doctors:", err)
+// SeedOptions sizes the load-test dataset. The same Seed always generates
+// the same rows; only the appointment dates move along with the current day.
+type SeedOptions struct {
+	Seed         uint64
+	Doctors      int
+	Patients     int
+	Appointments int
+}
+
+func DefaultSeedOptions() SeedOptions {
+	return SeedOptions{
+		Seed:         1,
+		Doctors:      2000,
+		Patients:     5000,
+		Appointments: 20000,
 	}
+}
 
-	if err := SeedDefaultPatients(db); err != nil {
-		log.Println("WARNING: Failed to seed default patients:", err)
+// Seed inserts the named dataset. Everything but minimal creates accounts
+// with known passwords and is refused when APP_ENV=production.
+func Seed(ctx context.Context, db *sql.DB, dataset string, opts SeedOptions) error {
+	if dataset != DatasetMinimal && os.Getenv("APP_ENV") == "production" {
+		return fmt.Errorf("dataset %s berisi akun dengan password yang diketahui dan tidak boleh dipakai di production", dataset)
 	}
 
-	if err := SeedDefaultDoctorSchedules(db); err != nil {
-		log.Println("WARNING: Failed to seed default doctor schedules:", err)
+	switch dataset {
+	case DatasetMinimal:
+		return SeedDefaultSpecializations(db)
+	case DatasetDemo:
+		return seedAll(db,
+			SeedDefaultSpecializations,
+			SeedDefaultUsers,
+			SeedDefaultDoctors,
+			SeedDefaultPatients,
+			SeedDefaultDoctorSchedules,
+		)
+	case DatasetLoadTest:
+		if err := SeedDefaultSpecializations(db); err != nil {
+			return err
+		}
+		return SeedLoadTest(ctx, db, opts)
+	default:
+		return fmt.Errorf("dataset tidak dikenal %q (tersedia: %s, %s, %s)", dataset, DatasetMinimal, DatasetDemo, DatasetLoadTest)
 	}
+}
 
+func seedAll(db *sql.DB, steps ...func(*sql.DB) error) error {
+	for _, step := range steps {
+		if err := step(db); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -60,7 +106,7 @@ func SeedDefaultUsers(db *sql.DB) error {
 			"INSERT IGNORE INTO users (name, email, password, role) VALUES (?, ?, ?, ?)",
 			user.name, user.email, user.password, user.role)
 		if err != nil {
-			log.Printf("WARNING: Failed to insert user %s: %v", user.email, err)
+			return fmt.Errorf("insert user %s: %w", user.email, err)
 		}
 	}
 
@@ -86,7 +132,7 @@ func SeedDefaultSpecializations(db *sql.DB) error {
 	for _, spec := range specializations {
 		_, err := db.ExecContext(ctx, "INSERT IGNORE INTO specializations (name) VALUES (?)", spec)
 		if err != nil {
-			log.Printf("WARNING: Failed to insert specialization %s: %v", spec, err)
+			return fmt.Errorf("insert specialization %s: %w", spec, err)
 		}
 	}
 
@@ -94,27 +140,32 @@ func SeedDefaultSpecializations(db *sql.DB) error {
 	return nil
 }
 
+// SeedDefaultDoctors looks the users and specializations up by email and
+// name, so it does not depend on the ids the database handed out.
 func SeedDefaultDoctors(db *sql.DB) error {
 	ctx := context.Background()
 
 	doctors := []struct {
-		userID           int
-		specializationID int
-		gender           string
-		address          string
-		licenseNumber    string
+		email          string
+		specialization string
+		gender         string
+		address        string
+		licenseNumber  string
 	}{
-		{2, 1, "male", "123 Main St, City, State", "DOC001"},
-		{3, 2, "female", "456 Oak Ave, City, State", "DOC002"},
-		{4, 3, "male", "789 Pine Rd, City, State", "DOC003"},
+		{"john.smith@hospital.com", "General Practitioner", "male", "123 Main St, City, State", "DOC001"},
+		{"sarah.johnson@hospital.com", "Pediatrician", "female", "456 Oak Ave, City, State", "DOC002"},
+		{"michael.brown@hospital.com", "Cardiologist", "male", "789 Pine Rd, City, State", "DOC003"},
 	}
 
 	for _, doctor := range doctors {
-		_, err := db.ExecContext(ctx,
-			"INSERT IGNORE INTO doctors (user_id, specialization_id, gender, address, license_number) VALUES (?, ?, ?, ?, ?)",
-			doctor.userID, doctor.specializationID, doctor.gender, doctor.address, doctor.licenseNumber)
+		_, err := db.ExecContext(ctx, `
+			INSERT IGNORE INTO doctors (user_id, specialization_id, gender, address, license_number)
+			SELECT u.id, s.id, ?, ?, ?
+			FROM users u, specializations s
+			WHERE u.email = ? AND s.name = ?`,
+			doctor.gender, doctor.address, doctor.licenseNumber, doctor.email, doctor.specialization)
 		if err != nil {
-			log.Printf("WARNING: Failed to insert doctor %d: %v", doctor.userID, err)
+			return fmt.Errorf("insert doctor %s: %w", doctor.email, err)
 		}
 	}
 
@@ -126,23 +177,24 @@ func SeedDefaultPatients(db *sql.DB) error {
 	ctx := context.Background()
 
 	patients := []struct {
-		userID      int
+		email       string
 		dateOfBirth string
 		phone       string
 		address     string
 		bloodType   string
 	}{
-		{5, "1990-01-15", "555-0101", "111 Patient St, City, State", "A+"},
-		{6, "1985-05-22", "555-0102", "222 Patient Ave, City, State", "B+"},
-		{7, "1992-11-08", "555-0103", "333 Patient Rd, City, State", "O-"},
+		{"patient1@email.com", "1990-01-15", "0812-0000-0101", "111 Patient St, City, State", "A+"},
+		{"patient2@email.com", "1985-05-22", "0812-0000-0102", "222 Patient Ave, City, State", "B+"},
+		{"patient3@email.com", "1992-11-08", "0812-0000-0103", "333 Patient Rd, City, State", "O-"},
 	}
 
 	for _, patient := range patients {
-		_, err := db.ExecContext(ctx,
-			"INSERT IGNORE INTO patients (user_id, date_of_birth, phone, address, blood_type) VALUES (?, ?, ?, ?, ?)",
-			patient.userID, patient.dateOfBirth, patient.phone, patient.address, patient.bloodType)
+		_, err := db.ExecContext(ctx, `
+			INSERT IGNORE INTO patients (user_id, date_of_birth, phone, address, blood_type)
+			SELECT id, ?, ?, ?, ? FROM users WHERE email = ?`,
+			patient.dateOfBirth, patient.phone, patient.address, patient.bloodType, patient.email)
 		if err != nil {
-			log.Printf("WARNING: Failed to insert patient %d: %v", patient.userID, err)
+			return fmt.Errorf("insert patient %s: %w", patient.email, err)
 		}
 	}
 
@@ -154,41 +206,42 @@ func SeedDefaultDoctorSchedules(db *sql.DB) error {
 	ctx := context.Background()
 
 	schedules := []struct {
-		doctorID  int
-		workDay   string
-		startTime string
-		endTime   string
-		quota     int
+		licenseNumber string
+		workDay       string
+		startTime     string
+		endTime       string
+		quota         int
 	}{
-		// Dr. John Smith (ID: 1) - General Practitioner
-		{1, "monday", "09:00", "12:00", 10},
-		{1, "monday", "14:00", "17:00", 10},
-		{1, "wednesday", "09:00", "12:00", 10},
-		{1, "wednesday", "14:00", "17:00", 10},
-		{1, "friday", "09:00", "12:00", 10},
-		{1, "friday", "14:00", "17:00", 10},
-
-		// Dr. Sarah Johnson (ID: 2) - Pediatrician
-		{2, "tuesday", "08:00", "12:00", 8},
-		{2, "tuesday", "13:00", "16:00", 8},
-		{2, "thursday", "08:00", "12:00", 8},
-		{2, "thursday", "13:00", "16:00", 8},
-		{2, "saturday", "08:00", "12:00", 8},
-
-		// Dr. Michael Brown (ID: 3) - Cardiologist
-		{3, "monday", "10:00", "13:00", 12},
-		{3, "tuesday", "10:00", "13:00", 12},
-		{3, "wednesday", "10:00", "13:00", 12},
-		{3, "thursday", "10:00", "13:00", 12},
-		{3, "friday", "10:00", "13:00", 12},
+		// Dr. John Smith - General Practitioner
+		{"DOC001", "monday", "09:00", "12:00", 10},
+		{"DOC001", "monday", "14:00", "17:00", 10},
+		{"DOC001", "wednesday", "09:00", "12:00", 10},
+		{"DOC001", "wednesday", "14:00", "17:00", 10},
+		{"DOC001", "friday", "09:00", "12:00", 10},
+		{"DOC001", "friday", "14:00", "17:00", 10},
+
+		// Dr. Sarah Johnson - Pediatrician
+		{"DOC002", "tuesday", "08:00", "12:00", 8},
+		{"DOC002", "tuesday", "13:00", "16:00", 8},
+		{"DOC002", "thursday", "08:00", "12:00", 8},
+		{"DOC002", "thursday", "13:00", "16:00", 8},
+		{"DOC002", "saturday", "08:00", "12:00", 8},
+
+		// Dr. Michael Brown - Cardiologist
+		{"DOC003", "monday", "10:00", "13:00", 12},
+		{"DOC003", "tuesday", "10:00", "13:00", 12},
+		{"DOC003", "wednesday", "10:00", "13:00", 12},
+		{"DOC003", "thursday", "10:00", "13:00", 12},
+		{"DOC003", "friday", "10:00", "13:00", 12},
 	}
 
 	for _, schedule := range schedules {
-		_, err := db.ExecContext(ctx,
-			"INSERT IGNORE INTO doctor_schedules (doctor_id, work_day, start_time, end_time, patient_quota) VALUES (?, ?, ?, ?, ?)",
-			schedule.doctorID, schedule.workDay, schedule.startTime, schedule.endTime, schedule.quota)
+		_, err := db.ExecContext(ctx, `
+			INSERT IGNORE INTO doctor_schedules (doctor_id, work_day, start_time, end_time, patient_quota)
+			SELECT id, ?, ?, ?, ? FROM doctors WHERE license_number = ?`,
+			schedule.workDay, schedule.startTime, schedule.endTime, schedule.quota, schedule.licenseNumber)
 		if err != nil {
-			log.Printf("WARNING: Failed to insert schedule for doctor %d: %v", schedule.doctorID, err)
+			return fmt.Errorf("insert schedule for doctor %s: %w", schedule.licenseNumber, err)
 		}
 	}
 
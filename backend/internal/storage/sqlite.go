@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// sqliteDriverName is registered with database/sql for sqlite:// URLs.
+const sqliteDriverName = "sqlite-medical-record"
+
+func init() {
+	sql.Register(sqliteDriverName, sqliteDriver{base: &sqlite.Driver{}})
+}
+
+// SQLite has no date or time types. Timestamps are stored as UTC text in
+// sqliteTimeFormat, which sorts and compares like the instants it holds, and
+// dates as YYYY-MM-DD like MySQL returns them.
+const (
+	sqliteTimeFormat = "2006-01-02 15:04:05.000"
+	sqliteDateFormat = "2006-01-02"
+	sqliteNow        = "strftime('%Y-%m-%d %H:%M:%f', 'now')"
+)
+
+var (
+	nowPattern       = regexp.MustCompile(`(?i)\bNOW\(\)`)
+	forUpdatePattern = regexp.MustCompile(`(?i)\s+FOR\s+UPDATE\b`)
+)
+
+// memoryDatabases numbers the in-memory databases opened by this process.
+var memoryDatabases atomic.Int64
+
+// sqliteDSN turns what follows sqlite:// into a modernc.org/sqlite DSN. A
+// path opens that file, :memory: a fresh in-memory database. Query
+// parameters are passed on, so _pragma=... can be added to the defaults.
+func sqliteDSN(rest string) (string, error) {
+	name, rawQuery, _ := strings.Cut(rest, "?")
+	if name == "" {
+		return "", fmt.Errorf("DB_URL: sqlite:// butuh path file atau :memory:")
+	}
+	params, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("DB_URL tidak valid: %w", err)
+	}
+
+	// BEGIN IMMEDIATE takes the write lock up front, which stands in for the
+	// SELECT ... FOR UPDATE the repositories rely on
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(10000)")
+	params.Set("_txlock", "immediate")
+
+	if name == ":memory:" {
+		// The memdb VFS lets every pooled connection see the same database,
+		// a plain :memory: would give each connection its own
+		name = fmt.Sprintf("/medical-record-%d", memoryDatabases.Add(1))
+		params.Set("vfs", "memdb")
+	} else {
+		params.Add("_pragma", "journal_mode(WAL)")
+	}
+	return "file:" + name + "?" + params.Encode(), nil
+}
+
+// rebindSQLite turns a MySQL flavoured statement into SQLite:
+//
+//   - INSERT IGNORE becomes INSERT OR IGNORE
+//   - NOW() becomes the current UTC time in sqliteTimeFormat
+//   - FOR UPDATE is dropped, transactions already hold the write lock
+//
+// LIKE needs nothing, SQLite already ignores case for ASCII.
+func rebindSQLite(query string) string {
+	return mapUnquoted(query, func(code string) string {
+		code = insertIgnorePattern.ReplaceAllString(code, "INSERT OR IGNORE INTO")
+		code = nowPattern.ReplaceAllString(code, sqliteNow)
+		return forUpdatePattern.ReplaceAllString(code, "")
+	})
+}
+
+// sqliteTime formats t the way the schema stores it. Midnight UTC is taken
+// for a date, that is what parsing YYYY-MM-DD gives and how SQLite hands DATE
+// columns back.
+func sqliteTime(t time.Time) string {
+	t = t.UTC()
+	if t.Equal(t.Truncate(24 * time.Hour)) {
+		return t.Format(sqliteDateFormat)
+	}
+	return t.Format(sqliteTimeFormat)
+}
+
+// sqliteDriver wraps the modernc.org/sqlite driver and rewrites every
+// statement with rebindSQLite, so the repositories run unchanged on SQLite.
+type sqliteDriver struct {
+	base driver.Driver
+}
+
+func (d sqliteDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.base.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{Conn: conn}, nil
+}
+
+// sqliteConn forwards to the modernc connection, which implements all of the
+// optional driver interfaces used below.
+type sqliteConn struct {
+	driver.Conn
+}
+
+func (c *sqliteConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqliteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, rebindSQLite(query))
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteStmt{Stmt: stmt}, nil
+}
+
+func (c *sqliteConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, rebindSQLite(query), args)
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.Conn.(driver.QueryerContext).QueryContext(ctx, rebindSQLite(query), args)
+	if err != nil {
+		return nil, err
+	}
+	return newSQLiteRows(rows), nil
+}
+
+func (c *sqliteConn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
+
+// CheckNamedValue stores times in the format the schema uses instead of the
+// driver's default, which keeps the zone offset and would not compare
+// against NOW() or DATE columns.
+func (c *sqliteConn) CheckNamedValue(v *driver.NamedValue) error {
+	value, err := driver.DefaultParameterConverter.ConvertValue(v.Value)
+	if err != nil {
+		return err
+	}
+	if t, ok := value.(time.Time); ok {
+		value = sqliteTime(t)
+	}
+	v.Value = value
+	return nil
+}
+
+func (c *sqliteConn) ResetSession(ctx context.Context) error {
+	return c.Conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+func (c *sqliteConn) IsValid() bool {
+	v, ok := c.Conn.(driver.Validator)
+	return !ok || v.IsValid()
+}
+
+type sqliteStmt struct {
+	driver.Stmt
+}
+
+func (s *sqliteStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.Stmt.(driver.StmtExecContext).ExecContext(ctx, args)
+}
+
+func (s *sqliteStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := s.Stmt.(driver.StmtQueryContext).QueryContext(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return newSQLiteRows(rows), nil
+}
+
+// sqliteRows turns timestamps back into time.Time for columns SQLite knows no
+// type of, such as MAX(created_at). The driver already does so for columns
+// declared DATE, DATETIME or TIMESTAMP.
+type sqliteRows struct {
+	driver.Rows
+	untyped []bool
+}
+
+func newSQLiteRows(rows driver.Rows) *sqliteRows {
+	r := &sqliteRows{Rows: rows}
+	if types, ok := rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		r.untyped = make([]bool, len(rows.Columns()))
+		for i := range r.untyped {
+			r.untyped[i] = types.ColumnTypeDatabaseTypeName(i) == ""
+		}
+	}
+	return r
+}
+
+func (r *sqliteRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	for i, untyped := range r.untyped {
+		s, ok := dest[i].(string)
+		if !untyped || !ok || len(s) != len(sqliteTimeFormat) {
+			continue
+		}
+		if t, err := time.Parse(sqliteTimeFormat, s); err == nil {
+			dest[i] = t
+		}
+	}
+	return nil
+}
+
+func (r *sqliteRows) ColumnTypeDatabaseTypeName(index int) string {
+	if types, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return types.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRebindSQLite(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "placeholders kept",
+			query: "SELECT id FROM users WHERE email = ? AND role = ?",
+			want:  "SELECT id FROM users WHERE email = ? AND role = ?",
+		},
+		{
+			name:  "INSERT IGNORE",
+			query: "INSERT IGNORE INTO mfa_recovery_codes (user_id, code_hash) VALUES (?, ?)",
+			want:  "INSERT OR IGNORE INTO mfa_recovery_codes (user_id, code_hash) VALUES (?, ?)",
+		},
+		{
+			name:  "NOW()",
+			query: "UPDATE user_tokens SET used_at = NOW() WHERE id = ? AND expires_at > now()",
+			want:  "UPDATE user_tokens SET used_at = " + sqliteNow + " WHERE id = ? AND expires_at > " + sqliteNow,
+		},
+		{
+			name:  "FOR UPDATE dropped",
+			query: "SELECT quota FROM doctor_schedules WHERE id = ?\n    FOR UPDATE",
+			want:  "SELECT quota FROM doctor_schedules WHERE id = ?",
+		},
+		{
+			name:  "LIKE kept",
+			query: "SELECT id FROM users WHERE name LIKE ?",
+			want:  "SELECT id FROM users WHERE name LIKE ?",
+		},
+		{
+			name:  "keywords inside strings left alone",
+			query: "SELECT 'NOW() FOR UPDATE' FROM users WHERE note = 'INSERT IGNORE INTO'",
+			want:  "SELECT 'NOW() FOR UPDATE' FROM users WHERE note = 'INSERT IGNORE INTO'",
+		},
+		{
+			name:  "INSERT ... SELECT",
+			query: "INSERT IGNORE INTO doctor_schedules (doctor_id) SELECT ? FROM doctors WHERE created_at < NOW()",
+			want:  "INSERT OR IGNORE INTO doctor_schedules (doctor_id) SELECT ? FROM doctors WHERE created_at < " + sqliteNow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rebindSQLite(tt.query); got != tt.want {
+				t.Errorf("rebindSQLite(%q)\n got %q\nwant %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteTime(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{"midnight UTC is a date", time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), "2026-03-09"},
+		{"time of day", time.Date(2026, 3, 9, 8, 30, 0, 0, time.UTC), "2026-03-09 08:30:00.000"},
+		{"milliseconds kept", time.Date(2026, 3, 9, 8, 30, 5, 123456789, time.UTC), "2026-03-09 08:30:05.123"},
+		{"converted to UTC", time.Date(2026, 3, 9, 9, 0, 0, 0, jakarta), "2026-03-09 02:00:00.000"},
+		{"local midnight is not a date", time.Date(2026, 3, 9, 0, 0, 0, 0, jakarta), "2026-03-08 17:00:00.000"},
+		{"midnight in UTC from another zone", time.Date(2026, 3, 9, 7, 0, 0, 0, jakarta), "2026-03-09"},
+		{"a nanosecond past midnight", time.Date(2026, 3, 9, 0, 0, 0, 1, time.UTC), "2026-03-09 00:00:00.000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sqliteTime(tt.at); got != tt.want {
+				t.Errorf("sqliteTime(%v) = %q, want %q", tt.at, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,19 +1,17 @@
 package main
 
 import (
+	"context"
 	"log"
-	"net/http"
 	"os"
+	"strings"
 
-	authMiddleware "github.com/JinXVIII/BE-Medical-Record/internal/middleware"
-
-	"github.com/JinXVIII/BE-Medical-Record/internal/handler"
-	"github.com/JinXVIII/BE-Medical-Record/internal/repository"
+	"github.com/JinXVIII/BE-Medical-Record/internal/auth"
+	"github.com/JinXVIII/BE-Medical-Record/internal/domain"
+	"github.com/JinXVIII/BE-Medical-Record/internal/mailer"
+	"github.com/JinXVIII/BE-Medical-Record/internal/server"
 	"github.com/JinXVIII/BE-Medical-Record/internal/service"
 	"github.com/JinXVIII/BE-Medical-Record/internal/storage"
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
 	"github.com/joho/godotenv"
 )
 
@@ -35,113 +33,115 @@ func main() {
 	}
 	log.Println("Berhasil terhubung ke database")
 
-	// Inisialisasi database (buat tabel jika belum ada)
-	if err := storage.InitializeDatabase(db); err != nil {
+	// The migrate subcommand runs before anything expects the schema to exist
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Inisialisasi database: cek migrasi (AUTO_MIGRATE=true menerapkannya) lalu seed data
+	if err := storage.InitializeDatabase(db, os.Getenv("AUTO_MIGRATE") == "true"); err != nil {
 		log.Fatal("Gagal menginisialisasi database: ", err)
 	}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Fatal("JWT_SECRET tidak bisa kosong")
+	// Development only: seed a dataset on boot, e.g. SEED_ON_BOOT=demo with
+	// APP_ENV=development. Everywhere else data is seeded explicitly with the
+	// seed command. A requested seed that fails stops the boot instead of
+	// serving a half-seeded database
+	if dataset := os.Getenv("SEED_ON_BOOT"); dataset != "" {
+		if env := os.Getenv("APP_ENV"); env != "development" {
+			log.Fatalf("SEED_ON_BOOT hanya boleh dipakai dengan APP_ENV=development (sekarang %q); pakai perintah seed di environment lain", env)
+		}
+		if err := storage.Seed(context.Background(), db, dataset, storage.DefaultSeedOptions()); err != nil {
+			log.Fatal("Gagal melakukan seed data: ", err)
+		}
+		log.Printf("Dataset %s selesai di-seed", dataset)
+	}
+
+	// Signing keys: RS256/EdDSA from PEM files, HS256 with JWT_SECRET as fallback
+	keyConfig, err := auth.KeyConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	jwtKeys, err := auth.LoadKeySet(keyConfig)
+	if err != nil {
+		log.Fatal("Gagal memuat kunci JWT: ", err)
+	}
+
+	// Outgoing mail is written to MAIL_SPOOL_DIR until a real mailer is plugged in
+	mail, err := mailer.NewSpoolMailer(getEnv("MAIL_SPOOL_DIR", "mail_spool"), getEnv("MAIL_FROM", "no-reply@medical-record.local"))
+	if err != nil {
+		log.Fatal("Gagal menyiapkan mailer: ", err)
+	}
+
+	deps := server.Deps{
+		DB:   db,
+		Keys: jwtKeys,
+		Mail: mail,
+		Links: service.AccountLinks{
+			VerifyEmailURL:   getEnv("API_BASE_URL", "http://localhost:8080") + "/api/verify-email",
+			ResetPasswordURL: getEnv("FRONTEND_URL", "http://localhost:5173") + "/reset-password",
+		},
+		// Second factor, enforced for the roles in MFA_REQUIRED_ROLES
+		MFA: service.MFAConfig{
+			Issuer:        getEnv("MFA_ISSUER", "Medical Record"),
+			RequiredRoles: parseRoles(getEnv("MFA_REQUIRED_ROLES", "admin,doctor")),
+		},
+		// Origins of the frontend, comma separated
+		CORSOrigins: parseList(getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:5173,http://127.0.0.1:5173")),
+		// Must be true behind Railway's proxy, see server.Deps
+		TrustProxy: os.Getenv("TRUST_PROXY") == "true",
 	}
 
-	// User Auth
-	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo, jwtSecret)
-	userHandler := handler.NewUserHandler(userService)
-
-	// Doctor Management
-	doctorRepo := repository.NewDoctorRepository(db)
-	doctorService := service.NewDoctorService(doctorRepo, userRepo, db)
-	doctorHandler := handler.NewDoctorHandler(doctorService)
-
-	// Doctor Profile Management
-	doctorProfileHandler := handler.NewDoctorProfileHandler(doctorService)
-
-	// Doctor Schedule Management
-	scheduleRepo := repository.NewDoctorScheduleRepository(db)
-	scheduleService := service.NewDoctorScheduleService(scheduleRepo, doctorRepo)
-	scheduleHandler := handler.NewDoctorScheduleHandler(scheduleService, doctorService)
-	doctorScheduleHandler := handler.NewDoctorScheduleHandler(scheduleService, doctorService)
-
-	// Appointment
-	appoinmentRepo := repository.NewAppointmentRepository(db)
-	patientRepo := repository.NewPatientRepository(db)
-	patientService := service.NewPatientService(db, appoinmentRepo, patientRepo)
-	patientHandler := handler.NewPatientHandler(patientService)
-	doctorAppointmentHandler := handler.NewDoctorAppointmentHandler(patientService, doctorService)
-
-	r := chi.NewRouter()
-	r.Use(middleware.Logger)
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:5173", "http://127.0.0.1:5173"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
-
-	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("Hello World!"))
-	})
-
-	r.Route("/api", func(r chi.Router) {
-		// User endpoints
-		r.Post("/register", userHandler.Register)
-		r.Post("/login", userHandler.Login)
-
-		r.Group(func(r chi.Router) {
-			r.Use(authMiddleware.AuthMiddleware)
-
-			r.Route("/doctor", func(r chi.Router) {
-				// Doctor Profile
-				r.Route("/profile", func(r chi.Router) {
-					r.Get("/", doctorProfileHandler.GetMyProfile)    // Get my profile
-					r.Put("/", doctorProfileHandler.UpdateMyProfile) // Update my profile
-				})
-
-				// Doctor Schedules (doctors only)
-				r.Route("/schedules", func(r chi.Router) {
-					r.Get("/", scheduleHandler.GetMySchedules)        // Get my schedules
-					r.Post("/", scheduleHandler.CreateSchedule)       // Create schedule
-					r.Put("/{id}", scheduleHandler.UpdateSchedule)    // Update schedule
-					r.Delete("/{id}", scheduleHandler.DeleteSchedule) // Delete schedule
-				})
-
-				r.Route("/appointments", func(r chi.Router) {
-					r.Get("/", doctorAppointmentHandler.GetAppointments)
-					r.Patch("/{id}", doctorAppointmentHandler.UpdateStatus)
-				})
-			})
-
-			r.Route("/patient", func(r chi.Router) {
-				r.Route("/doctors", func(r chi.Router) {
-					r.Get("/{id}/schedules", doctorScheduleHandler.GetDoctorSchedules) //Get Schedule
-					r.Get("/search", doctorHandler.SearchDoctors)                      // Search Doctors
-				})
-
-				r.Route("/appointments", func(r chi.Router) {
-					r.Get("/", patientHandler.GetAppointments)                // Get Appointment
-					r.Post("/", patientHandler.CreateAppointment)             // Create Appointment
-					r.Get("/{id}", patientHandler.GetAppointmentDetail)       //Get Appointment detail
-					r.Patch("/{id}/cancel", patientHandler.CancelAppointment) // Canceled Appointment
-				})
-			})
-
-		})
-	})
-
-	// GANTI BAGIAN INI
-    port := os.Getenv("PORT") // Railway akan kasih nomor pintu otomatis di sini
-    if port == "" {
-        port = "8080" // Ini buat kalau kamu jalanin di laptop sendiri
-    }
-
-    log.Printf("Server berjalan di port %s", port)
-    
-    // Gunakan variabel port tadi, jangan diketik manual :8080 lagi
-    if err := http.ListenAndServe(":"+port, r); err != nil {
-        log.Fatal("Gagal menjalankan server: ", err)
-    }
+	// Subcommands (e.g. create-admin) run instead of the server
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], db, server.NewServices(deps).Users); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Railway hands the port over in PORT
+	cfg := server.DefaultConfig()
+	cfg.Addr = ":" + getEnv("PORT", "8080")
+
+	// Runs until SIGINT or SIGTERM, then drains requests and closes db
+	if err := server.New(cfg, server.NewRouter(deps), db).Run(context.Background()); err != nil {
+		log.Fatal("Gagal menjalankan server: ", err)
+	}
+}
+
+// getEnv returns the environment variable key or fallback when it is unset.
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// parseRoles reads a comma separated list of roles, ignoring unknown ones.
+func parseRoles(value string) []domain.UserRole {
+	var roles []domain.UserRole
+	for _, role := range strings.Split(value, ",") {
+		role = strings.TrimSpace(role)
+		if domain.IsValidRole(role) {
+			roles = append(roles, domain.UserRole(role))
+		} else if role != "" {
+			log.Printf("Warning: role %q tidak dikenal, diabaikan", role)
+		}
+	}
+	return roles
+}
+
+// parseList splits a comma separated value, dropping empty entries.
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -2,6 +2,7 @@ package helper
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -14,6 +15,7 @@ func init() {
 
 	// Register custom validation
 	validate.RegisterValidation("email", validateEmail)
+	validate.RegisterValidation("phone", validatePhone)
 }
 
 // ValidateStruct validates a struct and returns error messages
@@ -42,6 +44,10 @@ func ValidateStruct(s interface{}) map[string]string {
 				errors[fieldName] = fmt.Sprintf("%s must be at most %s characters", fieldName, e.Param())
 			case "oneof":
 				errors[fieldName] = fmt.Sprintf("%s must be one of: %s", fieldName, e.Param())
+			case "phone":
+				errors[fieldName] = "Invalid phone number format"
+			case "datetime":
+				errors[fieldName] = fmt.Sprintf("%s must use the format YYYY-MM-DD", fieldName)
 			default:
 				errors[fieldName] = fmt.Sprintf("%s is invalid", fieldName)
 			}
@@ -57,6 +63,15 @@ func validateEmail(fl validator.FieldLevel) bool {
 	return strings.Contains(email, "@") && strings.Contains(email, ".")
 }
 
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+
+// validatePhone accepts 8 to 15 digits with an optional leading +. Spaces and
+// dashes used as separators are ignored.
+func validatePhone(fl validator.FieldLevel) bool {
+	phone := strings.NewReplacer(" ", "", "-", "").Replace(fl.Field().String())
+	return phonePattern.MatchString(phone)
+}
+
 // ValidateVar validates a single variable
 func ValidateVar(field interface{}, tag string) error {
 	return validate.Var(field, tag)
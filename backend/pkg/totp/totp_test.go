@@ -0,0 +1,94 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of RFC 6238 Appendix B, "12345678901234567890"
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// rfcVectors are the SHA-1 rows of RFC 6238 Appendix B. The RFC prints 8
+// digits; a 6 digit code is the same value mod 10^6, its last 6 digits.
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestCode(t *testing.T) {
+	for _, v := range rfcVectors {
+		got, err := Code(rfcSecret, Step(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", v.unix, err)
+		}
+		if got != v.code {
+			t.Errorf("Code at %d = %s, want %s", v.unix, got, v.code)
+		}
+	}
+
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code accepted a secret that is not base32")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, v := range rfcVectors {
+		at := time.Unix(v.unix, 0)
+		step, ok := Validate(rfcSecret, v.code, at)
+		if !ok || step != Step(at) {
+			t.Errorf("Validate(%s) at %d = %d, %v; want %d, true", v.code, v.unix, step, ok, Step(at))
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	at := time.Unix(1111111111, 0)
+	current := Step(at)
+
+	tests := []struct {
+		name   string
+		offset int64
+		ok     bool
+	}{
+		{"two steps behind", -2, false},
+		{"one step behind", -1, true},
+		{"current step", 0, true},
+		{"one step ahead", 1, true},
+		{"two steps ahead", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, current+tt.offset)
+			if err != nil {
+				t.Fatalf("Code: %v", err)
+			}
+			step, ok := Validate(rfcSecret, code, at)
+			if ok != tt.ok {
+				t.Fatalf("Validate = %v, want %v", ok, tt.ok)
+			}
+			if ok && step != current+tt.offset {
+				t.Errorf("matched step %d, want %d", step, current+tt.offset)
+			}
+		})
+	}
+}
+
+func TestValidateMalformed(t *testing.T) {
+	at := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "94287082", "abcdef"} {
+		if _, ok := Validate(rfcSecret, code, at); ok {
+			t.Errorf("Validate accepted %q", code)
+		}
+	}
+	if _, ok := Validate(rfcSecret, " 287082 ", at); !ok {
+		t.Error("Validate rejected a code with surrounding spaces")
+	}
+}